@@ -12,6 +12,15 @@ var (
 		Usage: `EVM export mode ("full" or "ext-mpt" or "mpt" or "none")`,
 		Value: "mpt",
 	}
+	ExportChunkFlag = cli.UintFlag{
+		Name:  "export.chunk",
+		Usage: "Number of epochs per chunk of events archive (0 = export into a single file)",
+	}
+	ExportCompressionFlag = cli.StringFlag{
+		Name:  "export.compression",
+		Usage: `Compression of events archive chunks ("gzip" or "zstd" or "none")`,
+		Value: "gzip",
+	}
 	importCommand = cli.Command{
 		Name:      "import",
 		Usage:     "Import a blockchain file",
@@ -31,14 +40,28 @@ Events are fully verified by default, unless overridden by check=false flag.`,
 				ArgsUsage: "<filename> (<filename 2> ... <filename N>)",
 				Flags: []cli.Flag{
 					DataDirFlag,
+					ImportCheckpointFlag,
+					TraceIndexFlag,
 					// record-replay: geth import --substatedir flag
 					substate.SubstateDirFlag,
 					RecordingFlag,
+					RecordingMetaDirFlag,
+					RecordingFromBlockFlag,
+					RecordingToBlockFlag,
+					RecordingEpochsFlag,
+					RecordingAddressesFlag,
 					ProfileEVMCallFlag,
+					ProfileEVMCallSinkFlag,
+					ProfileEVMCallFileFlag,
+					ProfileEVMCallReportFlag,
 					MicroProfilingFlag,
+					MicroProfilingSnapshotFlag,
+					MicroProfilingOutputFlag,
 				},
 				Description: `
-The import command imports events from RLP-encoded files.
+The import command imports events from RLP-encoded files,
+or from chunked events archives given by a manifest.json or its dir.
+Chunks of an archive are verified against the manifest hashes before import.
 Events are fully verified by default, unless overridden by --check=false flag.`,
 			},
 			{
@@ -65,10 +88,12 @@ The import command imports EVM storage (trie nodes, code, preimages) from files.
 			{
 				Name:      "events",
 				Usage:     "Export blockchain events",
-				ArgsUsage: "<filename> [<epochFrom> <epochTo>]",
+				ArgsUsage: "<filename or dir> [<epochFrom> <epochTo>]",
 				Action:    utils.MigrateFlags(exportEvents),
 				Flags: []cli.Flag{
 					DataDirFlag,
+					ExportChunkFlag,
+					ExportCompressionFlag,
 				},
 				Description: `
     opera export events
@@ -76,7 +101,11 @@ The import command imports EVM storage (trie nodes, code, preimages) from files.
 Requires a first argument of the file to write to.
 Optional second and third arguments control the first and
 last epoch to write. If the file ends with .gz, the output will
-be gzipped
+be gzipped, if it ends with .zst, the output will be compressed with zstd.
+
+With --export.chunk, the first argument is a dir to write chunked events archive to.
+The archive consists of chunk files of --export.chunk epochs each, and manifest.json
+which lists epochs ranges and SHA256 hashes of the chunks.
 `,
 			},
 			{
@@ -97,6 +126,40 @@ Optional second and third arguments control the first and
 last epoch to write.
 Pass dry-run instead of filename for calculation of hashes without exporting data.
 EVM export mode is configured with --export.evm.mode.
+`,
+			},
+		},
+	}
+	replayCommand = cli.Command{
+		Name:     "replay",
+		Usage:    "Replay recorded blockchain data",
+		Category: "MISCELLANEOUS COMMANDS",
+
+		Subcommands: []cli.Command{
+			{
+				Name:      "substate",
+				Usage:     "Replay recorded transaction substates",
+				ArgsUsage: "<firstBlock> <lastBlock>",
+				Action:    utils.MigrateFlags(replaySubstates),
+				Flags: []cli.Flag{
+					substate.SubstateDirFlag,
+					RecordingMetaDirFlag,
+					ReplayChainIDFlag,
+					ReplayReportFlag,
+					ReplayWorkersFlag,
+					ReplayShardFlag,
+					ReplayFailFastFlag,
+				},
+				Description: `
+    opera replay substate
+
+Re-executes the transaction substates recorded by 'opera import events --recording'
+within the given range of blocks, and compares the results with the recorded ones.
+Mismatches are logged and optionally written into a JSON report, configured with --replay.report.
+Reports include the Opera metadata of transactions (epoch, Atropos, tx hash), if it's recorded
+into --recording.metadir.
+The range is split into shards of --replay.shard blocks, which are replayed by
+--replay.workers parallel workers. Use --replay.failfast to stop at the first mismatch.
 `,
 			},
 		},
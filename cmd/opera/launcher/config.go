@@ -8,9 +8,13 @@ import (
 	"path"
 	"path/filepath"
 	"reflect"
+	"runtime"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/abft"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/utils/cachescale"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
@@ -18,6 +22,7 @@ import (
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/substate"
 	"github.com/naoina/toml"
 	"gopkg.in/urfave/cli.v1"
 
@@ -27,6 +32,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
 	"github.com/Fantom-foundation/go-opera/integration"
 	"github.com/Fantom-foundation/go-opera/integration/makefakegenesis"
+	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/opera/genesis"
 	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
 	futils "github.com/Fantom-foundation/go-opera/utils"
@@ -90,6 +96,16 @@ var (
 		Usage: "Sets a cap on transaction fee (in FTM) that can be sent via the RPC APIs (0 = no cap)",
 		Value: gossip.DefaultConfig(cachescale.Identity).RPCTxFeeCap,
 	}
+	RPCReceiptsRangeCapFlag = cli.Uint64Flag{
+		Name:  "rpc.receiptsrangecap",
+		Usage: "Sets a cap on number of blocks in ftm_getReceiptsRange (0 = no cap)",
+		Value: gossip.DefaultConfig(cachescale.Identity).RPCReceiptsRangeCap,
+	}
+	RPCPrivateTxEpochsFlag = cli.Uint64Flag{
+		Name:  "rpc.privatetxepochs",
+		Usage: "Number of epochs after which transactions sent via eth_sendPrivateTransaction fall back to public gossip",
+		Value: uint64(gossip.DefaultConfig(cachescale.Identity).PrivateTxEpochs),
+	}
 
 	SyncModeFlag = cli.StringFlag{
 		Name:  "syncmode",
@@ -105,19 +121,111 @@ var (
 		Usage: "Exits after synchronisation reaches the required epoch",
 	}
 
+	TxPoolSnapshotFlag = cli.StringFlag{
+		Name:  "txpool.snapshot",
+		Usage: "Disk snapshot of remote transactions to survive node restarts (disabled by default)",
+	}
+	TxPoolResnapshotFlag = cli.DurationFlag{
+		Name:  "txpool.resnapshot",
+		Usage: "Time interval to regenerate the remote transactions snapshot",
+		Value: evmcore.DefaultTxPoolConfig.Resnapshot,
+	}
+	TxPoolDroppedHistoryFlag = cli.IntFlag{
+		Name:  "txpool.droppedhistory",
+		Usage: "Number of the most recently dropped transactions to keep reasons of (0 = disabled)",
+		Value: evmcore.DefaultTxPoolConfig.DroppedHistory,
+	}
+
+	TraceIndexFlag = cli.BoolFlag{
+		Name:  "trace.index",
+		Usage: "Enable indexing of flat call traces of processed blocks for the trace_* API (disabled by default)",
+	}
+
 	// Record/replay
 	RecordingFlag = cli.BoolFlag{
 		Name:  "recording",
-		Usage: "Enable recording for record/replay mechanism.",
+		Usage: "Enable recording of transaction substates for record/replay mechanism.",
+	}
+	RecordingMetaDirFlag = cli.StringFlag{
+		Name:  "recording.metadir",
+		Usage: "Data directory for Opera-specific metadata of recorded substates (default = <substatedir>-meta).",
+	}
+	RecordingFromBlockFlag = cli.Uint64Flag{
+		Name:  "recording.from-block",
+		Usage: "First block to record substates of.",
+	}
+	RecordingToBlockFlag = cli.Uint64Flag{
+		Name:  "recording.to-block",
+		Usage: "Last block to record substates of (0 = no limit).",
+	}
+	RecordingEpochsFlag = cli.StringFlag{
+		Name:  "recording.epochs",
+		Usage: "Range of epochs to record substates of, as <from>-<to> (open-ended if <to> is omitted).",
+	}
+	RecordingAddressesFlag = cli.StringFlag{
+		Name:  "recording.addresses",
+		Usage: "Comma separated list of accounts, only transactions touching them are recorded.",
 	}
 	ProfileEVMCallFlag = cli.BoolFlag{
 		Name:  "profiling-call",
 		Usage: "Enable profiling for EVM calls.",
 	}
+	ProfileEVMCallSinkFlag = cli.StringFlag{
+		Name:  "profiling-call.sink",
+		Usage: "Sink of EVM calls profiling records: 'csv', 'sqlite' (requires opera built with the sqlite tag), 'metrics' (requires --metrics) or 'none'.",
+		Value: "csv",
+	}
+	ProfileEVMCallFileFlag = cli.StringFlag{
+		Name:  "profiling-call.file",
+		Usage: "File to write EVM calls profiling records to, for the 'csv' and 'sqlite' (default = evm-calls.db) sinks.",
+		Value: "evm-calls.csv",
+	}
+	ProfileEVMCallReportFlag = cli.StringFlag{
+		Name:  "profiling-call.report",
+		Usage: "File to write EVM calls statistic aggregated by contracts and function selectors to (empty = disabled).",
+		Value: "evm-calls-report.csv",
+	}
 	MicroProfilingFlag = cli.BoolFlag{
 		Name:  "micro-profiling",
 		Usage: "Enable micro-profiling of EVM.",
 	}
+	MicroProfilingSnapshotFlag = cli.DurationFlag{
+		Name:  "micro-profiling.snapshot",
+		Usage: "Interval of dumping intermediate micro-profiling snapshots (0 = only at the end).",
+		Value: time.Hour,
+	}
+	MicroProfilingOutputFlag = cli.StringFlag{
+		Name:  "micro-profiling.output",
+		Usage: "File or directory to write micro-profiling statistic to.",
+		Value: microProfilingDumpFile,
+	}
+	ImportCheckpointFlag = cli.StringFlag{
+		Name:  "import.checkpoint",
+		Usage: "File to persist the events import progress to, an interrupted import is resumed from it (default = <datadir>/import.checkpoint).",
+	}
+	ReplayChainIDFlag = cli.Uint64Flag{
+		Name:  "replay.chainid",
+		Usage: "Chain ID of the network the substates were recorded on (used if the chain config isn't recorded in the metadata).",
+		Value: opera.MainNetworkID,
+	}
+	ReplayReportFlag = cli.StringFlag{
+		Name:  "replay.report",
+		Usage: "File to write a JSON report of replay mismatches to.",
+	}
+	ReplayWorkersFlag = cli.IntFlag{
+		Name:  "replay.workers",
+		Usage: "Number of parallel replay workers.",
+		Value: runtime.NumCPU(),
+	}
+	ReplayShardFlag = cli.Uint64Flag{
+		Name:  "replay.shard",
+		Usage: "Number of consecutive blocks replayed by a worker at once.",
+		Value: 1000,
+	}
+	ReplayFailFastFlag = cli.BoolFlag{
+		Name:  "replay.failfast",
+		Usage: "Stop replaying at the first mismatch.",
+	}
 )
 
 type GenesisTemplate struct {
@@ -286,6 +394,12 @@ func setTxPool(ctx *cli.Context, cfg *evmcore.TxPoolConfig) {
 	if ctx.GlobalIsSet(utils.TxPoolRejournalFlag.Name) {
 		cfg.Rejournal = ctx.GlobalDuration(utils.TxPoolRejournalFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolSnapshotFlag.Name) {
+		cfg.Snapshot = ctx.GlobalString(TxPoolSnapshotFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolResnapshotFlag.Name) {
+		cfg.Resnapshot = ctx.GlobalDuration(TxPoolResnapshotFlag.Name)
+	}
 	if ctx.GlobalIsSet(utils.TxPoolPriceLimitFlag.Name) {
 		cfg.PriceLimit = ctx.GlobalUint64(utils.TxPoolPriceLimitFlag.Name)
 	}
@@ -307,6 +421,9 @@ func setTxPool(ctx *cli.Context, cfg *evmcore.TxPoolConfig) {
 	if ctx.GlobalIsSet(utils.TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(utils.TxPoolLifetimeFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolDroppedHistoryFlag.Name) {
+		cfg.DroppedHistory = ctx.GlobalInt(TxPoolDroppedHistoryFlag.Name)
+	}
 }
 
 func gossipConfigWithFlags(ctx *cli.Context, src gossip.Config) (gossip.Config, error) {
@@ -320,16 +437,81 @@ func gossipConfigWithFlags(ctx *cli.Context, src gossip.Config) (gossip.Config,
 	if ctx.GlobalIsSet(RPCGlobalTxFeeCapFlag.Name) {
 		cfg.RPCTxFeeCap = ctx.GlobalFloat64(RPCGlobalTxFeeCapFlag.Name)
 	}
+	if ctx.GlobalIsSet(RPCReceiptsRangeCapFlag.Name) {
+		cfg.RPCReceiptsRangeCap = ctx.GlobalUint64(RPCReceiptsRangeCapFlag.Name)
+	}
+	if ctx.GlobalIsSet(RPCPrivateTxEpochsFlag.Name) {
+		cfg.PrivateTxEpochs = idx.Epoch(ctx.GlobalUint64(RPCPrivateTxEpochsFlag.Name))
+	}
 	if ctx.GlobalIsSet(SyncModeFlag.Name) {
 		if syncmode := ctx.GlobalString(SyncModeFlag.Name); syncmode != "full" && syncmode != "snap" {
 			utils.Fatalf("--%s must be either 'full' or 'snap'", SyncModeFlag.Name)
 		}
 		cfg.AllowSnapsync = ctx.GlobalString(SyncModeFlag.Name) == "snap"
 	}
+	if ctx.GlobalIsSet(TraceIndexFlag.Name) {
+		cfg.TraceIndex = ctx.GlobalBool(TraceIndexFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordingFlag.Name) {
+		cfg.RecordSubstates = ctx.GlobalBool(RecordingFlag.Name)
+	}
+	if cfg.RecordSubstates && cfg.SubstateMetaDir == "" {
+		cfg.SubstateMetaDir = ctx.GlobalString(substate.SubstateDirFlag.Name) + "-meta"
+	}
+	if ctx.GlobalIsSet(RecordingMetaDirFlag.Name) {
+		cfg.SubstateMetaDir = ctx.GlobalString(RecordingMetaDirFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordingFromBlockFlag.Name) {
+		cfg.SubstateFilter.FromBlock = ctx.GlobalUint64(RecordingFromBlockFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordingToBlockFlag.Name) {
+		cfg.SubstateFilter.ToBlock = ctx.GlobalUint64(RecordingToBlockFlag.Name)
+	}
+	if ctx.GlobalIsSet(RecordingEpochsFlag.Name) {
+		from, to, err := parseEpochRange(ctx.GlobalString(RecordingEpochsFlag.Name))
+		if err != nil {
+			utils.Fatalf("Invalid --%s: %v", RecordingEpochsFlag.Name, err)
+		}
+		cfg.SubstateFilter.FromEpoch, cfg.SubstateFilter.ToEpoch = from, to
+	}
+	if ctx.GlobalIsSet(RecordingAddressesFlag.Name) {
+		cfg.SubstateFilter.Addresses = nil
+		for _, account := range strings.Split(ctx.GlobalString(RecordingAddressesFlag.Name), ",") {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				utils.Fatalf("Invalid account in --%s: %s", RecordingAddressesFlag.Name, trimmed)
+			} else {
+				cfg.SubstateFilter.Addresses = append(cfg.SubstateFilter.Addresses, common.HexToAddress(trimmed))
+			}
+		}
+	}
 
 	return cfg, nil
 }
 
+// parseEpochRange parses an epochs range in the <from>-<to> or <from>- format.
+func parseEpochRange(s string) (from, to idx.Epoch, err error) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 2 {
+		return 0, 0, errors.New("expected <from>-<to> format")
+	}
+	n, err := strconv.ParseUint(strings.TrimSpace(parts[0]), 10, 32)
+	if err != nil {
+		return 0, 0, err
+	}
+	from = idx.Epoch(n)
+	if last := strings.TrimSpace(parts[1]); last != "" {
+		n, err = strconv.ParseUint(last, 10, 32)
+		if err != nil {
+			return 0, 0, err
+		}
+		to = idx.Epoch(n)
+		if to < from {
+			return 0, 0, errors.New("last epoch is lower than first epoch")
+		}
+	}
+	return from, to, nil
+}
+
 func gossipStoreConfigWithFlags(ctx *cli.Context, src gossip.StoreConfig) (gossip.StoreConfig, error) {
 	cfg := src
 	if ctx.GlobalIsSet(utils.GCModeFlag.Name) {
@@ -411,7 +593,7 @@ func mayMakeAllConfigs(ctx *cli.Context) (*config, error) {
 	if err != nil {
 		return nil, err
 	}
-	if cfg.Emitter.Validator.ID != 0 && len(cfg.Emitter.PrevEmittedEventFile.Path) == 0 {
+	if cfg.Emitter.Validator.ID != 0 && !cfg.Emitter.Shadow && len(cfg.Emitter.PrevEmittedEventFile.Path) == 0 {
 		cfg.Emitter.PrevEmittedEventFile.Path = cfg.Node.ResolvePath(path.Join("emitter", fmt.Sprintf("last-%d", cfg.Emitter.Validator.ID)))
 	}
 	setTxPool(ctx, &cfg.TxPool)
@@ -419,6 +601,9 @@ func mayMakeAllConfigs(ctx *cli.Context) (*config, error) {
 	if err := cfg.Opera.Validate(); err != nil {
 		return nil, err
 	}
+	if err := cfg.TxPool.Policy.Validate(); err != nil {
+		return nil, err
+	}
 
 	return &cfg, nil
 }
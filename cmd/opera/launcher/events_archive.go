@@ -0,0 +1,188 @@
+package launcher
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/klauspost/compress/zstd"
+)
+
+// eventsManifestName is a file name of the manifest of chunked events archive.
+const eventsManifestName = "manifest.json"
+
+const eventsManifestVersion = 1
+
+// eventsChunk is a file of chunked events archive, which contains the events of an epochs range.
+// Each chunk is a regular events file, so it may be imported separately.
+type eventsChunk struct {
+	File      string    `json:"file"` // relative to the manifest dir
+	FromEpoch idx.Epoch `json:"fromEpoch"`
+	ToEpoch   idx.Epoch `json:"toEpoch"`
+	Size      int64     `json:"size"`
+	SHA256    string    `json:"sha256"`
+}
+
+// eventsManifest describes chunks of events archive, ordered by epochs.
+type eventsManifest struct {
+	Version int           `json:"version"`
+	Chunks  []eventsChunk `json:"chunks"`
+}
+
+func readEventsManifest(fn string) (*eventsManifest, error) {
+	data, err := ioutil.ReadFile(fn)
+	if err != nil {
+		return nil, err
+	}
+	m := &eventsManifest{}
+	if err := json.Unmarshal(data, m); err != nil {
+		return nil, err
+	}
+	if m.Version != eventsManifestVersion {
+		return nil, fmt.Errorf("unsupported events manifest version %d", m.Version)
+	}
+	return m, nil
+}
+
+func writeEventsManifest(fn string, m *eventsManifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(fn, data, 0644)
+}
+
+// hashEventsFile returns size and SHA256 hash of the file content.
+func hashEventsFile(fn string) (int64, string, error) {
+	fh, err := os.Open(fn)
+	if err != nil {
+		return 0, "", err
+	}
+	defer fh.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, fh)
+	if err != nil {
+		return 0, "", err
+	}
+	return size, hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// verifyEventsChunk checks that the chunk file is complete and not corrupted.
+func verifyEventsChunk(fn string, chunk *eventsChunk) error {
+	size, sum, err := hashEventsFile(fn)
+	if err != nil {
+		return err
+	}
+	if size != chunk.Size {
+		return fmt.Errorf("chunk %s size mismatch: got=%d, expected=%d", chunk.File, size, chunk.Size)
+	}
+	if sum != chunk.SHA256 {
+		return fmt.Errorf("chunk %s hash mismatch: got=%s, expected=%s", chunk.File, sum, chunk.SHA256)
+	}
+	return nil
+}
+
+// eventsSource is an events file to import, chunk is nil for the files which aren't a part of an archive.
+type eventsSource struct {
+	file  string
+	chunk *eventsChunk
+}
+
+// expandEventsArchives resolves import arguments into events files.
+// An argument is either an events file, or a manifest (or its dir) of chunked events archive.
+func expandEventsArchives(args []string) ([]eventsSource, error) {
+	sources := make([]eventsSource, 0, len(args))
+	for _, fn := range args {
+		manifest := fn
+		if info, err := os.Stat(fn); err == nil && info.IsDir() {
+			manifest = filepath.Join(fn, eventsManifestName)
+		} else if filepath.Base(fn) != eventsManifestName {
+			sources = append(sources, eventsSource{file: fn})
+			continue
+		}
+		m, err := readEventsManifest(manifest)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read events manifest %s: %v", manifest, err)
+		}
+		dir := filepath.Dir(manifest)
+		for i := range m.Chunks {
+			sources = append(sources, eventsSource{
+				file:  filepath.Join(dir, m.Chunks[i].File),
+				chunk: &m.Chunks[i],
+			})
+		}
+	}
+	return sources, nil
+}
+
+// isCompressedEventsFile reports whether the events file is compressed, judging by its name.
+func isCompressedEventsFile(fn string) bool {
+	return strings.HasSuffix(fn, ".gz") || strings.HasSuffix(fn, ".zst")
+}
+
+type zstdReadCloser struct {
+	*zstd.Decoder
+}
+
+func (r zstdReadCloser) Close() error {
+	r.Decoder.Close()
+	return nil
+}
+
+// newEventsReader potentially unwraps the gzip or zstd stream of the events file.
+func newEventsReader(fn string, r io.Reader) (io.ReadCloser, error) {
+	switch {
+	case strings.HasSuffix(fn, ".gz"):
+		return gzip.NewReader(r)
+	case strings.HasSuffix(fn, ".zst"):
+		d, err := zstd.NewReader(r)
+		if err != nil {
+			return nil, err
+		}
+		return zstdReadCloser{d}, nil
+	default:
+		return ioutil.NopCloser(r), nil
+	}
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
+
+// newEventsWriter potentially wraps the events file with a gzip or zstd stream.
+func newEventsWriter(fn string, w io.Writer) (io.WriteCloser, error) {
+	switch {
+	case strings.HasSuffix(fn, ".gz"):
+		return gzip.NewWriter(w), nil
+	case strings.HasSuffix(fn, ".zst"):
+		return zstd.NewWriter(w)
+	default:
+		return nopWriteCloser{w}, nil
+	}
+}
+
+// eventsChunkExt returns file extension of chunks for the compression name.
+func eventsChunkExt(compression string) (string, error) {
+	switch compression {
+	case "gzip":
+		return ".rlp.gz", nil
+	case "zstd":
+		return ".rlp.zst", nil
+	case "none":
+		return ".rlp", nil
+	default:
+		return "", fmt.Errorf("unknown events compression '%s'", compression)
+	}
+}
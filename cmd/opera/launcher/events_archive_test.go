@@ -0,0 +1,73 @@
+package launcher
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEventsArchive(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "events_archive_test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	data := bytes.Repeat([]byte("events"), 1000)
+	manifest := &eventsManifest{
+		Version: eventsManifestVersion,
+	}
+	for _, chunk := range []eventsChunk{
+		{File: "events-1-10.rlp.gz", FromEpoch: 1, ToEpoch: 10},
+		{File: "events-11-20.rlp.zst", FromEpoch: 11, ToEpoch: 20},
+		{File: "events-21-30.rlp", FromEpoch: 21, ToEpoch: 30},
+	} {
+		fn := filepath.Join(dir, chunk.File)
+		fh, err := os.Create(fn)
+		require.NoError(err)
+		w, err := newEventsWriter(fn, fh)
+		require.NoError(err)
+		_, err = w.Write(data)
+		require.NoError(err)
+		require.NoError(w.Close())
+		require.NoError(fh.Close())
+
+		chunk.Size, chunk.SHA256, err = hashEventsFile(fn)
+		require.NoError(err)
+		manifest.Chunks = append(manifest.Chunks, chunk)
+	}
+	require.NoError(writeEventsManifest(filepath.Join(dir, eventsManifestName), manifest))
+
+	for _, arg := range []string{dir, filepath.Join(dir, eventsManifestName)} {
+		sources, err := expandEventsArchives([]string{"single.rlp", arg})
+		require.NoError(err)
+		require.Len(sources, 4)
+		require.Equal(eventsSource{file: "single.rlp"}, sources[0])
+		for i, src := range sources[1:] {
+			require.Equal(filepath.Join(dir, manifest.Chunks[i].File), src.file)
+			require.Equal(manifest.Chunks[i], *src.chunk)
+			require.NoError(verifyEventsChunk(src.file, src.chunk))
+
+			fh, err := os.Open(src.file)
+			require.NoError(err)
+			r, err := newEventsReader(src.file, fh)
+			require.NoError(err)
+			got, err := ioutil.ReadAll(r)
+			require.NoError(err)
+			require.NoError(r.Close())
+			require.NoError(fh.Close())
+			require.Equal(data, got)
+		}
+	}
+
+	// corrupted chunk
+	fn := filepath.Join(dir, manifest.Chunks[2].File)
+	require.NoError(ioutil.WriteFile(fn, data[1:], 0644))
+	require.Error(verifyEventsChunk(fn, &manifest.Chunks[2]))
+	require.NoError(ioutil.WriteFile(fn, append([]byte{'E'}, data[1:]...), 0644))
+	require.Error(verifyEventsChunk(fn, &manifest.Chunks[2]))
+}
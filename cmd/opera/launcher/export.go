@@ -1,13 +1,13 @@
 package launcher
 
 import (
-	"compress/gzip"
 	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path"
+	"path/filepath"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
@@ -49,19 +49,6 @@ func exportEvents(ctx *cli.Context) error {
 
 	fn := ctx.Args().First()
 
-	// Open the file handle and potentially wrap with a gzip stream
-	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
-	if err != nil {
-		return err
-	}
-	defer fh.Close()
-
-	var writer io.Writer = fh
-	if strings.HasSuffix(fn, ".gz") {
-		writer = gzip.NewWriter(writer)
-		defer writer.(*gzip.Writer).Close()
-	}
-
 	from := idx.Epoch(1)
 	if len(ctx.Args()) > 1 {
 		n, err := strconv.ParseUint(ctx.Args().Get(1), 10, 32)
@@ -79,7 +66,49 @@ func exportEvents(ctx *cli.Context) error {
 		to = idx.Epoch(n)
 	}
 
+	if chunk := ctx.Uint(ExportChunkFlag.Name); chunk != 0 {
+		ext, err := eventsChunkExt(ctx.String(ExportCompressionFlag.Name))
+		if err != nil {
+			return err
+		}
+		if to == 0 {
+			to = gdb.GetEpoch()
+		}
+		return exportEventsArchive(fn, ext, gdb, from, to, idx.Epoch(chunk))
+	}
+
 	log.Info("Exporting events to file", "file", fn)
+	err = exportEventsFile(fn, gdb, from, to)
+	if err != nil {
+		utils.Fatalf("Export error: %v\n", err)
+	}
+
+	return nil
+}
+
+// exportEventsFile writes the events of epochs range into the events file.
+func exportEventsFile(fn string, gdb *gossip.Store, from, to idx.Epoch) error {
+	// Open the file handle and potentially wrap with a compression stream
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, os.ModePerm)
+	if err != nil {
+		return err
+	}
+
+	err = writeEventsFile(fn, fh, gdb, from, to)
+	if closeErr := fh.Close(); closeErr != nil && err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// writeEventsFile writes the header and the events of epochs range into the file handle,
+// which isn't closed.
+func writeEventsFile(fn string, fh io.Writer, gdb *gossip.Store, from, to idx.Epoch) error {
+	writer, err := newEventsWriter(fn, fh)
+	if err != nil {
+		return err
+	}
+
 	// Write header and version
 	_, err = writer.Write(append(eventsFileHeader, eventsFileVersion...))
 	if err != nil {
@@ -87,9 +116,50 @@ func exportEvents(ctx *cli.Context) error {
 	}
 	err = exportTo(writer, gdb, from, to)
 	if err != nil {
-		utils.Fatalf("Export error: %v\n", err)
+		return err
 	}
+	return writer.Close()
+}
 
+// exportEventsArchive writes the events of epochs range as chunked events archive into the dir.
+// Chunks are listed with their hashes in the manifest, which is written last.
+func exportEventsArchive(dir, ext string, gdb *gossip.Store, from, to, chunkSize idx.Epoch) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	manifest := &eventsManifest{
+		Version: eventsManifestVersion,
+	}
+	for chunkFrom := from; chunkFrom <= to; chunkFrom += chunkSize {
+		chunkTo := chunkFrom + chunkSize - 1
+		if chunkTo > to || chunkTo < chunkFrom {
+			chunkTo = to
+		}
+		chunk := eventsChunk{
+			File:      fmt.Sprintf("events-%d-%d%s", chunkFrom, chunkTo, ext),
+			FromEpoch: chunkFrom,
+			ToEpoch:   chunkTo,
+		}
+		fn := filepath.Join(dir, chunk.File)
+		log.Info("Exporting events to chunk", "file", fn, "from", chunkFrom, "to", chunkTo)
+		if err := exportEventsFile(fn, gdb, chunkFrom, chunkTo); err != nil {
+			return err
+		}
+		var err error
+		chunk.Size, chunk.SHA256, err = hashEventsFile(fn)
+		if err != nil {
+			return err
+		}
+		manifest.Chunks = append(manifest.Chunks, chunk)
+		if chunkTo == to {
+			break
+		}
+	}
+	fn := filepath.Join(dir, eventsManifestName)
+	if err := writeEventsManifest(fn, manifest); err != nil {
+		return err
+	}
+	log.Info("Exported events archive", "manifest", fn, "chunks", len(manifest.Chunks))
 	return nil
 }
 
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"math"
 	"os"
 	"os/signal"
@@ -13,16 +14,13 @@ import (
 	"strings"
 	"syscall"
 	"time"
-	"context"
 
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/cmd/utils"
 	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/core/vm"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rlp"
-	"github.com/ethereum/go-ethereum/substate"
 	"github.com/status-im/keycard-go/hexutils"
 	"gopkg.in/urfave/cli.v1"
 
@@ -32,8 +30,6 @@ import (
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
 	"github.com/Fantom-foundation/go-opera/utils/ioread"
-	"github.com/Fantom-foundation/go-opera/evmcore"
-
 )
 
 func importEvm(ctx *cli.Context) error {
@@ -84,32 +80,6 @@ func importEvmFile(fn string, gdb *gossip.Store) error {
 
 func importEvents(ctx *cli.Context) error {
 
-	if ctx.Bool(RecordingFlag.Name) {
-	        // OpenSubstateDB
-		substate.RecordReplay = true
-		substate.SetSubstateFlags(ctx)
-		substate.OpenSubstateDB()
-		defer substate.CloseSubstateDB()
-	}
-
-	if ctx.Bool(ProfileEVMCallFlag.Name) {
-		evmcore.ProfileEVMCall = true
-	}
-	if ctx.Bool(MicroProfilingFlag.Name) {
-		vm.MicroProfiling = true
-		ctx, cancel := context.WithCancel(context.Background())
-		ch := make(chan struct{})
-		stats := vm.NewMicroProfileStatistic()
-		go vm.MicroProfilingCollector(ctx, ch, stats)
-		defer func() {
-			(cancel)() // stop data collector
-			<-ch  	   // wait for data collector to finish
-			// TODO: get chainID from cli
-			version := fmt.Sprintf("git-date:%v, git-commit:%v, chaind-id:%v", gitDate, gitCommit, 250)
-			stats.Dump(version)
-		}()
-	}
-
 	if len(ctx.Args()) < 1 {
 		utils.Fatalf("This command requires an argument.")
 	}
@@ -121,6 +91,7 @@ func importEvents(ctx *cli.Context) error {
 	cfg.Opera.Protocol.EventsSemaphoreLimit.Num = math.MaxUint32
 	cfg.Emitter.Validator = emitter.ValidatorConfig{}
 	cfg.TxPool.Journal = ""
+	cfg.TxPool.Snapshot = ""
 	cfg.Node.IPCPath = ""
 	cfg.Node.HTTPHost = ""
 	cfg.Node.WSHost = ""
@@ -143,12 +114,57 @@ func importEvents(ctx *cli.Context) error {
 
 func importEventsToNode(ctx *cli.Context, cfg *config, genesisStore *genesisstore.Store, args ...string) error {
 	node, svc, nodeClose := makeNode(ctx, cfg, genesisStore)
+	// profiling is stopped after the node is closed, so no blocks are processed meanwhile
+	stopProfiling, err := startProfiling(ctx, svc.GetEvmStateReader().Config().ChainID.Uint64())
+	if err != nil {
+		nodeClose()
+		return err
+	}
+	defer stopProfiling()
 	defer nodeClose()
 	startNode(ctx, node)
 
-	for _, fn := range args {
+	checkpointPath := ctx.String(ImportCheckpointFlag.Name)
+	if checkpointPath == "" {
+		checkpointPath = path.Join(cfg.Node.DataDir, "import.checkpoint")
+	}
+	cp, err := readImportCheckpoint(checkpointPath)
+	if err != nil {
+		return fmt.Errorf("failed to read import checkpoint: %v", err)
+	}
+	// the checkpoint is valid only if the DB contains the checkpointed epochs
+	if cp != nil && cp.Epoch >= svc.CurrentEpoch() {
+		log.Warn("Import checkpoint is ahead of the DB, ignoring it", "epoch", cp.Epoch)
+		cp = nil
+	}
+	sources, err := expandEventsArchives(args)
+	if err != nil {
+		return err
+	}
+	if cp != nil {
+		for i, src := range sources {
+			if importFileID(src.file) == cp.File {
+				log.Info("Resuming events import", "file", src.file, "offset", cp.Offset, "epoch", cp.Epoch, "last", hash.Event(cp.LastEvent).String())
+				sources = sources[i:]
+				break
+			}
+		}
+	}
+
+	for _, src := range sources {
+		fn := src.file
+		if src.chunk != nil {
+			if err := verifyEventsChunk(fn, src.chunk); err != nil {
+				log.Error("Events chunk verification error", "file", fn, "err", err)
+				return err
+			}
+		}
 		log.Info("Importing events from file", "file", fn)
-		if err := importEventsFile(svc, fn); err != nil {
+		var offset uint64
+		if cp != nil && importFileID(fn) == cp.File {
+			offset = cp.Offset
+		}
+		if err := importEventsFile(svc, fn, offset, checkpointPath); err != nil {
 			log.Error("Import error", "file", fn, "err", err)
 			return err
 		}
@@ -173,7 +189,9 @@ func checkEventsFileHeader(reader io.Reader) error {
 	return nil
 }
 
-func importEventsFile(srv *gossip.Service, fn string) error {
+// importEventsFile imports events of the file starting from the offset (if not zero),
+// and checkpoints the progress on every sealed epoch.
+func importEventsFile(srv *gossip.Service, fn string, offset uint64, checkpointPath string) error {
 	// Watch for Ctrl-C while the import is running.
 	// If a signal is received, the import will stop.
 	interrupt := make(chan os.Signal, 1)
@@ -190,27 +208,41 @@ func importEventsFile(srv *gossip.Service, fn string) error {
 		}
 	}
 
-	// Open the file handle and potentially unwrap the gzip stream
+	// Open the file handle and potentially unwrap the compression stream
 	fh, err := os.Open(fn)
 	if err != nil {
 		return err
 	}
 	defer fh.Close()
 
-	var reader io.Reader = fh
-	if strings.HasSuffix(fn, ".gz") {
-		if reader, err = gzip.NewReader(reader); err != nil {
-			return err
-		}
-		defer reader.(*gzip.Reader).Close()
+	reader, err := newEventsReader(fn, fh)
+	if err != nil {
+		return err
 	}
+	defer reader.Close()
+	compressed := isCompressedEventsFile(fn)
 
 	// Check file version and header
 	if err := checkEventsFileHeader(reader); err != nil {
 		return err
 	}
-
-	stream := rlp.NewStream(reader, 0)
+	headerSize := uint64(len(eventsFileHeader) + len(eventsFileVersion))
+
+	// Skip the already imported events
+	if offset > headerSize {
+		if compressed {
+			_, err = io.CopyN(ioutil.Discard, reader, int64(offset-headerSize))
+		} else {
+			_, err = fh.Seek(int64(offset), io.SeekStart)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to skip imported events: %v", err)
+		}
+	} else {
+		offset = headerSize
+	}
+	counter := newCountingReader(reader, offset)
+	stream := rlp.NewStream(counter, 0)
 
 	start := time.Now()
 	last := hash.Event{}
@@ -239,6 +271,18 @@ func importEventsFile(srv *gossip.Service, fn string) error {
 		batchSize = 0
 		return nil
 	}
+	// checkpoint is written once all the events before the offset are applied and the epoch is sealed
+	checkpoint := func(offset uint64) error {
+		if srv.CurrentEpoch() <= epoch {
+			return nil
+		}
+		return writeImportCheckpoint(checkpointPath, &importCheckpoint{
+			File:      importFileID(fn),
+			Offset:    offset,
+			LastEvent: common.Hash(last),
+			Epoch:     epoch,
+		})
+	}
 
 	for {
 		select {
@@ -246,6 +290,7 @@ func importEventsFile(srv *gossip.Service, fn string) error {
 			return fmt.Errorf("interrupted")
 		default:
 		}
+		pos := counter.n
 		e := new(inter.EventPayload)
 		err = stream.Decode(e)
 		if err == io.EOF {
@@ -264,6 +309,11 @@ func importEventsFile(srv *gossip.Service, fn string) error {
 				return err
 			}
 		}
+		if e.Epoch() != epoch && epoch != 0 {
+			if err := checkpoint(pos); err != nil {
+				return fmt.Errorf("failed to write import checkpoint: %v", err)
+			}
+		}
 		epoch = e.Epoch()
 		batch = append(batch, e)
 		batchSize += 1024 + e.Size()
@@ -271,6 +321,9 @@ func importEventsFile(srv *gossip.Service, fn string) error {
 		events++
 	}
 	srv.WaitBlockEnd()
+	if err := checkpoint(counter.n); err != nil {
+		return fmt.Errorf("failed to write import checkpoint: %v", err)
+	}
 	log.Info("Events import is finished", "file", fn, "last", last.String(), "imported", events, "txs", txs, "elapsed", common.PrettyDuration(time.Since(start)))
 
 	return nil
@@ -0,0 +1,88 @@
+package launcher
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// importCheckpoint is a progress of events import, which allows to resume an interrupted import.
+// All the events of the file before Offset are applied, up to the sealed Epoch.
+type importCheckpoint struct {
+	File      string      `json:"file"`
+	Offset    uint64      `json:"offset"` // offset in the decompressed stream
+	LastEvent common.Hash `json:"lastEvent"`
+	Epoch     idx.Epoch   `json:"epoch"`
+}
+
+// readImportCheckpoint reads the checkpoint, returns nil if it doesn't exist.
+func readImportCheckpoint(fn string) (*importCheckpoint, error) {
+	data, err := ioutil.ReadFile(fn)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	cp := &importCheckpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+// writeImportCheckpoint atomically replaces the checkpoint.
+func writeImportCheckpoint(fn string, cp *importCheckpoint) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	tmp := fn + ".tmp"
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, fn)
+}
+
+// importFileID identifies an imported file regardless of the working dir.
+func importFileID(fn string) string {
+	abs, err := filepath.Abs(fn)
+	if err != nil {
+		return fn
+	}
+	return abs
+}
+
+// countingReader counts the bytes consumed from the stream.
+// It implements io.ByteReader, so the RLP stream doesn't buffer ahead and the count is exact.
+type countingReader struct {
+	r *bufio.Reader
+	n uint64
+}
+
+func newCountingReader(r io.Reader, offset uint64) *countingReader {
+	return &countingReader{
+		r: bufio.NewReader(r),
+		n: offset,
+	}
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += uint64(n)
+	return n, err
+}
+
+func (c *countingReader) ReadByte() (byte, error) {
+	b, err := c.r.ReadByte()
+	if err == nil {
+		c.n++
+	}
+	return b, err
+}
@@ -0,0 +1,66 @@
+package launcher
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportCheckpoint(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "import_checkpoint_test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	fn := filepath.Join(dir, "import.checkpoint")
+	cp, err := readImportCheckpoint(fn)
+	require.NoError(err)
+	require.Nil(cp)
+
+	expect := &importCheckpoint{
+		File:      importFileID("events.rlp"),
+		Offset:    12345,
+		LastEvent: common.HexToHash("0x01"),
+		Epoch:     100,
+	}
+	require.NoError(writeImportCheckpoint(fn, expect))
+	cp, err = readImportCheckpoint(fn)
+	require.NoError(err)
+	require.Equal(expect, cp)
+}
+
+func TestCountingReader(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	items := []string{"a", "bcd", string(make([]byte, 100))}
+	for _, item := range items {
+		require.NoError(rlp.Encode(&buf, item))
+	}
+	data := buf.Bytes()
+
+	counter := newCountingReader(bytes.NewReader(data), 0)
+	stream := rlp.NewStream(counter, 0)
+	var offsets []uint64
+	for range items {
+		var s string
+		require.NoError(stream.Decode(&s))
+		offsets = append(offsets, counter.n)
+	}
+	require.Equal([]uint64{1, 5, uint64(len(data))}, offsets)
+
+	// decoding from an offset gives the rest of items
+	counter = newCountingReader(bytes.NewReader(data[offsets[0]:]), offsets[0])
+	stream = rlp.NewStream(counter, 0)
+	var s string
+	require.NoError(stream.Decode(&s))
+	require.Equal("bcd", s)
+	require.Equal(offsets[1], counter.n)
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/node"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/substate"
 	"gopkg.in/urfave/cli.v1"
 
 	evmetrics "github.com/ethereum/go-ethereum/metrics"
@@ -28,6 +29,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/flags"
 	"github.com/Fantom-foundation/go-opera/gossip"
 	"github.com/Fantom-foundation/go-opera/gossip/emitter"
+	"github.com/Fantom-foundation/go-opera/graphql"
 	"github.com/Fantom-foundation/go-opera/integration"
 	"github.com/Fantom-foundation/go-opera/opera/genesis"
 	"github.com/Fantom-foundation/go-opera/opera/genesisstore"
@@ -95,6 +97,8 @@ func initFlags() {
 		utils.TxPoolNoLocalsFlag,
 		utils.TxPoolJournalFlag,
 		utils.TxPoolRejournalFlag,
+		TxPoolSnapshotFlag,
+		TxPoolResnapshotFlag,
 		utils.TxPoolPriceLimitFlag,
 		utils.TxPoolPriceBumpFlag,
 		utils.TxPoolAccountSlotsFlag,
@@ -102,6 +106,7 @@ func initFlags() {
 		utils.TxPoolAccountQueueFlag,
 		utils.TxPoolGlobalQueueFlag,
 		utils.TxPoolLifetimeFlag,
+		TxPoolDroppedHistoryFlag,
 	}
 	operaFlags = []cli.Flag{
 		GenesisFlag,
@@ -119,7 +124,16 @@ func initFlags() {
 		validatorIDFlag,
 		validatorPubkeyFlag,
 		validatorPasswordFlag,
+		validatorShadowFlag,
 		SyncModeFlag,
+		TraceIndexFlag,
+		RecordingFlag,
+		RecordingMetaDirFlag,
+		RecordingFromBlockFlag,
+		RecordingToBlockFlag,
+		RecordingEpochsFlag,
+		RecordingAddressesFlag,
+		substate.SubstateDirFlag,
 	}
 	legacyRpcFlags = []cli.Flag{
 		utils.NoUSBFlag,
@@ -152,6 +166,8 @@ func initFlags() {
 		utils.IPCPathFlag,
 		RPCGlobalGasCapFlag,
 		RPCGlobalTxFeeCapFlag,
+		RPCReceiptsRangeCapFlag,
+		RPCPrivateTxEpochsFlag,
 	}
 
 	metricsFlags = []cli.Flag{
@@ -213,6 +229,7 @@ func init() {
 		// See chaincmd.go
 		importCommand,
 		exportCommand,
+		replayCommand,
 		checkCommand,
 		// See snapshot.go
 		snapshotCommand,
@@ -314,6 +331,11 @@ func makeNode(ctx *cli.Context, cfg *config, genesisStore *genesisstore.Store) (
 
 	stack := makeConfigNode(ctx, &cfg.Node)
 
+	if cfg.Opera.RecordSubstates {
+		// substate DB is opened and closed by the service
+		substate.SetSubstateFlags(ctx)
+	}
+
 	valKeystore := valkeystore.NewDefaultFileKeystore(path.Join(getValKeystoreDir(cfg.Node), "validator"))
 	valPubkey := cfg.Emitter.Validator.PubKey
 	if key := getFakeValidatorKey(ctx); key != nil && cfg.Emitter.Validator.ID != 0 {
@@ -323,7 +345,7 @@ func makeNode(ctx *cli.Context, cfg *config, genesisStore *genesisstore.Store) (
 	}
 
 	// unlock validator key
-	if !valPubkey.Empty() {
+	if !valPubkey.Empty() && !cfg.Emitter.Shadow {
 		err := unlockValidatorKey(ctx, valPubkey, valKeystore)
 		if err != nil {
 			utils.Fatalf("Failed to unlock validator key: %v", err)
@@ -336,6 +358,9 @@ func makeNode(ctx *cli.Context, cfg *config, genesisStore *genesisstore.Store) (
 		if cfg.TxPool.Journal != "" {
 			cfg.TxPool.Journal = stack.ResolvePath(cfg.TxPool.Journal)
 		}
+		if cfg.TxPool.Snapshot != "" {
+			cfg.TxPool.Snapshot = stack.ResolvePath(cfg.TxPool.Snapshot)
+		}
 		return evmcore.NewTxPool(cfg.TxPool, reader.Config(), reader)
 	}
 	haltCheck := func(oldEpoch, newEpoch idx.Epoch, age time.Time) bool {
@@ -363,6 +388,12 @@ func makeNode(ctx *cli.Context, cfg *config, genesisStore *genesisstore.Store) (
 	}
 
 	stack.RegisterAPIs(svc.APIs())
+	if ctx.GlobalBool(utils.GraphQLEnabledFlag.Name) {
+		err = graphql.New(stack, svc.EthAPI, cfg.Opera.FilterAPI, cfg.Node.GraphQLCors, cfg.Node.GraphQLVirtualHosts)
+		if err != nil {
+			utils.Fatalf("Failed to register the GraphQL service: %v", err)
+		}
+	}
 	stack.RegisterProtocols(svc.Protocols())
 	stack.RegisterLifecycle(svc)
 
@@ -0,0 +1,267 @@
+package launcher
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/metrics"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+// profilingVersion labels profiling results with the node version and the profiled network.
+func profilingVersion(chainID uint64) string {
+	return fmt.Sprintf("git-date:%v, git-commit:%v, chain-id:%v", gitDate, gitCommit, chainID)
+}
+
+// startProfiling enables the profiling modes requested by flags, and returns a function which stops them
+// and dumps the results.
+func startProfiling(ctx *cli.Context, chainID uint64) (stop func(), err error) {
+	version := profilingVersion(chainID)
+	stopCall := func() {}
+	if ctx.Bool(ProfileEVMCallFlag.Name) {
+		stopCall, err = startCallProfiling(ctx, version)
+		if err != nil {
+			return nil, err
+		}
+	}
+	stopMicro := func() {}
+	if ctx.Bool(MicroProfilingFlag.Name) {
+		stopMicro = startMicroProfiling(ctx.Duration(MicroProfilingSnapshotFlag.Name), ctx.String(MicroProfilingOutputFlag.Name), version)
+	}
+	return func() {
+		stopMicro()
+		stopCall()
+	}, nil
+}
+
+func makeCallProfiler(ctx *cli.Context) (evmcore.CallProfiler, error) {
+	switch sink := ctx.String(ProfileEVMCallSinkFlag.Name); sink {
+	case "csv":
+		fh, err := os.OpenFile(ctx.String(ProfileEVMCallFileFlag.Name), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return nil, err
+		}
+		return evmcore.NewCSVCallProfiler(fh, fh), nil
+	case "sqlite":
+		fn := ctx.String(ProfileEVMCallFileFlag.Name)
+		if !ctx.IsSet(ProfileEVMCallFileFlag.Name) {
+			fn = "evm-calls.db"
+		}
+		return openSQLiteCallProfiler(fn)
+	case "metrics":
+		// metrics are collected only if they're enabled
+		if !metrics.Enabled {
+			return nil, errors.New("'metrics' EVM call profiling sink requires --metrics flag")
+		}
+		return evmcore.NewMetricsCallProfiler(), nil
+	case "none":
+		return evmcore.NewMultiCallProfiler(), nil
+	default:
+		return nil, fmt.Errorf("unknown EVM call profiling sink '%s'", sink)
+	}
+}
+
+func dumpCallStatistic(stats *evmcore.CallStatistic, fn string, version string) error {
+	fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+	return stats.Dump(fh, version)
+}
+
+func startCallProfiling(ctx *cli.Context, version string) (stop func(), err error) {
+	profiler, err := makeCallProfiler(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var stats *evmcore.CallStatistic
+	report := ctx.String(ProfileEVMCallReportFlag.Name)
+	if report != "" {
+		stats = evmcore.NewCallStatistic()
+		profiler = evmcore.NewMultiCallProfiler(profiler, stats)
+	}
+	evmcore.EVMCallProfiler = profiler
+
+	return func() {
+		evmcore.EVMCallProfiler = nil
+		if err := profiler.Close(); err != nil {
+			log.Error("Failed to close EVM call profiler", "err", err)
+		}
+		if stats != nil {
+			if err := dumpCallStatistic(stats, report, version); err != nil {
+				log.Error("Failed to dump EVM call statistic", "file", report, "err", err)
+			}
+		}
+	}, nil
+}
+
+// microProfilingDumpFile is the file in the working dir, which micro-profiling statistic is dumped into.
+const microProfilingDumpFile = "profiling.db"
+
+// microProfileDumper is a dumpable micro-profiling statistic.
+type microProfileDumper interface {
+	Dump(version string)
+}
+
+// microProfilingOutputFile returns the absolute path of the output file, which is the default file name
+// inside the output if it's a directory.
+func microProfilingOutputFile(output string) (string, error) {
+	if output == "" {
+		output = microProfilingDumpFile
+	}
+	if strings.HasSuffix(output, string(filepath.Separator)) {
+		if err := os.MkdirAll(output, 0700); err != nil {
+			return "", err
+		}
+	}
+	if info, err := os.Stat(output); err == nil && info.IsDir() {
+		output = filepath.Join(output, microProfilingDumpFile)
+	}
+	return filepath.Abs(output)
+}
+
+// dumpMicroProfiling dumps the statistic into the output file.
+// The statistic is always dumped into the working dir first, so an existing file there is kept aside meanwhile.
+func dumpMicroProfiling(stats microProfileDumper, output string, version string) (err error) {
+	output, err = microProfilingOutputFile(output)
+	if err != nil {
+		return err
+	}
+	dumpFile, err := filepath.Abs(microProfilingDumpFile)
+	if err != nil {
+		return err
+	}
+	if output == dumpFile {
+		stats.Dump(version)
+		return nil
+	}
+
+	if _, err := os.Stat(dumpFile); err == nil {
+		backup, err := tempFile(filepath.Dir(dumpFile), microProfilingDumpFile)
+		if err != nil {
+			return err
+		}
+		if err := os.Rename(dumpFile, backup); err != nil {
+			_ = os.Remove(backup)
+			return err
+		}
+		defer func() {
+			if rerr := os.Rename(backup, dumpFile); rerr != nil && err == nil {
+				err = rerr
+			}
+		}()
+	}
+	stats.Dump(version)
+	defer os.Remove(dumpFile)
+	return copyFile(dumpFile, output)
+}
+
+// tempFile creates an empty temporary file in the dir and returns its path.
+func tempFile(dir, name string) (string, error) {
+	fh, err := ioutil.TempFile(dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	return fh.Name(), fh.Close()
+}
+
+// copyFile replaces dst with a copy of src. The copy is written into a temporary file in the dst dir first,
+// so dst is replaced atomically, even if src is on another filesystem.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	tmp, err := ioutil.TempFile(filepath.Dir(dst), filepath.Base(dst)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	_, err = io.Copy(tmp, in)
+	if err == nil {
+		err = tmp.Sync()
+	}
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), dst)
+	}
+	if err != nil {
+		_ = os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// startMicroProfiling starts collecting EVM micro-profiling data, which is dumped into the output file on stop
+// and every snapshot interval (if not zero).
+func startMicroProfiling(snapshot time.Duration, output string, version string) (stop func()) {
+	vm.MicroProfiling = true
+	stats := vm.NewMicroProfileStatistic()
+	collect := func() (stop func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		ch := make(chan struct{})
+		go vm.MicroProfilingCollector(ctx, ch, stats)
+		return func() {
+			cancel() // stop data collector
+			<-ch     // wait for data collector to finish
+		}
+	}
+	dump := func() {
+		if err := dumpMicroProfiling(stats, output, version); err != nil {
+			log.Error("Failed to dump micro-profiling statistic", "file", output, "err", err)
+		}
+	}
+	return runMicroProfiling(snapshot, collect, dump)
+}
+
+// runMicroProfiling runs the collector, and dumps the collected data on stop and every snapshot interval (if not zero).
+// The collector is paused while the data is dumped.
+func runMicroProfiling(snapshot time.Duration, collect func() (stop func()), dump func()) (stop func()) {
+	stopCollector := collect()
+
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		if snapshot == 0 {
+			<-quit
+			return
+		}
+		ticker := time.NewTicker(snapshot)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				// collector is paused to dump a consistent snapshot
+				stopCollector()
+				start := time.Now()
+				dump()
+				stopCollector = collect()
+				log.Info("Dumped micro-profiling snapshot", "elapsed", time.Since(start))
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		close(quit)
+		<-done
+		stopCollector()
+		dump()
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !sqlite
+// +build !sqlite
+
+package launcher
+
+import (
+	"errors"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+// openSQLiteCallProfiler fails, as opera is built without the sqlite tag (the SQLite driver requires cgo).
+func openSQLiteCallProfiler(fn string) (evmcore.CallProfiler, error) {
+	return nil, errors.New("'sqlite' EVM call profiling sink requires opera built with the sqlite tag")
+}
@@ -0,0 +1,27 @@
+//go:build sqlite
+// +build sqlite
+
+package launcher
+
+import (
+	"database/sql"
+
+	_ "github.com/mattn/go-sqlite3"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+// openSQLiteCallProfiler opens the SQLite DB file for the 'sqlite' EVM call profiling sink.
+// The SQLite driver requires cgo, so the sink is available only in builds with the sqlite tag.
+func openSQLiteCallProfiler(fn string) (evmcore.CallProfiler, error) {
+	db, err := sql.Open("sqlite3", fn)
+	if err != nil {
+		return nil, err
+	}
+	profiler, err := evmcore.NewSQLCallProfiler(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return profiler, nil
+}
@@ -0,0 +1,138 @@
+package launcher
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProfilingVersion(t *testing.T) {
+	require := require.New(t)
+
+	require.Contains(profilingVersion(250), "chain-id:250")
+	require.Contains(profilingVersion(4002), "chain-id:4002")
+}
+
+type fakeMicroProfileDumper struct {
+	versions []string
+}
+
+func (d *fakeMicroProfileDumper) Dump(version string) {
+	d.versions = append(d.versions, version)
+	_ = ioutil.WriteFile(microProfilingDumpFile, []byte(version), 0600)
+}
+
+func TestDumpMicroProfiling(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "micro-profiling")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	wd, err := os.Getwd()
+	require.NoError(err)
+	require.NoError(os.Chdir(dir))
+	defer os.Chdir(wd)
+
+	stats := &fakeMicroProfileDumper{}
+
+	// the default output is kept in place
+	require.NoError(dumpMicroProfiling(stats, microProfilingDumpFile, "v1"))
+	require.FileExists(microProfilingDumpFile)
+
+	// the dump is written to the output, overwriting the previous snapshot
+	output := path.Join(dir, "out", "micro.db")
+	require.NoError(os.Mkdir(path.Dir(output), 0700))
+	require.NoError(dumpMicroProfiling(stats, output, "v2"))
+	require.NoError(dumpMicroProfiling(stats, output, "v3"))
+	data, err := ioutil.ReadFile(output)
+	require.NoError(err)
+	require.Equal("v3", string(data))
+	require.Equal([]string{"v1", "v2", "v3"}, stats.versions)
+
+	// the file of the working dir isn't overwritten
+	data, err = ioutil.ReadFile(microProfilingDumpFile)
+	require.NoError(err)
+	require.Equal("v1", string(data))
+
+	// the dump is written into the output dir
+	require.NoError(dumpMicroProfiling(stats, path.Dir(output), "v4"))
+	data, err = ioutil.ReadFile(path.Join(path.Dir(output), microProfilingDumpFile))
+	require.NoError(err)
+	require.Equal("v4", string(data))
+	newDir := path.Join(dir, "new") + string(os.PathSeparator)
+	require.NoError(dumpMicroProfiling(stats, newDir, "v5"))
+	data, err = ioutil.ReadFile(path.Join(newDir, microProfilingDumpFile))
+	require.NoError(err)
+	require.Equal("v5", string(data))
+
+	// no temporary files are left
+	for _, d := range []string{dir, path.Dir(output), newDir} {
+		files, err := ioutil.ReadDir(d)
+		require.NoError(err)
+		for _, f := range files {
+			require.NotContains(f.Name(), ".tmp")
+		}
+	}
+	data, err = ioutil.ReadFile(microProfilingDumpFile)
+	require.NoError(err)
+	require.Equal("v1", string(data))
+}
+
+func TestRunMicroProfiling(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		mu        sync.Mutex
+		collects  int
+		running   bool
+		dumps     int
+		dumpedRun bool // a dump happened while the collector was running
+	)
+	collect := func() func() {
+		mu.Lock()
+		defer mu.Unlock()
+		collects++
+		running = true
+		return func() {
+			mu.Lock()
+			defer mu.Unlock()
+			running = false
+		}
+	}
+	dump := func() {
+		mu.Lock()
+		defer mu.Unlock()
+		dumps++
+		dumpedRun = dumpedRun || running
+	}
+
+	// no snapshots
+	stop := runMicroProfiling(0, collect, dump)
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	require.Equal(1, collects)
+	require.Equal(1, dumps)
+	require.False(running)
+
+	// snapshots are dumped periodically, with the collector paused
+	collects, dumps = 0, 0
+	stop = runMicroProfiling(time.Millisecond, collect, dump)
+	require.Eventually(func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return dumps >= 3
+	}, 5*time.Second, time.Millisecond)
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	require.False(dumpedRun)
+	require.False(running)
+	// every collecting period is dumped, including the last one
+	require.Equal(collects, dumps)
+}
@@ -0,0 +1,348 @@
+package launcher
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/cmd/utils"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/substate"
+	"gopkg.in/urfave/cli.v1"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// substateReplayer re-executes recorded substates with the Opera chain config and rules.
+// The chain config recorded in the metadata is used if it's available, otherwise
+// it's derived from the mainnet rules.
+type substateReplayer struct {
+	preLondon *params.ChainConfig
+	london    *params.ChainConfig
+
+	configsMu sync.Mutex
+	configs   map[idx.Epoch]*params.ChainConfig // recorded chain configs, nil if not recorded
+}
+
+func newSubstateReplayer(chainID uint64) *substateReplayer {
+	rules := opera.MainNetRules()
+	rules.NetworkID = chainID
+	preLondon := rules.EvmChainConfig()
+	// Berlin and London are activated together on Opera networks
+	rules.Upgrades.Berlin = true
+	rules.Upgrades.London = true
+	london := rules.EvmChainConfig()
+	return &substateReplayer{
+		preLondon: preLondon,
+		london:    london,
+		configs:   make(map[idx.Epoch]*params.ChainConfig),
+	}
+}
+
+// recordedChainConfig returns the chain config recorded for the epoch, or nil.
+func (r *substateReplayer) recordedChainConfig(epoch idx.Epoch) *params.ChainConfig {
+	r.configsMu.Lock()
+	defer r.configsMu.Unlock()
+	config, ok := r.configs[epoch]
+	if !ok {
+		config = evmcore.GetSubstateChainConfig(epoch)
+		r.configs[epoch] = config
+	}
+	return config
+}
+
+func (r *substateReplayer) chainConfig(block uint64, tx int, recording *substate.Substate) *params.ChainConfig {
+	if meta := evmcore.GetSubstateMeta(block, tx); meta != nil {
+		if config := r.recordedChainConfig(meta.Epoch); config != nil {
+			return config
+		}
+	}
+	// the recorded base fee tells which side of the upgrade the block is on
+	if recording.Env.BaseFee != nil {
+		return r.london
+	}
+	return r.preLondon
+}
+
+func (r *substateReplayer) replay(block uint64, tx int, recording *substate.Substate) (*evmcore.SubstateReplayReport, error) {
+	return evmcore.ReplaySubstate(r.chainConfig(block, tx, recording), opera.DefaultVMConfig, block, tx, recording)
+}
+
+func parseBlockRange(args cli.Args) (first, last uint64, err error) {
+	first, err = strconv.ParseUint(args.Get(0), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	last, err = strconv.ParseUint(args.Get(1), 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+	if first > last {
+		return 0, 0, errors.New("first block is greater than last block")
+	}
+	return first, last, nil
+}
+
+// substateShard is a range of blocks which is replayed by a single worker.
+type substateShard struct {
+	first uint64
+	last  uint64
+}
+
+// splitBlockRange splits [first, last] into shards of at most size blocks.
+func splitBlockRange(first, last, size uint64) []substateShard {
+	if size == 0 {
+		size = 1
+	}
+	shards := make([]substateShard, 0, (last-first)/size+1)
+	for from := first; ; from += size {
+		to := from + size - 1
+		if to > last || to < from {
+			to = last
+		}
+		shards = append(shards, substateShard{from, to})
+		if to == last {
+			break
+		}
+	}
+	return shards
+}
+
+// substateReplayProgress is shared by replay workers, counters are accessed atomically.
+type substateReplayProgress struct {
+	blocks uint64
+	txs    uint64
+	failed uint64
+}
+
+// replayShard replays all the recorded substates of the shard and sends mismatched reports into the channel.
+func (r *substateReplayer) replayShard(shard substateShard, progress *substateReplayProgress, reports chan<- *evmcore.SubstateReplayReport, quit <-chan struct{}) error {
+	for block := shard.first; ; block++ {
+		select {
+		case <-quit:
+			return nil
+		default:
+		}
+		recordings := substate.GetBlockSubstates(block)
+		indexes := make([]int, 0, len(recordings))
+		for tx := range recordings {
+			indexes = append(indexes, tx)
+		}
+		sort.Ints(indexes)
+
+		for _, tx := range indexes {
+			report, err := r.replay(block, tx, recordings[tx])
+			if err != nil {
+				return fmt.Errorf("failed to replay tx %d of block %d: %v", tx, block, err)
+			}
+			atomic.AddUint64(&progress.txs, 1)
+			if report.OK() {
+				continue
+			}
+			atomic.AddUint64(&progress.failed, 1)
+			select {
+			case reports <- report:
+			case <-quit:
+				return nil
+			}
+		}
+		atomic.AddUint64(&progress.blocks, 1)
+		if block == shard.last {
+			return nil
+		}
+	}
+}
+
+// substateReplayPool replays shards in parallel workers and collects the mismatched reports.
+type substateReplayPool struct {
+	workers  int
+	failFast bool // stop on the first mismatched report
+
+	replayShard func(shard substateShard, reports chan<- *evmcore.SubstateReplayReport, quit <-chan struct{}) error
+	onReport    func(report *evmcore.SubstateReplayReport) error
+}
+
+// run replays the shards until all of them are done, or the replay is stopped by an error,
+// a mismatch in the fail-fast mode or an interrupt. onTick is called on every tick.
+func (p *substateReplayPool) run(shards []substateShard, interrupt <-chan os.Signal, tick <-chan time.Time, onTick func()) error {
+	var (
+		shardsQ  = make(chan substateShard)
+		reports  = make(chan *evmcore.SubstateReplayReport, p.workers)
+		errs     = make(chan error, p.workers)
+		quit     = make(chan struct{})
+		stopOnce sync.Once
+		wg       sync.WaitGroup
+	)
+	stop := func() {
+		stopOnce.Do(func() {
+			close(quit)
+		})
+	}
+	go func() {
+		defer close(shardsQ)
+		for _, shard := range shards {
+			select {
+			case shardsQ <- shard:
+			case <-quit:
+				return
+			}
+		}
+	}()
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for shard := range shardsQ {
+				if err := p.replayShard(shard, reports, quit); err != nil {
+					errs <- err
+					stop()
+					return
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(reports)
+	}()
+
+	var replayErr error
+loop:
+	for {
+		select {
+		case report, ok := <-reports:
+			if !ok {
+				break loop
+			}
+			if err := p.onReport(report); err != nil && replayErr == nil {
+				replayErr = err
+				stop()
+			}
+			if p.failFast {
+				stop()
+			}
+		case <-interrupt:
+			if replayErr == nil {
+				replayErr = errors.New("interrupted")
+			}
+			stop()
+		case <-tick:
+			onTick()
+		}
+	}
+	close(errs)
+	for err := range errs {
+		if replayErr == nil {
+			replayErr = err
+		}
+	}
+	return replayErr
+}
+
+func replaySubstates(ctx *cli.Context) error {
+	if len(ctx.Args()) != 2 {
+		utils.Fatalf("This command requires 2 arguments: <firstBlock> <lastBlock>.")
+	}
+	first, last, err := parseBlockRange(ctx.Args())
+	if err != nil {
+		return err
+	}
+	workers := ctx.Int(ReplayWorkersFlag.Name)
+	if workers < 1 {
+		workers = 1
+	}
+	failFast := ctx.Bool(ReplayFailFastFlag.Name)
+
+	// Watch for Ctrl-C while the replay is running.
+	// If a signal is received, the replay will stop.
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(interrupt)
+
+	substate.SetSubstateFlags(ctx)
+	substate.OpenSubstateDBReadOnly()
+	defer substate.CloseSubstateDB()
+
+	metaDir := ctx.String(RecordingMetaDirFlag.Name)
+	if metaDir == "" {
+		metaDir = ctx.String(substate.SubstateDirFlag.Name) + "-meta"
+	}
+	// metadata is optional, as its recording may be disabled
+	if _, err := os.Stat(metaDir); err == nil {
+		if err := evmcore.OpenSubstateMetaDB(metaDir); err != nil {
+			return err
+		}
+		defer evmcore.CloseSubstateMetaDB()
+	} else if ctx.IsSet(RecordingMetaDirFlag.Name) {
+		return err
+	}
+
+	var reportEnc *json.Encoder
+	if fn := ctx.String(ReplayReportFlag.Name); fn != "" {
+		fh, err := os.OpenFile(fn, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644)
+		if err != nil {
+			return err
+		}
+		defer fh.Close()
+		reportEnc = json.NewEncoder(fh)
+	}
+
+	replayer := newSubstateReplayer(ctx.Uint64(ReplayChainIDFlag.Name))
+	shards := splitBlockRange(first, last, ctx.Uint64(ReplayShardFlag.Name))
+	log.Info("Replaying substates", "first", first, "last", last, "shards", len(shards), "workers", workers)
+
+	progress := &substateReplayProgress{}
+	pool := &substateReplayPool{
+		workers:  workers,
+		failFast: failFast,
+		replayShard: func(shard substateShard, reports chan<- *evmcore.SubstateReplayReport, quit <-chan struct{}) error {
+			return replayer.replayShard(shard, progress, reports, quit)
+		},
+		onReport: func(report *evmcore.SubstateReplayReport) error {
+			for _, m := range report.Mismatches {
+				log.Warn("Substate mismatch", "block", report.Block, "tx", report.Tx, "field", m.Field, "address", m.Address, "key", m.Key, "expected", m.Expected, "got", m.Got)
+			}
+			if reportEnc != nil {
+				return reportEnc.Encode(report)
+			}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	logProgress := func(msg string) {
+		elapsed := time.Since(start)
+		txs := atomic.LoadUint64(&progress.txs)
+		log.Info(msg, "blocks", atomic.LoadUint64(&progress.blocks), "total", last-first+1,
+			"txs", txs, "failed", atomic.LoadUint64(&progress.failed),
+			"txs/s", fmt.Sprintf("%.1f", float64(txs)/elapsed.Seconds()),
+			"elapsed", common.PrettyDuration(elapsed))
+	}
+	ticker := time.NewTicker(statsReportLimit)
+	defer ticker.Stop()
+
+	replayErr := pool.run(shards, interrupt, ticker.C, func() {
+		logProgress("Replaying substates")
+	})
+	logProgress("Substates replay is finished")
+
+	if replayErr != nil {
+		return replayErr
+	}
+	if failed := atomic.LoadUint64(&progress.failed); failed != 0 {
+		return fmt.Errorf("%d of %d transactions mismatched the recorded substates", failed, atomic.LoadUint64(&progress.txs))
+	}
+	return nil
+}
@@ -0,0 +1,180 @@
+package launcher
+
+import (
+	"errors"
+	"io/ioutil"
+	"math"
+	"math/big"
+	"os"
+	"sort"
+	"sync/atomic"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/substate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+func TestSplitBlockRange(t *testing.T) {
+	require := require.New(t)
+
+	require.Equal([]substateShard{{1, 1}}, splitBlockRange(1, 1, 10))
+	require.Equal([]substateShard{{1, 10}}, splitBlockRange(1, 10, 10))
+	require.Equal([]substateShard{{1, 10}, {11, 20}, {21, 25}}, splitBlockRange(1, 25, 10))
+	require.Equal([]substateShard{{5, 5}, {6, 6}}, splitBlockRange(5, 6, 0))
+	require.Equal([]substateShard{{math.MaxUint64 - 3, math.MaxUint64 - 2}, {math.MaxUint64 - 1, math.MaxUint64}},
+		splitBlockRange(math.MaxUint64-3, math.MaxUint64, 2))
+	require.Equal([]substateShard{{math.MaxUint64 - 3, math.MaxUint64}}, splitBlockRange(math.MaxUint64-3, math.MaxUint64, math.MaxUint64))
+}
+
+// fakeReplayShard replays every block of the shard with a report, which is mismatched for the given blocks.
+func fakeReplayShard(replayed *uint64, mismatched map[uint64]bool) func(substateShard, chan<- *evmcore.SubstateReplayReport, <-chan struct{}) error {
+	return func(shard substateShard, reports chan<- *evmcore.SubstateReplayReport, quit <-chan struct{}) error {
+		for block := shard.first; block <= shard.last; block++ {
+			select {
+			case <-quit:
+				return nil
+			default:
+			}
+			atomic.AddUint64(replayed, 1)
+			if !mismatched[block] {
+				continue
+			}
+			report := &evmcore.SubstateReplayReport{
+				Block:      block,
+				Mismatches: []evmcore.SubstateMismatch{{Field: "status"}},
+			}
+			select {
+			case reports <- report:
+			case <-quit:
+				return nil
+			}
+		}
+		return nil
+	}
+}
+
+func runFakeReplay(pool *substateReplayPool, shards []substateShard, interrupt <-chan os.Signal) ([]uint64, error) {
+	var reported []uint64
+	pool.onReport = func(report *evmcore.SubstateReplayReport) error {
+		reported = append(reported, report.Block)
+		return nil
+	}
+	err := pool.run(shards, interrupt, nil, func() {})
+	sort.Slice(reported, func(i, j int) bool {
+		return reported[i] < reported[j]
+	})
+	return reported, err
+}
+
+func TestSubstateReplayPool(t *testing.T) {
+	require := require.New(t)
+
+	var replayed uint64
+	pool := &substateReplayPool{
+		workers:     4,
+		replayShard: fakeReplayShard(&replayed, map[uint64]bool{3: true, 50: true, 100: true}),
+	}
+	reported, err := runFakeReplay(pool, splitBlockRange(1, 100, 7), nil)
+	require.NoError(err)
+	require.Equal(uint64(100), replayed)
+	require.Equal([]uint64{3, 50, 100}, reported)
+}
+
+func TestSubstateReplayPoolFailFast(t *testing.T) {
+	require := require.New(t)
+
+	mismatched := map[uint64]bool{}
+	for block := uint64(1); block <= 1000; block++ {
+		mismatched[block] = true
+	}
+	var replayed uint64
+	pool := &substateReplayPool{
+		workers:     2,
+		failFast:    true,
+		replayShard: fakeReplayShard(&replayed, mismatched),
+	}
+	reported, err := runFakeReplay(pool, splitBlockRange(1, 1000, 1), nil)
+	require.NoError(err)
+	require.NotEmpty(reported)
+	// besides the first mismatch, only the buffered and the in-flight reports may get through
+	require.LessOrEqual(len(reported), 1+2*pool.workers)
+	require.Less(atomic.LoadUint64(&replayed), uint64(10))
+}
+
+func TestSubstateReplayPoolErrors(t *testing.T) {
+	require := require.New(t)
+
+	// a failed shard stops the replay
+	var replayed uint64
+	replayShard := fakeReplayShard(&replayed, nil)
+	failure := errors.New("failure")
+	pool := &substateReplayPool{
+		workers: 2,
+		replayShard: func(shard substateShard, reports chan<- *evmcore.SubstateReplayReport, quit <-chan struct{}) error {
+			if shard.first == 3 {
+				return failure
+			}
+			return replayShard(shard, reports, quit)
+		},
+	}
+	_, err := runFakeReplay(pool, splitBlockRange(1, 1000, 1), nil)
+	require.Equal(failure, err)
+	require.Less(atomic.LoadUint64(&replayed), uint64(1000))
+
+	// a failed report stops the replay
+	replayed = 0
+	pool = &substateReplayPool{
+		workers:     2,
+		replayShard: fakeReplayShard(&replayed, map[uint64]bool{1: true, 2: true}),
+		onReport: func(*evmcore.SubstateReplayReport) error {
+			return failure
+		},
+	}
+	err = pool.run(splitBlockRange(1, 1000, 1), nil, nil, func() {})
+	require.Equal(failure, err)
+	require.Less(atomic.LoadUint64(&replayed), uint64(1000))
+
+	// an interrupt stops the replay
+	interrupt := make(chan os.Signal, 1)
+	interrupt <- os.Interrupt
+	replayed = 0
+	pool = &substateReplayPool{
+		workers: 2,
+		replayShard: func(shard substateShard, reports chan<- *evmcore.SubstateReplayReport, quit <-chan struct{}) error {
+			<-quit
+			return nil
+		},
+	}
+	_, err = runFakeReplay(pool, splitBlockRange(1, 1000, 1), interrupt)
+	require.EqualError(err, "interrupted")
+}
+
+func TestSubstateReplayerChainConfig(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "substate_replayer_test")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	require.NoError(evmcore.OpenSubstateMetaDB(dir))
+	defer evmcore.CloseSubstateMetaDB()
+
+	recorded := opera.FakeNetRules().EvmChainConfig()
+	evmcore.PutSubstateChainConfig(2, recorded)
+	evmcore.PutSubstateMeta(10, 0, &evmcore.SubstateMeta{Epoch: 2})
+	evmcore.PutSubstateMeta(20, 0, &evmcore.SubstateMeta{Epoch: 3})
+
+	r := newSubstateReplayer(opera.MainNetworkID)
+	preLondon := &substate.Substate{Env: &substate.SubstateEnv{}}
+	london := &substate.Substate{Env: &substate.SubstateEnv{BaseFee: big.NewInt(0)}}
+
+	// the recorded chain config is preferred
+	require.Equal(recorded.String(), r.chainConfig(10, 0, preLondon).String())
+	require.Equal(recorded.String(), r.chainConfig(10, 0, london).String())
+	// the mainnet rules are used if the chain config or the metadata aren't recorded
+	require.Equal(r.preLondon, r.chainConfig(20, 0, preLondon))
+	require.Equal(r.london, r.chainConfig(20, 0, london))
+	require.Equal(r.preLondon, r.chainConfig(30, 0, preLondon))
+}
@@ -235,6 +235,99 @@ func (s *PublicTxPoolAPI) Status() map[string]hexutil.Uint {
 	}
 }
 
+// PolicyStatus returns the numbers of transactions denied and deprioritised by the pool admission policy,
+// grouped by rule, and the most recent of them.
+func (s *PublicTxPoolAPI) PolicyStatus() map[string]interface{} {
+	stats := s.b.TxPoolPolicyStats()
+
+	denied := make(map[string]hexutil.Uint64, len(stats.Denied))
+	for rule, n := range stats.Denied {
+		denied[rule] = hexutil.Uint64(n)
+	}
+	deprioritised := make(map[string]hexutil.Uint64, len(stats.Deprioritised))
+	for rule, n := range stats.Deprioritised {
+		deprioritised[rule] = hexutil.Uint64(n)
+	}
+	recent := make([]map[string]interface{}, 0, len(stats.Recent))
+	for _, r := range stats.Recent {
+		recent = append(recent, map[string]interface{}{
+			"hash":      r.Hash,
+			"from":      r.From,
+			"to":        r.To,
+			"action":    r.Action,
+			"rule":      r.Rule,
+			"timestamp": hexutil.Uint64(r.Time.Unix()),
+		})
+	}
+	return map[string]interface{}{
+		"denied":        denied,
+		"deprioritised": deprioritised,
+		"recent":        recent,
+	}
+}
+
+// Dropped returns the transactions of the account which were recently dropped from the pool
+// before they were included in a block, with the reasons, the oldest first.
+func (s *PublicTxPoolAPI) Dropped(addr common.Address) []map[string]interface{} {
+	dropped := s.b.TxPoolDropped(addr)
+	curHeader := s.b.CurrentBlock().Header()
+
+	res := make([]map[string]interface{}, 0, len(dropped))
+	for _, d := range dropped {
+		res = append(res, rpcMarshalDroppedTx(d, curHeader.BaseFee))
+	}
+	return res
+}
+
+// txpoolNotifyBuffer is the size of channel buffer for the dropped transactions subscription
+const txpoolNotifyBuffer = 128
+
+// DroppedTransactions sends a notification each time a transaction is dropped from the pool
+// before it's included in a block. If addr is specified, only the transactions of the account are notified.
+func (s *PublicTxPoolAPI) DroppedTransactions(ctx context.Context, addr *common.Address) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		dropped := make(chan evmcore.DroppedTxsNotify, txpoolNotifyBuffer)
+		droppedSub := s.b.SubscribeDroppedTxsNotify(dropped)
+		defer droppedSub.Unsubscribe()
+
+		for {
+			select {
+			case ev := <-dropped:
+				baseFee := s.b.CurrentBlock().Header().BaseFee
+				for _, d := range ev.Txs {
+					if addr != nil && d.From != *addr {
+						continue
+					}
+					_ = notifier.Notify(rpcSub.ID, rpcMarshalDroppedTx(d, baseFee))
+				}
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-droppedSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+func rpcMarshalDroppedTx(d *evmcore.DroppedTx, baseFee *big.Int) map[string]interface{} {
+	return map[string]interface{}{
+		"transaction": newRPCPendingTransaction(d.Tx, baseFee),
+		"reason":      d.Reason,
+		"timestamp":   hexutil.Uint64(d.Time.Unix()),
+	}
+}
+
 // Inspect retrieves the content of the transaction pool and flattens it into an
 // easily inspectable list.
 func (s *PublicTxPoolAPI) Inspect() map[string]map[string]map[string]string {
@@ -831,6 +924,31 @@ func (s *PublicBlockChainAPI) GetBlockByHash(ctx context.Context, hash common.Ha
 	return nil, err
 }
 
+// GetBlockReceipts returns the receipts of all the transactions of the block.
+func (s *PublicBlockChainAPI) GetBlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]map[string]interface{}, error) {
+	number, err := s.b.ResolveRpcBlockNumberOrHash(ctx, blockNrOrHash)
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.b.BlockByNumber(ctx, rpc.BlockNumber(number))
+	if block == nil || err != nil {
+		return nil, err
+	}
+	receipts, err := s.b.GetReceiptsByNumber(ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return nil, err
+	}
+	if receipts.Len() != len(block.Transactions) {
+		return nil, fmt.Errorf("receipts of block #%d are inconsistent with the transactions", number)
+	}
+	signer := gsignercache.Wrap(types.MakeSigner(s.b.ChainConfig(), block.Number))
+	res := make([]map[string]interface{}, len(receipts))
+	for i, receipt := range receipts {
+		res[i] = marshalReceipt(receipt, &block.EvmHeader, block.Transactions[i], uint64(i), signer)
+	}
+	return res, nil
+}
+
 // GetUncleByBlockNumberAndIndex returns the uncle block for the given block hash and index. When fullTx is true
 // all transactions in the block are returned in full detail, otherwise only the transaction hash is returned.
 func (s *PublicBlockChainAPI) GetUncleByBlockNumberAndIndex(ctx context.Context, blockNr rpc.BlockNumber, index hexutil.Uint) (map[string]interface{}, error) {
@@ -946,14 +1064,20 @@ func (diff *StateOverride) Apply(state *state.StateDB) error {
 }
 
 func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64) (*evmcore.ExecutionResult, error) {
+	result, _, err := doCall(ctx, b, args, blockNrOrHash, overrides, timeout, globalGasCap, false)
+	return result, err
+}
+
+// doCall is DoCall which optionally records the state diff of the call.
+func doCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, timeout time.Duration, globalGasCap uint64, withStateDiff bool) (*evmcore.ExecutionResult, StateDiff, error) {
 	defer func(start time.Time) { log.Debug("Executing EVM call finished", "runtime", time.Since(start)) }(time.Now())
 
 	state, header, err := b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
 	if state == nil || err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if err := overrides.Apply(state); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Setup context so it may be cancelled the call has completed
 	// or, in case of unmetered gas, setup a context with a timeout.
@@ -970,13 +1094,19 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 	// Get a new instance of the EVM.
 	msg, err := args.ToMessage(globalGasCap, header.BaseFee)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	vmConfig := opera.DefaultVMConfig
 	vmConfig.NoBaseFee = true
+	var recorder *stateDiffRecorder
+	if withStateDiff {
+		state.Prepare(common.Hash{}, 0)
+		recorder = newStateDiffRecorder(state)
+		recorder.configure(&vmConfig)
+	}
 	evm, vmError, err := b.GetEVM(ctx, msg, state, header, &vmConfig)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	// Wait for the context to be done and cancel the evm. Even if the
 	// EVM has finished, cancelling may be done (repeatedly)
@@ -989,17 +1119,21 @@ func DoCall(ctx context.Context, b Backend, args TransactionArgs, blockNrOrHash
 	gp := new(evmcore.GasPool).AddGas(math.MaxUint64)
 	result, err := evmcore.ApplyMessage(evm, msg, gp)
 	if err := vmError(); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	// If the timer caused an abort, return an appropriate error message
 	if evm.Cancelled() {
-		return nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
+		return nil, nil, fmt.Errorf("execution aborted (timeout = %v)", timeout)
 	}
 	if err != nil {
-		return result, fmt.Errorf("err: %w (supplied gas %d)", err, msg.Gas())
+		return result, nil, fmt.Errorf("err: %w (supplied gas %d)", err, msg.Gas())
 	}
-	return result, nil
+	if recorder != nil {
+		state.Finalise(true)
+		return result, recorder.diff(), nil
+	}
+	return result, nil, nil
 }
 
 func newRevertError(result *evmcore.ExecutionResult) *revertError {
@@ -1032,14 +1166,27 @@ func (e *revertError) ErrorData() interface{} {
 	return e.reason
 }
 
+// CallOptions are the extra options of a call.
+type CallOptions struct {
+	StateDiff bool `json:"stateDiff"`
+}
+
+// CallResult is the result of a call with the state diff requested.
+type CallResult struct {
+	Output    hexutil.Bytes `json:"output"`
+	StateDiff StateDiff     `json:"stateDiff"`
+}
+
 // Call executes the given transaction on the state for the given block number.
 //
 // Additionally, the caller can specify a batch of contract for fields overriding.
+// If the stateDiff option is set, the result is a CallResult with the state changes of the call.
 //
 // Note, this function doesn't make and changes in the state/blockchain and is
 // useful to execute and retrieve values.
-func (s *PublicBlockChainAPI) Call(ctx context.Context, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride) (hexutil.Bytes, error) {
-	result, err := DoCall(ctx, s.b, args, blockNrOrHash, overrides, 5*time.Second, s.b.RPCGasCap())
+func (s *PublicBlockChainAPI) Call(ctx context.Context, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, opts *CallOptions) (interface{}, error) {
+	withStateDiff := opts != nil && opts.StateDiff
+	result, stateDiff, err := doCall(ctx, s.b, args, blockNrOrHash, overrides, 5*time.Second, s.b.RPCGasCap(), withStateDiff)
 	if err != nil {
 		return nil, err
 	}
@@ -1047,7 +1194,13 @@ func (s *PublicBlockChainAPI) Call(ctx context.Context, args TransactionArgs, bl
 	if len(result.Revert()) > 0 {
 		return nil, newRevertError(result)
 	}
-	return result.Return(), result.Err
+	if withStateDiff && result.Err == nil {
+		return &CallResult{
+			Output:    result.Return(),
+			StateDiff: stateDiff,
+		}, nil
+	}
+	return hexutil.Bytes(result.Return()), result.Err
 }
 
 // DoEstimateGas - binary search the gas requirement, as it may be higher than the amount used
@@ -1682,15 +1835,22 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	}
 	receipt := receipts[index]
 
+	// Derive the sender.
+	bigblock := new(big.Int).SetUint64(blockNumber)
+	signer := gsignercache.Wrap(types.MakeSigner(s.b.ChainConfig(), bigblock))
+	return marshalReceipt(receipt, header, tx, index, signer), nil
+}
+
+// marshalReceipt converts the receipt of the block tx into the RPC representation.
+func marshalReceipt(receipt *types.Receipt, header *evmcore.EvmHeader, tx *types.Transaction, index uint64, signer types.Signer) map[string]interface{} {
+	hash := tx.Hash()
+	blockNumber := header.Number.Uint64()
 	for _, l := range receipt.Logs {
 		l.TxHash = hash
 		l.BlockHash = header.Hash
 		l.BlockNumber = blockNumber
 	}
 
-	// Derive the sender.
-	bigblock := new(big.Int).SetUint64(blockNumber)
-	signer := gsignercache.Wrap(types.MakeSigner(s.b.ChainConfig(), bigblock))
 	from, _ := internaltx.Sender(signer, tx)
 
 	fields := map[string]interface{}{
@@ -1727,7 +1887,7 @@ func (s *PublicTransactionPoolAPI) GetTransactionReceipt(ctx context.Context, ha
 	if tx.To() == nil {
 		fields["contractAddress"] = receipt.ContractAddress
 	}
-	return fields, nil
+	return fields
 }
 
 // sign is a helper function that signs a transaction with the private key of the given address.
@@ -1745,6 +1905,10 @@ func (s *PublicTransactionPoolAPI) sign(addr common.Address, tx *types.Transacti
 
 // SubmitTransaction is a helper function that submits tx to txPool and logs a message.
 func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (common.Hash, error) {
+	return submitTransaction(ctx, b, tx, b.SendTx)
+}
+
+func submitTransaction(ctx context.Context, b Backend, tx *types.Transaction, send func(context.Context, *types.Transaction) error) (common.Hash, error) {
 	// If the transaction fee cap is already specified, ensure the
 	// fee of the given transaction is _reasonable_.
 	if err := checkTxFee(tx.GasPrice(), tx.Gas(), b.RPCTxFeeCap()); err != nil {
@@ -1754,7 +1918,7 @@ func SubmitTransaction(ctx context.Context, b Backend, tx *types.Transaction) (c
 		// Ensure only eip155 signed transactions are submitted if EIP155Required is set.
 		return common.Hash{}, errors.New("only replay-protected (EIP-155) transactions allowed over RPC")
 	}
-	if err := b.SendTx(ctx, tx); err != nil {
+	if err := send(ctx, tx); err != nil {
 		return common.Hash{}, err
 	} // Print a log with full tx details for manual investigations and interventions
 	signer := gsignercache.Wrap(types.MakeSigner(b.ChainConfig(), b.CurrentBlock().Number))
@@ -1831,6 +1995,17 @@ func (s *PublicTransactionPoolAPI) SendRawTransaction(ctx context.Context, encod
 	return SubmitTransaction(ctx, s.b, tx)
 }
 
+// SendPrivateTransaction will add the signed transaction to the local transaction pool
+// without gossiping it to peers, so it may be originated only by the local validator
+// until it falls back to public gossip after a configured number of epochs.
+func (s *PublicTransactionPoolAPI) SendPrivateTransaction(ctx context.Context, encodedTx hexutil.Bytes) (common.Hash, error) {
+	tx := new(types.Transaction)
+	if err := tx.UnmarshalBinary(encodedTx); err != nil {
+		return common.Hash{}, err
+	}
+	return submitTransaction(ctx, s.b, tx, s.b.SendPrivateTx)
+}
+
 // Sign calculates an ECDSA signature for:
 // keccack256("\x19Ethereum Signed Message:\n" + len(message) + message).
 //
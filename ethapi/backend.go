@@ -31,11 +31,13 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	notify "github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 
 	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 )
 
 // PeerProgress is synchronization status of a peer
@@ -58,9 +60,10 @@ type Backend interface {
 	ChainDb() ethdb.Database
 	AccountManager() *accounts.Manager
 	ExtRPCEnabled() bool
-	RPCGasCap() uint64        // global gas cap for eth_call over rpc: DoS protection
-	RPCTxFeeCap() float64     // global tx fee cap for all transaction related APIs
-	UnprotectedAllowed() bool // allows only for EIP155 transactions.
+	RPCGasCap() uint64           // global gas cap for eth_call over rpc: DoS protection
+	RPCTxFeeCap() float64        // global tx fee cap for all transaction related APIs
+	RPCReceiptsRangeCap() uint64 // global cap of blocks range for bulk receipts API
+	UnprotectedAllowed() bool    // allows only for EIP155 transactions.
 	CalcBlockExtApi() bool
 
 	// Blockchain API
@@ -71,6 +74,10 @@ type Backend interface {
 	ResolveRpcBlockNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (idx.Block, error)
 	BlockByHash(ctx context.Context, hash common.Hash) (*evmcore.EvmBlock, error)
 	GetReceiptsByNumber(ctx context.Context, number rpc.BlockNumber) (types.Receipts, error)
+	GetRawReceiptsRLP(ctx context.Context, number idx.Block) (rlp.RawValue, error)
+	CallTracesIndexed() bool
+	GetCallTraces(ctx context.Context, number idx.Block) ([][]txtrace.ActionTrace, error)
+	ForEachCallTracePosition(ctx context.Context, addr common.Address, recipient bool, from, to idx.Block, onPosition func(txtrace.Position) bool) error
 	GetTd(hash common.Hash) *big.Int
 	GetEVM(ctx context.Context, msg evmcore.Message, state *state.StateDB, header *evmcore.EvmHeader, vmConfig *vm.Config) (*vm.EVM, func() error, error)
 	MinGasPrice() *big.Int
@@ -78,6 +85,7 @@ type Backend interface {
 
 	// Transaction pool API
 	SendTx(ctx context.Context, signedTx *types.Transaction) error
+	SendPrivateTx(ctx context.Context, signedTx *types.Transaction) error
 	GetTransaction(ctx context.Context, txHash common.Hash) (*types.Transaction, uint64, uint64, error)
 	GetPoolTransactions() (types.Transactions, error)
 	GetPoolTransaction(txHash common.Hash) *types.Transaction
@@ -85,7 +93,10 @@ type Backend interface {
 	Stats() (pending int, queued int)
 	TxPoolContent() (map[common.Address]types.Transactions, map[common.Address]types.Transactions)
 	TxPoolContentFrom(addr common.Address) (types.Transactions, types.Transactions)
+	TxPoolPolicyStats() evmcore.TxPolicyStats
+	TxPoolDropped(addr common.Address) []*evmcore.DroppedTx
 	SubscribeNewTxsNotify(chan<- evmcore.NewTxsNotify) notify.Subscription
+	SubscribeDroppedTxsNotify(chan<- evmcore.DroppedTxsNotify) notify.Subscription
 
 	ChainConfig() *params.ChainConfig
 	CurrentBlock() *evmcore.EvmBlock
@@ -94,8 +105,13 @@ type Backend interface {
 	GetEventPayload(ctx context.Context, shortEventID string) (*inter.EventPayload, error)
 	GetEvent(ctx context.Context, shortEventID string) (*inter.Event, error)
 	GetHeads(ctx context.Context, epoch rpc.BlockNumber) (hash.Events, error)
+	ForEachEpochEventFrom(ctx context.Context, epoch rpc.BlockNumber, lamport idx.Lamport, onEvent func(event *inter.EventPayload) bool) error
 	CurrentEpoch(ctx context.Context) idx.Epoch
 	SealedEpochTiming(ctx context.Context) (start inter.Timestamp, end inter.Timestamp)
+	GetBlockEvents(ctx context.Context, number rpc.BlockNumber) (hash.Events, error)
+	SubscribeNewEventNotify(ch chan<- *inter.EventPayload) notify.Subscription
+	SubscribeNewEpochNotify(ch chan<- idx.Epoch) notify.Subscription
+	SubscribeNewBlockNotify(ch chan<- evmcore.ChainHeadNotify) notify.Subscription
 
 	// Lachesis aBFT API
 	GetEpochBlockState(ctx context.Context, epoch rpc.BlockNumber) (*iblockproc.BlockState, *iblockproc.EpochState, error)
@@ -141,6 +157,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Namespace: "debug",
 			Version:   "1.0",
 			Service:   NewPrivateDebugAPI(apiBackend),
+		}, {
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   NewPublicTxTraceAPI(apiBackend),
+			Public:    true,
 		}, {
 			Namespace: "eth",
 			Version:   "1.0",
@@ -156,6 +177,11 @@ func GetAPIs(apiBackend Backend) []rpc.API {
 			Version:   "1.0",
 			Service:   NewPublicAbftAPI(apiBackend),
 			Public:    true,
+		}, {
+			Namespace: "ftm",
+			Version:   "1.0",
+			Service:   NewPublicReceiptsAPI(apiBackend),
+			Public:    true,
 		},
 	}
 
@@ -0,0 +1,159 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+const (
+	// bundleTimeout is the amount of time a whole bundle can execute before being aborted.
+	bundleTimeout = 10 * time.Second
+	// maxBundleSize is the max count of transactions in a bundle.
+	maxBundleSize = 100
+)
+
+// BlockOverrides overrides the block header fields of a simulation.
+type BlockOverrides struct {
+	Number  *hexutil.Big    `json:"number"`
+	Time    *hexutil.Uint64 `json:"time"` // UNIX seconds
+	BaseFee *hexutil.Big    `json:"baseFee"`
+}
+
+// Apply returns a copy of the header with the overridden fields.
+func (o *BlockOverrides) Apply(header *evmcore.EvmHeader) *evmcore.EvmHeader {
+	if o == nil {
+		return header
+	}
+	cp := *header
+	if o.Number != nil {
+		cp.Number = new(big.Int).Set(o.Number.ToInt())
+	}
+	if o.Time != nil {
+		cp.Time = inter.FromUnix(int64(*o.Time))
+	}
+	if o.BaseFee != nil {
+		cp.BaseFee = new(big.Int).Set(o.BaseFee.ToInt())
+	}
+	return &cp
+}
+
+// BundleTxResult is the result of a simulated bundle transaction.
+type BundleTxResult struct {
+	GasUsed     hexutil.Uint64 `json:"gasUsed"`
+	ReturnValue hexutil.Bytes  `json:"returnValue"`
+	Error       string         `json:"error,omitempty"`
+	Logs        []*types.Log   `json:"logs"`
+	StateDiff   StateDiff      `json:"stateDiff"`
+}
+
+// SimulateBundle executes the transactions sequentially on top of the state of the given block, so each
+// transaction observes the changes of the previous ones. The block header fields and the state may be overridden.
+// A failed (e.g. reverted) transaction doesn't abort the bundle, but a transaction which isn't
+// executable at all (e.g. because of insufficient funds) does.
+//
+// Note, this function doesn't make any changes in the state/blockchain.
+func (s *PublicBlockChainAPI) SimulateBundle(ctx context.Context, txs []TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, overrides *StateOverride, blockOverrides *BlockOverrides) ([]*BundleTxResult, error) {
+	defer func(start time.Time) { log.Debug("Executing EVM bundle finished", "runtime", time.Since(start)) }(time.Now())
+
+	if len(txs) == 0 {
+		return nil, errors.New("empty bundle")
+	}
+	if len(txs) > maxBundleSize {
+		return nil, fmt.Errorf("too big bundle, the limit is %d transactions", maxBundleSize)
+	}
+
+	statedb, header, err := s.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	if err := overrides.Apply(statedb); err != nil {
+		return nil, err
+	}
+	header = blockOverrides.Apply(header)
+
+	ctx, cancel := context.WithTimeout(ctx, bundleTimeout)
+	defer cancel()
+
+	// The whole bundle is limited by the global gas cap
+	gasCap := s.b.RPCGasCap()
+	gp := new(evmcore.GasPool).AddGas(math.MaxUint64)
+	if gasCap != 0 {
+		gp = new(evmcore.GasPool).AddGas(gasCap)
+	}
+
+	results := make([]*BundleTxResult, 0, len(txs))
+	for i, args := range txs {
+		msg, err := args.ToMessage(gasCap, header.BaseFee)
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w", i, err)
+		}
+		// there is no tx hash, so logs are keyed by a pseudo one
+		pseudoHash := common.BigToHash(big.NewInt(int64(i)))
+		statedb.Prepare(pseudoHash, i)
+		recorder := newStateDiffRecorder(statedb)
+
+		vmConfig := opera.DefaultVMConfig
+		vmConfig.NoBaseFee = true
+		recorder.configure(&vmConfig)
+		evm, vmError, err := s.b.GetEVM(ctx, msg, statedb, header, &vmConfig)
+		if err != nil {
+			return nil, err
+		}
+		done := make(chan struct{})
+		go func() {
+			select {
+			case <-ctx.Done():
+				evm.Cancel()
+			case <-done:
+			}
+		}()
+		result, err := evmcore.ApplyMessage(evm, msg, gp)
+		close(done)
+		if err := vmError(); err != nil {
+			return nil, err
+		}
+		if evm.Cancelled() {
+			return nil, fmt.Errorf("execution aborted (timeout = %v)", bundleTimeout)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("tx %d: %w (supplied gas %d)", i, err, msg.Gas())
+		}
+		statedb.Finalise(true)
+
+		logs := statedb.GetLogs(pseudoHash, header.Hash)
+		for _, l := range logs {
+			l.TxHash = common.Hash{}
+		}
+		res := &BundleTxResult{
+			GasUsed:     hexutil.Uint64(result.UsedGas),
+			ReturnValue: result.Return(),
+			Logs:        logs,
+			StateDiff:   recorder.diff(),
+		}
+		if len(result.Revert()) > 0 {
+			res.ReturnValue = result.Revert()
+			res.Error = newRevertError(result).Error()
+		} else if result.Err != nil {
+			res.Error = result.Err.Error()
+		}
+		if res.Logs == nil {
+			res.Logs = []*types.Log{}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
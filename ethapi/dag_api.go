@@ -1,16 +1,21 @@
 package ethapi
 
 import (
+	"bytes"
 	"context"
 	"errors"
 	"fmt"
 	"math/big"
 
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/log"
 	"github.com/ethereum/go-ethereum/rpc"
 
+	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 )
 
 // PublicDAGChainAPI provides an API to access the directed acyclic graph chain.
@@ -61,6 +66,116 @@ func (s *PublicDAGChainAPI) GetHeads(ctx context.Context, epoch rpc.BlockNumber)
 	return inter.EventIDsToHex(res), nil
 }
 
+const (
+	defaultEventsPageSize = 100
+	maxEventsPageSize     = 1000
+)
+
+var errCursorEpoch = errors.New("cursor doesn't belong to the requested epoch")
+
+// EventsFilterArgs are the criteria and paging options of dag_getEvents.
+type EventsFilterArgs struct {
+	Creator     *hexutil.Uint64 `json:"creator"`
+	FromLamport *hexutil.Uint64 `json:"fromLamport"`
+	ToLamport   *hexutil.Uint64 `json:"toLamport"`
+	FromSeq     *hexutil.Uint64 `json:"fromSeq"`
+	Limit       *hexutil.Uint64 `json:"limit"`
+	// Cursor is the ID of the last event of the previous page
+	Cursor *hexutil.Bytes `json:"cursor"`
+	// Payload enables returning of event payloads rather than headers
+	Payload bool `json:"payload"`
+	InclTx  bool `json:"inclTx"`
+}
+
+func (args *EventsFilterArgs) match(e *inter.EventPayload) bool {
+	if args.Creator != nil && e.Creator() != idx.ValidatorID(*args.Creator) {
+		return false
+	}
+	if args.FromSeq != nil && e.Seq() < idx.Event(*args.FromSeq) {
+		return false
+	}
+	return true
+}
+
+// GetEvents returns a page of the epoch events, ordered by Lamport time, which match the filter.
+// The returned cursor (null for the last page) has to be passed to get the next page.
+// * When epoch is -2 the events of latest epoch are returned.
+// * When epoch is -1 the events of latest sealed epoch are returned.
+func (s *PublicDAGChainAPI) GetEvents(ctx context.Context, epoch rpc.BlockNumber, args EventsFilterArgs) (map[string]interface{}, error) {
+	limit := uint64(defaultEventsPageSize)
+	if args.Limit != nil {
+		limit = uint64(*args.Limit)
+	}
+	if limit == 0 || limit > maxEventsPageSize {
+		return nil, fmt.Errorf("limit must be within [1, %d]", maxEventsPageSize)
+	}
+	var fromLamport idx.Lamport
+	if args.FromLamport != nil {
+		fromLamport = idx.Lamport(*args.FromLamport)
+	}
+	var cursor *hash.Event
+	if args.Cursor != nil {
+		if len(*args.Cursor) != len(hash.Event{}) {
+			return nil, errors.New("invalid cursor")
+		}
+		id := hash.BytesToEvent(*args.Cursor)
+		if epoch >= 0 && id.Epoch() != idx.Epoch(epoch) {
+			return nil, errCursorEpoch
+		}
+		cursor = &id
+		if id.Lamport() > fromLamport {
+			fromLamport = id.Lamport()
+		}
+	}
+
+	events := make([]map[string]interface{}, 0, limit)
+	var last hash.Event
+	var err error
+	iterErr := s.b.ForEachEpochEventFrom(ctx, epoch, fromLamport, func(e *inter.EventPayload) bool {
+		// latest epochs may be switched since the previous page
+		if cursor != nil && e.Epoch() != cursor.Epoch() {
+			err = errCursorEpoch
+			return false
+		}
+		if args.ToLamport != nil && e.Lamport() > idx.Lamport(*args.ToLamport) {
+			return false
+		}
+		if cursor != nil && bytes.Compare(e.ID().Bytes(), cursor.Bytes()) <= 0 {
+			return true
+		}
+		if !args.match(e) {
+			return true
+		}
+		var fields map[string]interface{}
+		if args.Payload {
+			fields, err = inter.RPCMarshalEventPayload(e, args.InclTx, false)
+			if err != nil {
+				return false
+			}
+		} else {
+			fields = inter.RPCMarshalEvent(e)
+		}
+		events = append(events, fields)
+		last = e.ID()
+		return uint64(len(events)) < limit
+	})
+	if iterErr != nil {
+		return nil, iterErr
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := map[string]interface{}{
+		"events": events,
+		"cursor": nil,
+	}
+	if uint64(len(events)) == limit {
+		res["cursor"] = hexutil.Bytes(last.Bytes())
+	}
+	return res, nil
+}
+
 // GetEpochStats returns epoch statistics.
 // * When epoch is -2 the statistics for latest epoch is returned.
 // * When epoch is -1 the statistics for latest sealed epoch is returned.
@@ -79,3 +194,136 @@ func (s *PublicBlockChainAPI) GetEpochStats(ctx context.Context, requestedEpoch
 		"totalTxRewardWeight":   (*hexutil.Big)(new(big.Int)),
 	}, nil
 }
+
+// dagNotifyBuffer is the size of notifications buffer of a DAG subscription.
+const dagNotifyBuffer = 128
+
+// NewEvents sends a notification each time a new event is connected to the DAG,
+// either received from peers or emitted by the node.
+func (s *PublicDAGChainAPI) NewEvents(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		events := make(chan *inter.EventPayload, dagNotifyBuffer)
+		eventsSub := s.b.SubscribeNewEventNotify(events)
+		defer eventsSub.Unsubscribe()
+
+		for {
+			select {
+			case e := <-events:
+				_ = notifier.Notify(rpcSub.ID, inter.RPCMarshalEvent(e))
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-eventsSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+// NewEpoch sends a notification each time a new epoch is started, with the epoch validators and rules.
+func (s *PublicDAGChainAPI) NewEpoch(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		epochs := make(chan idx.Epoch, dagNotifyBuffer)
+		epochsSub := s.b.SubscribeNewEpochNotify(epochs)
+		defer epochsSub.Unsubscribe()
+
+		for {
+			select {
+			case epoch := <-epochs:
+				_, es, err := s.b.GetEpochBlockState(context.Background(), rpc.BlockNumber(epoch))
+				if err != nil || es == nil {
+					log.Warn("Failed to get epoch state for notification", "epoch", epoch, "err", err)
+					continue
+				}
+				_ = notifier.Notify(rpcSub.ID, rpcMarshalEpoch(es))
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-epochsSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
+
+func rpcMarshalEpoch(es *iblockproc.EpochState) map[string]interface{} {
+	validators := make([]map[string]interface{}, 0, es.Validators.Len())
+	for _, vid := range es.Validators.IDs() {
+		profile := es.ValidatorProfiles[vid]
+		validators = append(validators, map[string]interface{}{
+			"id":     hexutil.Uint64(vid),
+			"weight": (*hexutil.Big)(profile.Weight),
+			"pubkey": profile.PubKey.String(),
+		})
+	}
+	return map[string]interface{}{
+		"epoch":      hexutil.Uint64(es.Epoch),
+		"start":      hexutil.Uint64(es.EpochStart),
+		"validators": validators,
+		"rules":      es.Rules,
+	}
+}
+
+// NewBlock sends a notification each time a new block is decided, with its Atropos and the confirmed events.
+func (s *PublicDAGChainAPI) NewBlock(ctx context.Context) (*rpc.Subscription, error) {
+	notifier, supported := rpc.NotifierFromContext(ctx)
+	if !supported {
+		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
+	}
+
+	rpcSub := notifier.CreateSubscription()
+
+	go func() {
+		blocks := make(chan evmcore.ChainHeadNotify, dagNotifyBuffer)
+		blocksSub := s.b.SubscribeNewBlockNotify(blocks)
+		defer blocksSub.Unsubscribe()
+
+		for {
+			select {
+			case b := <-blocks:
+				number := b.Block.NumberU64()
+				events, err := s.b.GetBlockEvents(context.Background(), rpc.BlockNumber(number))
+				if err != nil {
+					log.Warn("Failed to get block events for notification", "block", number, "err", err)
+					continue
+				}
+				// block hash is the Atropos event ID
+				_ = notifier.Notify(rpcSub.ID, map[string]interface{}{
+					"number":        hexutil.Uint64(number),
+					"atropos":       hexutil.Bytes(b.Block.Hash.Bytes()),
+					"timestamp":     hexutil.Uint64(b.Block.Time.Unix()),
+					"timestampNano": hexutil.Uint64(b.Block.Time),
+					"events":        inter.EventIDsToHex(events),
+				})
+			case <-rpcSub.Err():
+				return
+			case <-notifier.Closed():
+				return
+			case <-blocksSub.Err():
+				return
+			}
+		}
+	}()
+
+	return rpcSub, nil
+}
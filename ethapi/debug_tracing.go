@@ -0,0 +1,374 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/eth/tracers"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// defaultTraceTimeout is the amount of time a single transaction can execute
+// by default before being forcefully aborted.
+const defaultTraceTimeout = 5 * time.Second
+
+// TraceConfig holds extra parameters to trace functions.
+type TraceConfig struct {
+	*vm.LogConfig
+	Tracer  *string
+	Timeout *string
+}
+
+// TraceCallConfig is the config for traceCall API. It holds one more
+// field to override the state for tracing.
+type TraceCallConfig struct {
+	*vm.LogConfig
+	Tracer         *string
+	Timeout        *string
+	StateOverrides *StateOverride
+}
+
+// txTraceResult is the result of a single transaction trace.
+type txTraceResult struct {
+	Result interface{} `json:"result,omitempty"` // Trace results produced by the tracer
+	Error  string      `json:"error,omitempty"`  // Trace failure produced by the tracer
+}
+
+// txTracer is either the struct logger or a JS tracer, configured by TraceConfig.
+type txTracer struct {
+	vm.Tracer
+	result func(gas uint64, failed bool) (interface{}, error)
+	cancel context.CancelFunc
+}
+
+func newTxTracer(ctx context.Context, config *TraceConfig, txctx *tracers.Context) (*txTracer, error) {
+	if config == nil {
+		config = &TraceConfig{}
+	}
+	if config.Tracer == nil {
+		logger := vm.NewStructLogger(config.LogConfig)
+		return &txTracer{
+			Tracer: logger,
+			result: func(gas uint64, failed bool) (interface{}, error) {
+				return &ExecutionResult{
+					Gas:         gas,
+					Failed:      failed,
+					ReturnValue: fmt.Sprintf("%x", logger.Output()),
+					StructLogs:  FormatLogs(logger.StructLogs()),
+				}, nil
+			},
+			cancel: func() {},
+		}, nil
+	}
+
+	timeout := defaultTraceTimeout
+	if config.Timeout != nil {
+		var err error
+		if timeout, err = time.ParseDuration(*config.Timeout); err != nil {
+			return nil, err
+		}
+	}
+	tracer, err := tracers.New(*config.Tracer, txctx)
+	if err != nil {
+		return nil, err
+	}
+	// Handle timeouts and RPC cancellations
+	deadlineCtx, cancel := context.WithTimeout(ctx, timeout)
+	go func() {
+		<-deadlineCtx.Done()
+		if errors.Is(deadlineCtx.Err(), context.DeadlineExceeded) {
+			tracer.Stop(errors.New("execution timeout"))
+		}
+	}()
+	return &txTracer{
+		Tracer: tracer,
+		result: func(uint64, bool) (interface{}, error) {
+			return tracer.GetResult()
+		},
+		cancel: cancel,
+	}, nil
+}
+
+// chainHeaders provides headers for BLOCKHASH of the re-executed transactions.
+type chainHeaders struct {
+	ctx context.Context
+	b   Backend
+}
+
+func (c chainHeaders) GetHeader(_ common.Hash, number uint64) *evmcore.EvmHeader {
+	header, err := c.b.HeaderByNumber(c.ctx, rpc.BlockNumber(number))
+	if err != nil {
+		return nil
+	}
+	return header
+}
+
+// blockReplay re-executes transactions of a processed block on top of the state of the previous block.
+// Block transactions include internal transactions and exclude skipped ones, so
+// they are applied exactly as they were processed.
+type blockReplay struct {
+	block   *evmcore.EvmBlock
+	config  *params.ChainConfig
+	chain   evmcore.DummyChain
+	statedb *state.StateDB
+	usedGas uint64
+	applied int
+}
+
+func newBlockReplay(ctx context.Context, b Backend, block *evmcore.EvmBlock) (*blockReplay, error) {
+	if block.NumberU64() == 0 {
+		return nil, errors.New("genesis is not traceable")
+	}
+	parent := rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(block.NumberU64() - 1))
+	statedb, _, err := b.StateAndHeaderByNumberOrHash(ctx, parent)
+	if err != nil {
+		return nil, err
+	}
+	return &blockReplay{
+		block:   block,
+		config:  chainConfigAt(ctx, b, block),
+		chain:   chainHeaders{ctx, b},
+		statedb: statedb,
+	}, nil
+}
+
+// chainConfigAt returns the chain config of the rules which the block was processed with.
+func chainConfigAt(ctx context.Context, b Backend, block *evmcore.EvmBlock) *params.ChainConfig {
+	// block hash is the Atropos event ID
+	epoch := hash.Event(block.Hash).Epoch()
+	_, es, err := b.GetEpochBlockState(ctx, rpc.BlockNumber(epoch))
+	if err != nil || es == nil {
+		return b.ChainConfig()
+	}
+	return es.Rules.EvmChainConfig()
+}
+
+// process applies the next txs of the block through the state processor.
+func (r *blockReplay) process(txs types.Transactions, cfg vm.Config) (types.Receipts, []uint32, error) {
+	processor := evmcore.NewStateProcessor(r.config, r.chain).WithTxOffset(uint(r.applied)).WithoutRecording()
+	receipts, _, skipped, err := processor.Process(evmcore.NewEvmBlock(&r.block.EvmHeader, txs), r.statedb, cfg, &r.usedGas, func(*types.Log, *state.StateDB) {})
+	r.applied += len(txs)
+	return receipts, skipped, err
+}
+
+// applyUntil applies the block txs preceding the i-th one without tracing.
+func (r *blockReplay) applyUntil(i int) error {
+	if i <= r.applied {
+		return nil
+	}
+	txs := r.block.Transactions[r.applied:i]
+	_, skipped, err := r.process(txs, opera.DefaultVMConfig)
+	if err != nil {
+		return err
+	}
+	if len(skipped) != 0 {
+		return fmt.Errorf("transaction %#x isn't applicable on re-execution", txs[skipped[0]].Hash())
+	}
+	return nil
+}
+
+// traceNext traces the next tx of the block.
+func (r *blockReplay) traceNext(ctx context.Context, config *TraceConfig) (interface{}, error) {
+	i := r.applied
+	tx := r.block.Transactions[i]
+	tracer, err := newTxTracer(ctx, config, &tracers.Context{
+		BlockHash: r.block.Hash,
+		TxIndex:   i,
+		TxHash:    tx.Hash(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer tracer.cancel()
+
+	cfg := opera.DefaultVMConfig
+	cfg.Debug = true
+	cfg.Tracer = tracer
+	receipts, skipped, err := r.process(types.Transactions{tx}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) != 0 {
+		return nil, fmt.Errorf("transaction %#x isn't applicable on re-execution", tx.Hash())
+	}
+	return tracer.result(receipts[0].GasUsed, receipts[0].Status == types.ReceiptStatusFailed)
+}
+
+// stateDiffNext applies the next tx of the block and returns its state diff.
+func (r *blockReplay) stateDiffNext() (StateDiff, error) {
+	tx := r.block.Transactions[r.applied]
+	recorder := newStateDiffRecorder(r.statedb)
+	cfg := opera.DefaultVMConfig
+	recorder.configure(&cfg)
+	_, skipped, err := r.process(types.Transactions{tx}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) != 0 {
+		return nil, fmt.Errorf("transaction %#x isn't applicable on re-execution", tx.Hash())
+	}
+	return recorder.diff(), nil
+}
+
+// TraceTransaction returns the structured logs created during the execution of EVM
+// and returns them as a JSON object.
+func (api *PrivateDebugAPI) TraceTransaction(ctx context.Context, txHash common.Hash, config *TraceConfig) (interface{}, error) {
+	tx, blockNumber, index, err := api.b.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", txHash)
+	}
+	block, err := api.b.BlockByNumber(ctx, rpc.BlockNumber(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	replay, err := newBlockReplay(ctx, api.b, block)
+	if err != nil {
+		return nil, err
+	}
+	if err := replay.applyUntil(int(index)); err != nil {
+		return nil, err
+	}
+	return replay.traceNext(ctx, config)
+}
+
+// GetTransactionStateDiff returns the pre and post state of the accounts and storage slots
+// changed by the transaction, in the Parity stateDiff format.
+func (api *PrivateDebugAPI) GetTransactionStateDiff(ctx context.Context, txHash common.Hash) (StateDiff, error) {
+	tx, blockNumber, index, err := api.b.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", txHash)
+	}
+	block, err := api.b.BlockByNumber(ctx, rpc.BlockNumber(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	replay, err := newBlockReplay(ctx, api.b, block)
+	if err != nil {
+		return nil, err
+	}
+	if err := replay.applyUntil(int(index)); err != nil {
+		return nil, err
+	}
+	return replay.stateDiffNext()
+}
+
+// TraceBlockByNumber returns the structured logs created during the execution of
+// EVM and returns them as a JSON object.
+func (api *PrivateDebugAPI) TraceBlockByNumber(ctx context.Context, number rpc.BlockNumber, config *TraceConfig) ([]*txTraceResult, error) {
+	block, err := api.b.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	return api.traceBlock(ctx, block, config)
+}
+
+// TraceBlockByHash returns the structured logs created during the execution of
+// EVM and returns them as a JSON object.
+func (api *PrivateDebugAPI) TraceBlockByHash(ctx context.Context, blockHash common.Hash, config *TraceConfig) ([]*txTraceResult, error) {
+	block, err := api.b.BlockByHash(ctx, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block %#x not found", blockHash)
+	}
+	return api.traceBlock(ctx, block, config)
+}
+
+func (api *PrivateDebugAPI) traceBlock(ctx context.Context, block *evmcore.EvmBlock, config *TraceConfig) ([]*txTraceResult, error) {
+	replay, err := newBlockReplay(ctx, api.b, block)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*txTraceResult, len(block.Transactions))
+	for i := range block.Transactions {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res, err := replay.traceNext(ctx, config)
+		if err != nil && replay.applied == i {
+			// tracer isn't created, the tx isn't executed
+			return nil, err
+		}
+		if err != nil {
+			results[i] = &txTraceResult{Error: err.Error()}
+		} else {
+			results[i] = &txTraceResult{Result: res}
+		}
+	}
+	return results, nil
+}
+
+// TraceCall lets you trace a given eth_call. It collects the structured logs
+// created during the execution of EVM if the given transaction was added on
+// top of the provided block and returns them as a JSON object.
+func (api *PrivateDebugAPI) TraceCall(ctx context.Context, args TransactionArgs, blockNrOrHash rpc.BlockNumberOrHash, config *TraceCallConfig) (interface{}, error) {
+	statedb, header, err := api.b.StateAndHeaderByNumberOrHash(ctx, blockNrOrHash)
+	if statedb == nil || err != nil {
+		return nil, err
+	}
+	var traceConfig *TraceConfig
+	if config != nil {
+		if err := config.StateOverrides.Apply(statedb); err != nil {
+			return nil, err
+		}
+		traceConfig = &TraceConfig{
+			LogConfig: config.LogConfig,
+			Tracer:    config.Tracer,
+			Timeout:   config.Timeout,
+		}
+	}
+	msg, err := args.ToMessage(api.b.RPCGasCap(), header.BaseFee)
+	if err != nil {
+		return nil, err
+	}
+	tracer, err := newTxTracer(ctx, traceConfig, &tracers.Context{BlockHash: header.Hash})
+	if err != nil {
+		return nil, err
+	}
+	defer tracer.cancel()
+
+	vmConfig := opera.DefaultVMConfig
+	vmConfig.NoBaseFee = true
+	vmConfig.Debug = true
+	vmConfig.Tracer = tracer
+	evm, vmError, err := api.b.GetEVM(ctx, msg, statedb, header, &vmConfig)
+	if err != nil {
+		return nil, err
+	}
+	result, err := evmcore.ApplyMessage(evm, msg, new(evmcore.GasPool).AddGas(math.MaxUint64))
+	if err := vmError(); err != nil {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("tracing failed: %w", err)
+	}
+	return tracer.result(result.UsedGas, result.Failed())
+}
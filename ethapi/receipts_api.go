@@ -0,0 +1,64 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// PublicReceiptsAPI provides bulk access to receipts of blocks ranges.
+type PublicReceiptsAPI struct {
+	b Backend
+}
+
+// NewPublicReceiptsAPI creates a new receipts API.
+func NewPublicReceiptsAPI(b Backend) *PublicReceiptsAPI {
+	return &PublicReceiptsAPI{b}
+}
+
+// RawBlockReceipts are RLP-encoded storage receipts of a block.
+type RawBlockReceipts struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	Receipts    hexutil.Bytes  `json:"receipts"`
+}
+
+// GetReceiptsRange returns raw receipts of the [from, to] blocks range, as they are stored.
+// Blocks without receipts are omitted.
+func (s *PublicReceiptsAPI) GetReceiptsRange(ctx context.Context, from, to rpc.BlockNumber) ([]RawBlockReceipts, error) {
+	begin, err := s.b.ResolveRpcBlockNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(from))
+	if err != nil {
+		return nil, err
+	}
+	end, err := s.b.ResolveRpcBlockNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(to))
+	if err != nil {
+		return nil, err
+	}
+	if begin > end {
+		return nil, errors.New("from block is greater than to block")
+	}
+	if limit := s.b.RPCReceiptsRangeCap(); limit != 0 && uint64(end-begin)+1 > limit {
+		return nil, fmt.Errorf("blocks range exceeds the cap of %d blocks", limit)
+	}
+
+	res := make([]RawBlockReceipts, 0, end-begin+1)
+	for n := begin; n <= end; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		raw, err := s.b.GetRawReceiptsRLP(ctx, n)
+		if err != nil {
+			return nil, err
+		}
+		if raw == nil {
+			continue
+		}
+		res = append(res, RawBlockReceipts{
+			BlockNumber: hexutil.Uint64(n),
+			Receipts:    hexutil.Bytes(raw),
+		})
+	}
+	return res, nil
+}
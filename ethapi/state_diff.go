@@ -0,0 +1,223 @@
+package ethapi
+
+import (
+	"bytes"
+	"math/big"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/substate"
+)
+
+// StateDiff is the set of accounts changed by a transaction, in the Parity (OpenEthereum) stateDiff format.
+type StateDiff map[common.Address]*AccountDiff
+
+// AccountDiff is a change of an account. Every field is either "=" if unchanged,
+// {"+": value} if the account is created, {"-": value} if the account is destroyed,
+// or {"*": {"from": value, "to": value}} if changed. Only changed storage slots are present.
+type AccountDiff struct {
+	Balance interface{}                 `json:"balance"`
+	Nonce   interface{}                 `json:"nonce"`
+	Code    interface{}                 `json:"code"`
+	Storage map[common.Hash]interface{} `json:"storage"`
+}
+
+type fromTo struct {
+	From interface{} `json:"from"`
+	To   interface{} `json:"to"`
+}
+
+// diffValue returns the Parity diff of a field.
+func diffValue(from, to interface{}, born, died, equal bool) interface{} {
+	switch {
+	case born:
+		return map[string]interface{}{"+": to}
+	case died:
+		return map[string]interface{}{"-": from}
+	case equal:
+		return "="
+	default:
+		return map[string]interface{}{"*": fromTo{from, to}}
+	}
+}
+
+func emptyAccount(acc *substate.SubstateAccount) bool {
+	if acc == nil {
+		return true
+	}
+	if acc.Nonce != 0 || acc.Balance != nil && acc.Balance.Sign() != 0 || len(acc.Code) != 0 {
+		return false
+	}
+	for _, value := range acc.Storage {
+		if value != (common.Hash{}) {
+			return false
+		}
+	}
+	return true
+}
+
+// newStateDiff compares the post-transaction accounts against the pre-transaction ones.
+// Missing and empty accounts are considered as not existing.
+func newStateDiff(pre, post substate.SubstateAlloc) StateDiff {
+	addrs := make(map[common.Address]struct{}, len(pre)+len(post))
+	for addr := range pre {
+		addrs[addr] = struct{}{}
+	}
+	for addr := range post {
+		addrs[addr] = struct{}{}
+	}
+
+	diff := make(StateDiff)
+	for addr := range addrs {
+		from, to := pre[addr], post[addr]
+		born, died := emptyAccount(from), emptyAccount(to)
+		if born && died {
+			continue
+		}
+		if from == nil {
+			from = &substate.SubstateAccount{}
+		}
+		if to == nil {
+			to = &substate.SubstateAccount{}
+		}
+		fromBalance, toBalance := new(big.Int), new(big.Int)
+		if from.Balance != nil {
+			fromBalance.Set(from.Balance)
+		}
+		if to.Balance != nil {
+			toBalance.Set(to.Balance)
+		}
+
+		account := &AccountDiff{
+			Balance: diffValue((*hexutil.Big)(fromBalance), (*hexutil.Big)(toBalance), born, died, fromBalance.Cmp(toBalance) == 0),
+			Nonce:   diffValue(hexutil.Uint64(from.Nonce), hexutil.Uint64(to.Nonce), born, died, from.Nonce == to.Nonce),
+			Code:    diffValue(hexutil.Bytes(from.Code), hexutil.Bytes(to.Code), born, died, bytes.Equal(from.Code, to.Code)),
+			Storage: make(map[common.Hash]interface{}),
+		}
+		changed := born || died || account.Balance != "=" || account.Nonce != "=" || account.Code != "="
+
+		slots := make(map[common.Hash]struct{}, len(from.Storage)+len(to.Storage))
+		for key := range from.Storage {
+			slots[key] = struct{}{}
+		}
+		for key := range to.Storage {
+			slots[key] = struct{}{}
+		}
+		for key := range slots {
+			fromValue, toValue := from.Storage[key], to.Storage[key]
+			if fromValue == toValue {
+				continue
+			}
+			account.Storage[key] = diffValue(fromValue, toValue, born, died, false)
+			changed = true
+		}
+
+		if changed {
+			diff[addr] = account
+		}
+	}
+	return diff
+}
+
+// stateDiffRecorder records the state diff of a single transaction applied to the statedb.
+// The touched accounts are collected by a tracer and compared against a copy of the pre-transaction state.
+type stateDiffRecorder struct {
+	statedb *state.StateDB
+	pre     *state.StateDB
+	tracer  *stateAccessTracer
+}
+
+// newStateDiffRecorder must be called before the transaction is applied.
+func newStateDiffRecorder(statedb *state.StateDB) *stateDiffRecorder {
+	return &stateDiffRecorder{
+		statedb: statedb,
+		pre:     statedb.Copy(),
+		tracer:  newStateAccessTracer(),
+	}
+}
+
+// configure sets the tracer of the transaction execution.
+func (r *stateDiffRecorder) configure(cfg *vm.Config) {
+	cfg.Debug = true
+	cfg.Tracer = r.tracer
+}
+
+// diff must be called after the transaction is applied and the state is finalised.
+func (r *stateDiffRecorder) diff() StateDiff {
+	return newStateDiff(r.tracer.alloc(r.pre), r.tracer.alloc(r.statedb))
+}
+
+// stateAccessTracer collects the accounts and storage slots which may be changed by a transaction.
+type stateAccessTracer struct {
+	accounts map[common.Address]map[common.Hash]struct{}
+}
+
+func newStateAccessTracer() *stateAccessTracer {
+	return &stateAccessTracer{
+		accounts: make(map[common.Address]map[common.Hash]struct{}),
+	}
+}
+
+func (t *stateAccessTracer) touch(addr common.Address) map[common.Hash]struct{} {
+	slots, ok := t.accounts[addr]
+	if !ok {
+		slots = make(map[common.Hash]struct{})
+		t.accounts[addr] = slots
+	}
+	return slots
+}
+
+// alloc returns the touched accounts and storage slots of the state.
+func (t *stateAccessTracer) alloc(statedb *state.StateDB) substate.SubstateAlloc {
+	alloc := make(substate.SubstateAlloc, len(t.accounts))
+	for addr, slots := range t.accounts {
+		if !statedb.Exist(addr) {
+			continue
+		}
+		acc := &substate.SubstateAccount{
+			Nonce:   statedb.GetNonce(addr),
+			Balance: new(big.Int).Set(statedb.GetBalance(addr)),
+			Code:    common.CopyBytes(statedb.GetCode(addr)),
+			Storage: make(map[common.Hash]common.Hash, len(slots)),
+		}
+		for slot := range slots {
+			acc.Storage[slot] = statedb.GetState(addr, slot)
+		}
+		alloc[addr] = acc
+	}
+	return alloc
+}
+
+func (t *stateAccessTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.touch(from)
+	t.touch(to)
+	// the fee recipient may be paid by the state transition
+	t.touch(env.Context.Coinbase)
+}
+
+func (t *stateAccessTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+	if op != vm.SSTORE {
+		return
+	}
+	stack := scope.Stack.Data()
+	if len(stack) < 1 {
+		return
+	}
+	slot := common.Hash(stack[len(stack)-1].Bytes32())
+	t.touch(scope.Contract.Address())[slot] = struct{}{}
+}
+
+func (t *stateAccessTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	t.touch(from)
+	t.touch(to)
+}
+
+func (t *stateAccessTracer) CaptureExit(output []byte, gasUsed uint64, err error) {}
+
+func (t *stateAccessTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+func (t *stateAccessTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {}
@@ -0,0 +1,127 @@
+package ethapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/substate"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiffValue(t *testing.T) {
+	for name, tc := range map[string]struct {
+		born, died, equal bool
+		expected          string
+	}{
+		"born":    {born: true, expected: `{"+":"0x2"}`},
+		"died":    {died: true, expected: `{"-":"0x1"}`},
+		"equal":   {equal: true, expected: `"="`},
+		"changed": {expected: `{"*":{"from":"0x1","to":"0x2"}}`},
+	} {
+		t.Run(name, func(t *testing.T) {
+			value := diffValue(hexutil.Uint64(1), hexutil.Uint64(2), tc.born, tc.died, tc.equal)
+			out, err := json.Marshal(value)
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(out))
+		})
+	}
+}
+
+func TestEmptyAccount(t *testing.T) {
+	for name, tc := range map[string]struct {
+		acc      *substate.SubstateAccount
+		expected bool
+	}{
+		"nil":           {nil, true},
+		"zero":          {&substate.SubstateAccount{}, true},
+		"zero balance":  {&substate.SubstateAccount{Balance: new(big.Int)}, true},
+		"zero storage":  {&substate.SubstateAccount{Storage: map[common.Hash]common.Hash{{1}: {}}}, true},
+		"nonce":         {&substate.SubstateAccount{Nonce: 1}, false},
+		"balance":       {&substate.SubstateAccount{Balance: big.NewInt(1)}, false},
+		"code":          {&substate.SubstateAccount{Code: []byte{0x1}}, false},
+		"storage value": {&substate.SubstateAccount{Storage: map[common.Hash]common.Hash{{1}: {2}}}, false},
+	} {
+		t.Run(name, func(t *testing.T) {
+			require.Equal(t, tc.expected, emptyAccount(tc.acc))
+		})
+	}
+}
+
+func TestNewStateDiff(t *testing.T) {
+	var (
+		addr = common.Address{0xaa}
+		slot = common.Hash{0x51}
+		one  = common.Hash{31: 1}
+		two  = common.Hash{31: 2}
+		acc  = func(nonce uint64, balance int64, code []byte, storage map[common.Hash]common.Hash) substate.SubstateAlloc {
+			return substate.SubstateAlloc{addr: &substate.SubstateAccount{
+				Nonce:   nonce,
+				Balance: big.NewInt(balance),
+				Code:    code,
+				Storage: storage,
+			}}
+		}
+		expect = func(account string) string {
+			return fmt.Sprintf(`{%q:%s}`, hexutil.Encode(addr[:]), account)
+		}
+	)
+	for name, tc := range map[string]struct {
+		pre, post substate.SubstateAlloc
+		expected  string
+	}{
+		"unchanged": {
+			pre:      acc(1, 10, nil, map[common.Hash]common.Hash{slot: one}),
+			post:     acc(1, 10, nil, map[common.Hash]common.Hash{slot: one}),
+			expected: `{}`,
+		},
+		"empty": {
+			pre:      acc(0, 0, nil, nil),
+			post:     substate.SubstateAlloc{},
+			expected: `{}`,
+		},
+		"born": {
+			pre:  substate.SubstateAlloc{},
+			post: acc(1, 10, []byte{0x60}, map[common.Hash]common.Hash{slot: one}),
+			expected: expect(fmt.Sprintf(`{"balance":{"+":"0xa"},"nonce":{"+":"0x1"},"code":{"+":"0x60"},"storage":{%q:{"+":%q}}}`,
+				slot.Hex(), one.Hex())),
+		},
+		"born from empty": {
+			pre:      acc(0, 0, nil, nil),
+			post:     acc(0, 10, nil, nil),
+			expected: expect(`{"balance":{"+":"0xa"},"nonce":{"+":"0x0"},"code":{"+":"0x"},"storage":{}}`),
+		},
+		"died": {
+			pre:  acc(1, 10, []byte{0x60}, map[common.Hash]common.Hash{slot: one}),
+			post: substate.SubstateAlloc{},
+			expected: expect(fmt.Sprintf(`{"balance":{"-":"0xa"},"nonce":{"-":"0x1"},"code":{"-":"0x60"},"storage":{%q:{"-":%q}}}`,
+				slot.Hex(), one.Hex())),
+		},
+		"changed": {
+			pre:      acc(1, 10, nil, nil),
+			post:     acc(2, 5, nil, nil),
+			expected: expect(`{"balance":{"*":{"from":"0xa","to":"0x5"}},"nonce":{"*":{"from":"0x1","to":"0x2"}},"code":"=","storage":{}}`),
+		},
+		"storage only": {
+			pre:  acc(1, 10, nil, map[common.Hash]common.Hash{slot: one, two: two}),
+			post: acc(1, 10, nil, map[common.Hash]common.Hash{slot: two, two: two}),
+			expected: expect(fmt.Sprintf(`{"balance":"=","nonce":"=","code":"=","storage":{%q:{"*":{"from":%q,"to":%q}}}}`,
+				slot.Hex(), one.Hex(), two.Hex())),
+		},
+		"storage cleared": {
+			pre:  acc(1, 10, nil, map[common.Hash]common.Hash{slot: one}),
+			post: acc(1, 10, nil, nil),
+			expected: expect(fmt.Sprintf(`{"balance":"=","nonce":"=","code":"=","storage":{%q:{"*":{"from":%q,"to":%q}}}}`,
+				slot.Hex(), one.Hex(), common.Hash{}.Hex())),
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			out, err := json.Marshal(newStateDiff(tc.pre, tc.post))
+			require.NoError(t, err)
+			require.JSONEq(t, tc.expected, string(out))
+		})
+	}
+}
@@ -0,0 +1,368 @@
+package ethapi
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+// maxTraceFilterScan is the max number of blocks trace_filter scans if the call traces index can't be used.
+const maxTraceFilterScan = 10000
+
+// PublicTxTraceAPI provides flat call traces of transactions, in the Parity (OpenEthereum) manner.
+// Traces are taken from the call traces index, the blocks which aren't indexed are replayed.
+type PublicTxTraceAPI struct {
+	b Backend
+}
+
+// NewPublicTxTraceAPI creates a new trace API.
+func NewPublicTxTraceAPI(b Backend) *PublicTxTraceAPI {
+	return &PublicTxTraceAPI{b}
+}
+
+// traceAction is the action of a flat trace, set of the fields depends on the action type.
+type traceAction struct {
+	CallType      string          `json:"callType,omitempty"`
+	From          *common.Address `json:"from,omitempty"`
+	To            *common.Address `json:"to,omitempty"`
+	Gas           *hexutil.Uint64 `json:"gas,omitempty"`
+	Input         *hexutil.Bytes  `json:"input,omitempty"`
+	Init          *hexutil.Bytes  `json:"init,omitempty"`
+	Value         *hexutil.Big    `json:"value,omitempty"`
+	Address       *common.Address `json:"address,omitempty"`
+	RefundAddress *common.Address `json:"refundAddress,omitempty"`
+	Balance       *hexutil.Big    `json:"balance,omitempty"`
+}
+
+// traceResult is the result of a flat trace, set of the fields depends on the action type.
+type traceResult struct {
+	GasUsed hexutil.Uint64  `json:"gasUsed"`
+	Output  *hexutil.Bytes  `json:"output,omitempty"`
+	Address *common.Address `json:"address,omitempty"`
+	Code    *hexutil.Bytes  `json:"code,omitempty"`
+}
+
+// RPCActionTrace is a flat trace of a call frame, formatted for RPC.
+type RPCActionTrace struct {
+	Action              traceAction  `json:"action"`
+	BlockHash           common.Hash  `json:"blockHash"`
+	BlockNumber         uint64       `json:"blockNumber"`
+	Error               string       `json:"error,omitempty"`
+	Result              *traceResult `json:"result"`
+	Subtraces           uint32       `json:"subtraces"`
+	TraceAddress        []uint32     `json:"traceAddress"`
+	TransactionHash     common.Hash  `json:"transactionHash"`
+	TransactionPosition uint64       `json:"transactionPosition"`
+	Type                string       `json:"type"`
+}
+
+func newRPCActionTrace(t *txtrace.ActionTrace, block *evmcore.EvmBlock, txIndex int) *RPCActionTrace {
+	res := &RPCActionTrace{
+		BlockHash:           block.Hash,
+		BlockNumber:         block.NumberU64(),
+		Error:               t.Error,
+		Subtraces:           t.Subtraces,
+		TraceAddress:        t.TraceAddress,
+		TransactionHash:     block.Transactions[txIndex].Hash(),
+		TransactionPosition: uint64(txIndex),
+		Type:                t.Type,
+	}
+	if res.TraceAddress == nil {
+		res.TraceAddress = []uint32{}
+	}
+	from, to := t.From, t.To
+	gas := hexutil.Uint64(t.Gas)
+	input, output := hexutil.Bytes(t.Input), hexutil.Bytes(t.Output)
+	value := (*hexutil.Big)(t.Value)
+	switch t.Type {
+	case txtrace.CreateAction:
+		res.Action = traceAction{From: &from, Gas: &gas, Init: &input, Value: value}
+		if t.Error == "" {
+			res.Result = &traceResult{GasUsed: hexutil.Uint64(t.GasUsed), Address: &to, Code: &output}
+		}
+	case txtrace.SuicideAction:
+		res.Action = traceAction{Address: &from, RefundAddress: &to, Balance: value}
+	default:
+		res.Action = traceAction{CallType: t.CallType, From: &from, To: &to, Gas: &gas, Input: &input, Value: value}
+		if t.Error == "" {
+			res.Result = &traceResult{GasUsed: hexutil.Uint64(t.GasUsed), Output: &output}
+		}
+	}
+	return res
+}
+
+// replayCallTraces re-executes the block txs in the [from, to) range and collects their call traces.
+func (api *PublicTxTraceAPI) replayCallTraces(ctx context.Context, block *evmcore.EvmBlock, from, to int) ([][]txtrace.ActionTrace, error) {
+	replay, err := newBlockReplay(ctx, api.b, block)
+	if err != nil {
+		return nil, err
+	}
+	if err := replay.applyUntil(from); err != nil {
+		return nil, err
+	}
+	tracer := txtrace.NewCallTracer()
+	cfg := opera.DefaultVMConfig
+	cfg.Debug = true
+	cfg.Tracer = tracer
+	txs := block.Transactions[from:to]
+	_, skipped, err := replay.process(txs, cfg)
+	if err != nil {
+		return nil, err
+	}
+	if len(skipped) != 0 {
+		return nil, fmt.Errorf("transaction %#x isn't applicable on re-execution", txs[skipped[0]].Hash())
+	}
+	return tracer.Take(), nil
+}
+
+// blockCallTraces returns call traces of all the block txs.
+func (api *PublicTxTraceAPI) blockCallTraces(ctx context.Context, block *evmcore.EvmBlock) ([][]txtrace.ActionTrace, error) {
+	if len(block.Transactions) == 0 {
+		return nil, nil
+	}
+	traces, err := api.b.GetCallTraces(ctx, idx.Block(block.NumberU64()))
+	if err != nil || traces != nil {
+		return traces, err
+	}
+	return api.replayCallTraces(ctx, block, 0, len(block.Transactions))
+}
+
+// Block returns flat call traces of all the txs of the block.
+func (api *PublicTxTraceAPI) Block(ctx context.Context, number rpc.BlockNumber) ([]*RPCActionTrace, error) {
+	block, err := api.b.BlockByNumber(ctx, number)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", number)
+	}
+	traces, err := api.blockCallTraces(ctx, block)
+	if err != nil {
+		return nil, err
+	}
+	res := make([]*RPCActionTrace, 0, len(traces))
+	for i := range traces {
+		for j := range traces[i] {
+			res = append(res, newRPCActionTrace(&traces[i][j], block, i))
+		}
+	}
+	return res, nil
+}
+
+// Transaction returns flat call traces of the transaction.
+func (api *PublicTxTraceAPI) Transaction(ctx context.Context, txHash common.Hash) ([]*RPCActionTrace, error) {
+	tx, blockNumber, index, err := api.b.GetTransaction(ctx, txHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx == nil {
+		return nil, fmt.Errorf("transaction %#x not found", txHash)
+	}
+	block, err := api.b.BlockByNumber(ctx, rpc.BlockNumber(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, fmt.Errorf("block #%d not found", blockNumber)
+	}
+	i := int(index)
+	traces, err := api.b.GetCallTraces(ctx, idx.Block(blockNumber))
+	if err != nil {
+		return nil, err
+	}
+	if traces == nil {
+		// replay only the preceding txs and the tx itself
+		if traces, err = api.replayCallTraces(ctx, block, i, i+1); err != nil {
+			return nil, err
+		}
+		i = 0
+	}
+	if i >= len(traces) {
+		return nil, errors.New("transaction traces are missing")
+	}
+	res := make([]*RPCActionTrace, len(traces[i]))
+	for j := range traces[i] {
+		res[j] = newRPCActionTrace(&traces[i][j], block, int(index))
+	}
+	return res, nil
+}
+
+// TraceFilterArgs are the criteria of trace_filter.
+// Traces match if they're sent from any of FromAddress (if not empty) and
+// sent to any of ToAddress (if not empty).
+type TraceFilterArgs struct {
+	FromBlock   *rpc.BlockNumber `json:"fromBlock"`
+	ToBlock     *rpc.BlockNumber `json:"toBlock"`
+	FromAddress []common.Address `json:"fromAddress"`
+	ToAddress   []common.Address `json:"toAddress"`
+	After       *uint64          `json:"after"`
+	Count       *uint64          `json:"count"`
+}
+
+func (args *TraceFilterArgs) match(t *txtrace.ActionTrace) bool {
+	contains := func(addrs []common.Address, addr common.Address) bool {
+		if len(addrs) == 0 {
+			return true
+		}
+		for _, a := range addrs {
+			if a == addr {
+				return true
+			}
+		}
+		return false
+	}
+	return contains(args.FromAddress, t.From) && contains(args.ToAddress, t.To)
+}
+
+func (api *PublicTxTraceAPI) resolveBlockNumber(ctx context.Context, n *rpc.BlockNumber) (idx.Block, error) {
+	if n != nil && *n >= 0 {
+		return idx.Block(*n), nil
+	}
+	if n != nil && *n == rpc.EarliestBlockNumber {
+		return 0, nil
+	}
+	header, err := api.b.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if err != nil {
+		return 0, err
+	}
+	return idx.Block(header.Number.Uint64()), nil
+}
+
+// indexedPositions returns sorted positions of the txs which traces may match the filter, using the call traces index.
+func (api *PublicTxTraceAPI) indexedPositions(ctx context.Context, args *TraceFilterArgs, from, to idx.Block) ([]txtrace.Position, error) {
+	addrs, recipient := args.FromAddress, false
+	if len(addrs) == 0 {
+		addrs, recipient = args.ToAddress, true
+	}
+	set := make(map[txtrace.Position]bool)
+	for _, addr := range addrs {
+		err := api.b.ForEachCallTracePosition(ctx, addr, recipient, from, to, func(pos txtrace.Position) bool {
+			set[pos] = true
+			return ctx.Err() == nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	positions := make([]txtrace.Position, 0, len(set))
+	for pos := range set {
+		positions = append(positions, pos)
+	}
+	sort.Slice(positions, func(i, j int) bool {
+		a, b := positions[i], positions[j]
+		return a.Block < b.Block || a.Block == b.Block && a.BlockOffset < b.BlockOffset
+	})
+	return positions, nil
+}
+
+// Filter returns flat call traces matching the criteria, ordered by blocks and txs.
+func (api *PublicTxTraceAPI) Filter(ctx context.Context, args TraceFilterArgs) ([]*RPCActionTrace, error) {
+	from, err := api.resolveBlockNumber(ctx, args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.resolveBlockNumber(ctx, args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	if from > to {
+		return nil, errors.New("fromBlock is greater than toBlock")
+	}
+	var after, count uint64
+	if args.After != nil {
+		after = *args.After
+	}
+	if args.Count != nil {
+		count = *args.Count
+	}
+
+	res := make([]*RPCActionTrace, 0)
+	var matched uint64
+	// onTxTraces collects the matching traces, returns false once count is reached
+	onTxTraces := func(block *evmcore.EvmBlock, i int, traces []txtrace.ActionTrace) bool {
+		for j := range traces {
+			if !args.match(&traces[j]) {
+				continue
+			}
+			matched++
+			if matched <= after {
+				continue
+			}
+			res = append(res, newRPCActionTrace(&traces[j], block, i))
+			if count != 0 && uint64(len(res)) >= count {
+				return false
+			}
+		}
+		return true
+	}
+
+	if api.b.CallTracesIndexed() && (len(args.FromAddress) != 0 || len(args.ToAddress) != 0) {
+		positions, err := api.indexedPositions(ctx, &args, from, to)
+		if err != nil {
+			return nil, err
+		}
+		var (
+			block  *evmcore.EvmBlock
+			traces [][]txtrace.ActionTrace
+		)
+		for _, pos := range positions {
+			if block == nil || block.NumberU64() != uint64(pos.Block) {
+				if block, err = api.b.BlockByNumber(ctx, rpc.BlockNumber(pos.Block)); err != nil {
+					return nil, err
+				}
+				if block == nil {
+					return nil, fmt.Errorf("block #%d not found", pos.Block)
+				}
+				if traces, err = api.b.GetCallTraces(ctx, pos.Block); err != nil {
+					return nil, err
+				}
+			}
+			if int(pos.BlockOffset) >= len(traces) || int(pos.BlockOffset) >= len(block.Transactions) {
+				return nil, fmt.Errorf("call traces of block #%d are inconsistent", pos.Block)
+			}
+			if !onTxTraces(block, int(pos.BlockOffset), traces[pos.BlockOffset]) {
+				break
+			}
+		}
+		return res, nil
+	}
+
+	if to-from+1 > maxTraceFilterScan {
+		return nil, fmt.Errorf("too wide blocks range, max %d blocks are allowed without addresses filter", maxTraceFilterScan)
+	}
+	for n := from; n <= to; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := api.b.BlockByNumber(ctx, rpc.BlockNumber(n))
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			break
+		}
+		traces, err := api.blockCallTraces(ctx, block)
+		if err != nil {
+			return nil, err
+		}
+		for i := range traces {
+			if !onTxTraces(block, i, traces[i]) {
+				return res, nil
+			}
+		}
+	}
+	return res, nil
+}
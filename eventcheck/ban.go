@@ -17,6 +17,7 @@ var (
 	ErrUnknownEpochBVs       = heavycheck.ErrUnknownEpochBVs
 	ErrUnknownEpochEV        = heavycheck.ErrUnknownEpochEV
 	ErrUndecidedBR           = errors.New("BR is unprocessable yet")
+	ErrRejectedBR            = errors.New("BR is rejected by local config")
 	ErrUndecidedER           = errors.New("ER is unprocessable yet")
 	ErrAlreadyConnectedEvent = base.ErrAlreadyConnectedEvent
 	ErrSpilledEvent          = base.ErrSpilledEvent
@@ -32,6 +33,7 @@ func IsBan(err error) bool {
 		err == ErrAlreadyProcessedER ||
 		err == ErrUnknownEpochBVs ||
 		err == ErrUndecidedBR ||
+		err == ErrRejectedBR ||
 		err == ErrUnknownEpochEV ||
 		err == ErrUndecidedER ||
 		err == ErrSpilledEvent ||
@@ -0,0 +1,269 @@
+package evmcore
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// CallProfile is a profiling record of a single transaction execution,
+// either a message call or a contract creation.
+type CallProfile struct {
+	Block     uint64
+	TxIndex   int
+	TxHash    common.Hash
+	Sender    common.Address
+	To        *common.Address // nil for contract creations
+	InputSize int
+	Selector  [4]byte // first 4 bytes of the input, meaningful only if InputSize >= 4
+	GasUsed   uint64
+	Status    uint64
+	Duration  time.Duration
+}
+
+// CallProfiler is a sink of EVM call profiling records.
+type CallProfiler interface {
+	Record(p *CallProfile)
+	Close() error
+}
+
+// EVMCallProfiler collects profiling records of executed transactions, profiling is disabled if nil.
+var EVMCallProfiler CallProfiler
+
+type callProfilers []CallProfiler
+
+// NewMultiCallProfiler returns a profiler which passes records to all the profilers.
+func NewMultiCallProfiler(profilers ...CallProfiler) CallProfiler {
+	return callProfilers(profilers)
+}
+
+func (pp callProfilers) Record(r *CallProfile) {
+	for _, p := range pp {
+		p.Record(r)
+	}
+}
+
+func (pp callProfilers) Close() error {
+	var err error
+	for _, p := range pp {
+		if e := p.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+
+// csvCallProfiler writes profiling records as CSV rows.
+type csvCallProfiler struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	closer io.Closer
+	err    error
+}
+
+var csvCallProfileHeader = []string{"block", "tx", "hash", "sender", "to", "create", "input_size", "selector", "gas_used", "status", "nanos"}
+
+// NewCSVCallProfiler returns a profiler which writes records into w as CSV.
+// The closer (if not nil) is closed when the profiler gets closed.
+func NewCSVCallProfiler(w io.Writer, closer io.Closer) CallProfiler {
+	p := &csvCallProfiler{
+		w:      csv.NewWriter(w),
+		closer: closer,
+	}
+	p.err = p.w.Write(csvCallProfileHeader)
+	return p
+}
+
+func (p *csvCallProfiler) Record(r *CallProfile) {
+	to := ""
+	if r.To != nil {
+		to = r.To.Hex()
+	}
+	selector := ""
+	if r.InputSize >= 4 {
+		selector = hexutil.Encode(r.Selector[:])
+	}
+	row := []string{
+		strconv.FormatUint(r.Block, 10),
+		strconv.Itoa(r.TxIndex),
+		r.TxHash.Hex(),
+		r.Sender.Hex(),
+		to,
+		strconv.FormatBool(r.To == nil),
+		strconv.Itoa(r.InputSize),
+		selector,
+		strconv.FormatUint(r.GasUsed, 10),
+		strconv.FormatUint(r.Status, 10),
+		strconv.FormatInt(r.Duration.Nanoseconds(), 10),
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = p.w.Write(row)
+	}
+}
+
+func (p *csvCallProfiler) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.w.Flush()
+	if p.err == nil {
+		p.err = p.w.Error()
+	}
+	if p.closer != nil {
+		if err := p.closer.Close(); err != nil && p.err == nil {
+			p.err = err
+		}
+	}
+	return p.err
+}
+
+// sqlCallProfiler writes profiling records into the evm_calls table of an SQL DB (e.g. SQLite).
+// Records are inserted in batches, each batch within a single DB transaction.
+type sqlCallProfiler struct {
+	mu    sync.Mutex
+	db    *sql.DB
+	batch []*CallProfile
+	err   error
+}
+
+const sqlCallProfileBatch = 1000
+
+const sqlCallProfileSchema = `CREATE TABLE IF NOT EXISTS evm_calls (
+	block INTEGER NOT NULL,
+	tx INTEGER NOT NULL,
+	hash TEXT NOT NULL,
+	sender TEXT NOT NULL,
+	recipient TEXT,
+	is_create BOOLEAN NOT NULL,
+	input_size INTEGER NOT NULL,
+	selector TEXT,
+	gas_used INTEGER NOT NULL,
+	status INTEGER NOT NULL,
+	nanos INTEGER NOT NULL
+)`
+
+const sqlCallProfileInsert = `INSERT INTO evm_calls
+	(block, tx, hash, sender, recipient, is_create, input_size, selector, gas_used, status, nanos)
+	VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`
+
+// NewSQLCallProfiler returns a profiler which inserts records into the evm_calls table of db,
+// creating the table if it doesn't exist. The db is closed when the profiler gets closed.
+func NewSQLCallProfiler(db *sql.DB) (CallProfiler, error) {
+	if _, err := db.Exec(sqlCallProfileSchema); err != nil {
+		return nil, err
+	}
+	return &sqlCallProfiler{
+		db:    db,
+		batch: make([]*CallProfile, 0, sqlCallProfileBatch),
+	}, nil
+}
+
+func (p *sqlCallProfiler) Record(r *CallProfile) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err != nil {
+		return
+	}
+	p.batch = append(p.batch, r)
+	if len(p.batch) >= sqlCallProfileBatch {
+		p.err = p.flush()
+	}
+}
+
+// flush inserts the batched records, must be called under the lock.
+func (p *sqlCallProfiler) flush() error {
+	if len(p.batch) == 0 {
+		return nil
+	}
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	stmt, err := tx.Prepare(sqlCallProfileInsert)
+	if err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	for _, r := range p.batch {
+		var to, selector interface{}
+		if r.To != nil {
+			to = r.To.Hex()
+		}
+		if r.InputSize >= 4 {
+			selector = hexutil.Encode(r.Selector[:])
+		}
+		_, err = stmt.Exec(r.Block, r.TxIndex, r.TxHash.Hex(), r.Sender.Hex(), to, r.To == nil,
+			r.InputSize, selector, int64(r.GasUsed), int64(r.Status), r.Duration.Nanoseconds())
+		if err != nil {
+			_ = stmt.Close()
+			_ = tx.Rollback()
+			return err
+		}
+	}
+	if err := stmt.Close(); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	p.batch = p.batch[:0]
+	return tx.Commit()
+}
+
+func (p *sqlCallProfiler) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.err == nil {
+		p.err = p.flush()
+	}
+	if err := p.db.Close(); err != nil && p.err == nil {
+		p.err = err
+	}
+	return p.err
+}
+
+// metricsCallProfiler aggregates records into the metrics registry,
+// which are exported by the metrics endpoints (e.g. Prometheus).
+type metricsCallProfiler struct {
+	callTime   metrics.Timer
+	createTime metrics.Timer
+	callGas    metrics.Histogram
+	createGas  metrics.Histogram
+	failed     metrics.Counter
+}
+
+// NewMetricsCallProfiler returns a profiler which aggregates records into execution time and gas histograms.
+// Note: metrics have to be enabled to get the records collected.
+func NewMetricsCallProfiler() CallProfiler {
+	return &metricsCallProfiler{
+		callTime:   metrics.GetOrRegisterTimer("evm/call/time", nil),
+		createTime: metrics.GetOrRegisterTimer("evm/create/time", nil),
+		callGas:    metrics.GetOrRegisterHistogram("evm/call/gas", nil, metrics.NewExpDecaySample(1028, 0.015)),
+		createGas:  metrics.GetOrRegisterHistogram("evm/create/gas", nil, metrics.NewExpDecaySample(1028, 0.015)),
+		failed:     metrics.GetOrRegisterCounter("evm/failed", nil),
+	}
+}
+
+func (p *metricsCallProfiler) Record(r *CallProfile) {
+	if r.To == nil {
+		p.createTime.Update(r.Duration)
+		p.createGas.Update(int64(r.GasUsed))
+	} else {
+		p.callTime.Update(r.Duration)
+		p.callGas.Update(int64(r.GasUsed))
+	}
+	if r.Status == 0 {
+		p.failed.Inc(1)
+	}
+}
+
+func (p *metricsCallProfiler) Close() error {
+	return nil
+}
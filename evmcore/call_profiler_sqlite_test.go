@@ -0,0 +1,82 @@
+//go:build sqlite
+// +build sqlite
+
+package evmcore
+
+import (
+	"database/sql"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	_ "github.com/mattn/go-sqlite3"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSQLCallProfiler(t *testing.T) {
+	require := require.New(t)
+
+	dir, err := ioutil.TempDir("", "call-profiler")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+	fn := path.Join(dir, "calls.db")
+
+	db, err := sql.Open("sqlite3", fn)
+	require.NoError(err)
+	p, err := NewSQLCallProfiler(db)
+	require.NoError(err)
+	to := common.HexToAddress("0x2")
+	p.Record(&CallProfile{
+		Block:     10,
+		TxIndex:   3,
+		Sender:    common.HexToAddress("0x1"),
+		To:        &to,
+		InputSize: 68,
+		Selector:  [4]byte{0xa9, 0x05, 0x9c, 0xbb},
+		GasUsed:   21000,
+		Status:    1,
+		Duration:  time.Microsecond,
+	})
+	// more than a batch of records
+	for i := 0; i < sqlCallProfileBatch; i++ {
+		p.Record(&CallProfile{
+			Block:   11,
+			TxIndex: i,
+			GasUsed: 53000,
+		})
+	}
+	require.NoError(p.Close())
+
+	db, err = sql.Open("sqlite3", fn)
+	require.NoError(err)
+	defer db.Close()
+
+	var count int
+	require.NoError(db.QueryRow("SELECT COUNT(*) FROM evm_calls").Scan(&count))
+	require.Equal(sqlCallProfileBatch+1, count)
+
+	var (
+		block, tx, inputSize, gasUsed, status, nanos int64
+		hash, sender                                 string
+		recipient, selector                          sql.NullString
+		isCreate                                     bool
+	)
+	require.NoError(db.QueryRow("SELECT * FROM evm_calls WHERE block = 10").Scan(
+		&block, &tx, &hash, &sender, &recipient, &isCreate, &inputSize, &selector, &gasUsed, &status, &nanos))
+	require.Equal(int64(3), tx)
+	require.Equal(common.HexToAddress("0x1").Hex(), sender)
+	require.Equal(to.Hex(), recipient.String)
+	require.False(isCreate)
+	require.Equal("0xa9059cbb", selector.String)
+	require.Equal(int64(21000), gasUsed)
+	require.Equal(int64(1000), nanos)
+
+	require.NoError(db.QueryRow("SELECT * FROM evm_calls WHERE block = 11 AND tx = 0").Scan(
+		&block, &tx, &hash, &sender, &recipient, &isCreate, &inputSize, &selector, &gasUsed, &status, &nanos))
+	require.False(recipient.Valid)
+	require.True(isCreate)
+	require.False(selector.Valid)
+}
@@ -0,0 +1,45 @@
+package evmcore
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCSVCallProfiler(t *testing.T) {
+	require := require.New(t)
+
+	var buf bytes.Buffer
+	p := NewCSVCallProfiler(&buf, nil)
+	to := common.HexToAddress("0x2")
+	p.Record(&CallProfile{
+		Block:     10,
+		TxIndex:   3,
+		Sender:    common.HexToAddress("0x1"),
+		To:        &to,
+		InputSize: 68,
+		Selector:  [4]byte{0xa9, 0x05, 0x9c, 0xbb},
+		GasUsed:   21000,
+		Status:    1,
+		Duration:  time.Microsecond,
+	})
+	p.Record(&CallProfile{
+		Block:   10,
+		TxIndex: 4,
+		GasUsed: 53000,
+	})
+	require.NoError(p.Close())
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(err)
+	require.Len(rows, 3)
+	require.Equal(csvCallProfileHeader, rows[0])
+	require.Equal([]string{"10", "3", common.Hash{}.Hex(), common.HexToAddress("0x1").Hex(), to.Hex(), "false", "68", "0xa9059cbb", "21000", "1", "1000"}, rows[1])
+	require.Equal("", rows[2][4])
+	require.Equal("true", rows[2][5])
+	require.Equal("", rows[2][7])
+}
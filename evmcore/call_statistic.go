@@ -0,0 +1,220 @@
+package evmcore
+
+import (
+	"encoding/csv"
+	"io"
+	"math/rand"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// callStatSampleSize is a size of the reservoir used to estimate percentiles of a group.
+const callStatSampleSize = 256
+
+// callStatValues accumulates values of a group, using a reservoir sample for percentiles.
+type callStatValues struct {
+	count  uint64
+	total  uint64
+	max    uint64
+	sample []uint64
+}
+
+func (v *callStatValues) add(x uint64, rnd *rand.Rand) {
+	v.count++
+	v.total += x
+	if x > v.max {
+		v.max = x
+	}
+	if len(v.sample) < callStatSampleSize {
+		v.sample = append(v.sample, x)
+	} else if i := rnd.Int63n(int64(v.count)); i < callStatSampleSize {
+		v.sample[i] = x
+	}
+}
+
+func (v *callStatValues) mean() uint64 {
+	if v.count == 0 {
+		return 0
+	}
+	return v.total / v.count
+}
+
+// percentiles estimates the percentiles (in range [0, 1]) of the group values.
+func (v *callStatValues) percentiles(ps ...float64) []uint64 {
+	res := make([]uint64, len(ps))
+	if len(v.sample) == 0 {
+		return res
+	}
+	sorted := make([]uint64, len(v.sample))
+	copy(sorted, v.sample)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	for i, p := range ps {
+		res[i] = sorted[int(p*float64(len(sorted)-1)+0.5)]
+	}
+	return res
+}
+
+// CallStatGroup is an aggregated statistic of a group of EVM calls.
+type CallStatGroup struct {
+	Kind string // "contract" or "selector"
+	Key  string
+	time callStatValues
+	gas  callStatValues
+}
+
+func newCallStatGroup(kind, key string) *CallStatGroup {
+	return &CallStatGroup{
+		Kind: kind,
+		Key:  key,
+	}
+}
+
+// Count returns number of calls in the group.
+func (g *CallStatGroup) Count() uint64 {
+	return g.time.count
+}
+
+// TotalTime returns the total execution time of the group.
+func (g *CallStatGroup) TotalTime() time.Duration {
+	return time.Duration(g.time.total)
+}
+
+// TotalGas returns the total gas used by the group.
+func (g *CallStatGroup) TotalGas() uint64 {
+	return g.gas.total
+}
+
+func (g *CallStatGroup) add(r *CallProfile, rnd *rand.Rand) {
+	g.time.add(uint64(r.Duration), rnd)
+	g.gas.add(r.GasUsed, rnd)
+}
+
+// CallStatistic aggregates EVM call profiling records by target contract and by function selector.
+// Percentiles are estimated with a bounded sample, while counts, totals and maximums are exact.
+type CallStatistic struct {
+	mu        sync.Mutex
+	contracts map[common.Address]*CallStatGroup
+	selectors map[[4]byte]*CallStatGroup
+	creates   *CallStatGroup
+	rnd       *rand.Rand
+}
+
+// NewCallStatistic returns an empty CallStatistic, it may be used as a CallProfiler.
+func NewCallStatistic() *CallStatistic {
+	return &CallStatistic{
+		contracts: make(map[common.Address]*CallStatGroup),
+		selectors: make(map[[4]byte]*CallStatGroup),
+		creates:   newCallStatGroup("contract", "create"),
+		rnd:       rand.New(rand.NewSource(0)),
+	}
+}
+
+func (s *CallStatistic) Record(r *CallProfile) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	contract := s.creates
+	if r.To != nil {
+		contract = s.contracts[*r.To]
+		if contract == nil {
+			contract = newCallStatGroup("contract", r.To.Hex())
+			s.contracts[*r.To] = contract
+		}
+	}
+	contract.add(r, s.rnd)
+
+	// selectors are meaningful only for calls with ABI-encoded input
+	if r.To == nil || r.InputSize < 4 {
+		return
+	}
+	selector := s.selectors[r.Selector]
+	if selector == nil {
+		selector = newCallStatGroup("selector", hexutil.Encode(r.Selector[:]))
+		s.selectors[r.Selector] = selector
+	}
+	selector.add(r, s.rnd)
+}
+
+func (s *CallStatistic) Close() error {
+	return nil
+}
+
+// Groups returns all the aggregated groups, ordered by the total execution time descending.
+func (s *CallStatistic) Groups() []*CallStatGroup {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.groups()
+}
+
+func (s *CallStatistic) groups() []*CallStatGroup {
+	groups := make([]*CallStatGroup, 0, len(s.contracts)+len(s.selectors)+1)
+	if s.creates.Count() != 0 {
+		groups = append(groups, s.creates)
+	}
+	for _, g := range s.contracts {
+		groups = append(groups, g)
+	}
+	for _, g := range s.selectors {
+		groups = append(groups, g)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		a, b := groups[i].time.total, groups[j].time.total
+		if a != b {
+			return a > b
+		}
+		if groups[i].Kind != groups[j].Kind {
+			return groups[i].Kind < groups[j].Kind
+		}
+		return groups[i].Key < groups[j].Key
+	})
+	return groups
+}
+
+var callStatisticHeader = []string{"kind", "key", "count",
+	"nanos_total", "nanos_mean", "nanos_p50", "nanos_p99", "nanos_max",
+	"gas_total", "gas_mean", "gas_p50", "gas_p99", "gas_max"}
+
+// Dump writes the aggregated groups into w as CSV, preceded by a comment line with the version.
+func (s *CallStatistic) Dump(w io.Writer, version string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, err := io.WriteString(w, "# "+version+"\n"); err != nil {
+		return err
+	}
+	cw := csv.NewWriter(w)
+	if err := cw.Write(callStatisticHeader); err != nil {
+		return err
+	}
+	for _, g := range s.groups() {
+		tp := g.time.percentiles(0.5, 0.99)
+		gp := g.gas.percentiles(0.5, 0.99)
+		row := []string{
+			g.Kind,
+			g.Key,
+			strconv.FormatUint(g.Count(), 10),
+			strconv.FormatUint(g.time.total, 10),
+			strconv.FormatUint(g.time.mean(), 10),
+			strconv.FormatUint(tp[0], 10),
+			strconv.FormatUint(tp[1], 10),
+			strconv.FormatUint(g.time.max, 10),
+			strconv.FormatUint(g.gas.total, 10),
+			strconv.FormatUint(g.gas.mean(), 10),
+			strconv.FormatUint(gp[0], 10),
+			strconv.FormatUint(gp[1], 10),
+			strconv.FormatUint(g.gas.max, 10),
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
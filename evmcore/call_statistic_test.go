@@ -0,0 +1,72 @@
+package evmcore
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallStatistic(t *testing.T) {
+	require := require.New(t)
+
+	s := NewCallStatistic()
+	a := common.HexToAddress("0xa")
+	b := common.HexToAddress("0xb")
+	transfer := [4]byte{0xa9, 0x05, 0x9c, 0xbb}
+	for i := 1; i <= 100; i++ {
+		s.Record(&CallProfile{
+			To:        &a,
+			InputSize: 68,
+			Selector:  transfer,
+			GasUsed:   uint64(i * 1000),
+			Duration:  time.Duration(i) * time.Millisecond,
+		})
+	}
+	s.Record(&CallProfile{
+		To:       &b,
+		GasUsed:  21000,
+		Duration: time.Millisecond,
+	})
+	s.Record(&CallProfile{
+		InputSize: 100,
+		GasUsed:   500000,
+		Duration:  time.Second,
+	})
+
+	groups := s.Groups()
+	require.Len(groups, 4)
+	// ordered by total time, then by kind
+	require.Equal("contract", groups[0].Kind)
+	require.Equal(a.Hex(), groups[0].Key)
+	require.Equal("selector", groups[1].Kind)
+	require.Equal("0xa9059cbb", groups[1].Key)
+	require.Equal("create", groups[2].Key)
+	require.Equal(b.Hex(), groups[3].Key)
+
+	g := groups[0]
+	require.Equal(uint64(100), g.Count())
+	require.Equal(5050*time.Millisecond, g.TotalTime())
+	require.Equal(uint64(5050000), g.TotalGas())
+	require.Equal(uint64(50500), g.gas.mean())
+	require.Equal(uint64(100000), g.gas.max)
+	p := g.gas.percentiles(0, 0.5, 0.99, 1)
+	require.Equal([]uint64{1000, 51000, 99000, 100000}, p)
+
+	var buf bytes.Buffer
+	require.NoError(s.Dump(&buf, "test"))
+	require.True(strings.HasPrefix(buf.String(), "# test\n"))
+	r := csv.NewReader(&buf)
+	r.Comment = '#'
+	rows, err := r.ReadAll()
+	require.NoError(err)
+	require.Len(rows, 5)
+	require.Equal(callStatisticHeader, rows[0])
+	require.Equal([]string{"contract", a.Hex(), "100",
+		"5050000000", "50500000", "51000000", "99000000", "100000000",
+		"5050000", "50500", "51000", "99000", "100000"}, rows[1])
+}
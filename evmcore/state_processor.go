@@ -28,17 +28,24 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 	"github.com/ethereum/go-ethereum/substate"
 
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/utils/signers/gsignercache"
 	"github.com/Fantom-foundation/go-opera/utils/signers/internaltx"
 )
 
+// putSubstate writes a recorded substate into the substate DB, replaced in tests.
+var putSubstate = substate.PutSubstate
+
 // StateProcessor is a basic Processor, which takes care of transitioning
 // state from one point to another.
 //
 // StateProcessor implements Processor.
 type StateProcessor struct {
-	config *params.ChainConfig // Chain configuration options
-	bc     DummyChain          // Canonical block chain
+	config   *params.ChainConfig  // Chain configuration options
+	bc       DummyChain           // Canonical block chain
+	txOffset int                  // Index of the first processed transaction within the block
+	noRecord bool                 // Disables substates recording and calls profiling
+	blockCtx *iblockproc.BlockCtx // Opera context of the processed block, required for substates recording
 }
 
 // NewStateProcessor initialises a new StateProcessor.
@@ -49,6 +56,27 @@ func NewStateProcessor(config *params.ChainConfig, bc DummyChain) *StateProcesso
 	}
 }
 
+// WithTxOffset sets the index of the first processed transaction within the block,
+// for blocks which are processed by multiple calls of Process.
+func (p *StateProcessor) WithTxOffset(offset uint) *StateProcessor {
+	p.txOffset = int(offset)
+	return p
+}
+
+// WithBlockCtx sets the Opera context of the processed block.
+// Substates are recorded only for blocks with a known context.
+func (p *StateProcessor) WithBlockCtx(block iblockproc.BlockCtx) *StateProcessor {
+	p.blockCtx = &block
+	return p
+}
+
+// WithoutRecording disables substates recording and calls profiling,
+// for re-executions of already processed blocks (e.g. tracing).
+func (p *StateProcessor) WithoutRecording() *StateProcessor {
+	p.noRecord = true
+	return p
+}
+
 // Process processes the state changes according to the Ethereum rules by running
 // the transaction messages using the statedb and applying any rewards to both
 // the processor (coinbase) and any included uncles.
@@ -65,6 +93,7 @@ func (p *StateProcessor) Process(
 	var (
 		gp           = new(GasPool).AddGas(block.GasLimit)
 		receipt      *types.Receipt
+		result       *ExecutionResult
 		skip         bool
 		header       = block.Header()
 		blockContext = NewEVMBlockContext(header, p.bc, nil)
@@ -72,6 +101,8 @@ func (p *StateProcessor) Process(
 		blockHash    = block.Hash
 		blockNumber  = block.Number
 		signer       = gsignercache.Wrap(types.MakeSigner(p.config, header.Number))
+		record       = !p.noRecord && p.blockCtx != nil && substate.RecordReplay &&
+			recordingFilter.recordBlock(uint64(p.blockCtx.Idx), p.blockCtx.Atropos.Epoch())
 	)
 	// Iterate over and process the individual transactions
 	for i, tx := range block.Transactions {
@@ -81,16 +112,19 @@ func (p *StateProcessor) Process(
 		}
 
 		statedb.Prepare(tx.Hash(), i)
-		receipt, _, skip, err = applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv, onNewLog)
+		receipt, result, skip, err = applyTransaction(msg, p.config, gp, statedb, blockNumber, blockHash, tx, usedGas, vmenv, onNewLog)
 		if skip {
 			skipped = append(skipped, uint32(i))
+			if record && recordingFilter.recordMsg(msg) {
+				p.recordSubstateMeta(block, i, tx, err)
+			}
 			err = nil
 			continue
 		}
 		if err != nil {
 			return nil, nil, nil, fmt.Errorf("could not apply tx %d [%v]: %w", i, tx.Hash().Hex(), err)
 		}
-		if substate.RecordReplay {
+		if record && recordingFilter.recordAlloc(statedb.SubstatePreAlloc, statedb.SubstatePostAlloc) {
 			// save tx substate into DBs, merge block hashes to env
 			etherBlock := block.RecordingEthBlock()
 			recording := substate.NewSubstate(
@@ -100,7 +134,23 @@ func (p *StateProcessor) Process(
 				substate.NewSubstateMessage(&msg),
 				substate.NewSubstateResult(receipt),
 			)
-			substate.PutSubstate(block.NumberU64(), i, recording)
+			putSubstate(block.NumberU64(), p.txOffset+i, recording)
+			p.recordSubstateMeta(block, i, tx, nil)
+		}
+		if EVMCallProfiler != nil && !p.noRecord {
+			profile := &CallProfile{
+				Block:     block.NumberU64(),
+				TxIndex:   p.txOffset + i,
+				TxHash:    tx.Hash(),
+				Sender:    msg.From(),
+				To:        msg.To(),
+				InputSize: len(msg.Data()),
+				GasUsed:   receipt.GasUsed,
+				Status:    receipt.Status,
+				Duration:  result.Elapsed,
+			}
+			copy(profile.Selector[:], msg.Data())
+			EVMCallProfiler.Record(profile)
 		}
 		receipts = append(receipts, receipt)
 		allLogs = append(allLogs, receipt.Logs...)
@@ -121,7 +171,7 @@ func applyTransaction(
 	onNewLog func(*types.Log, *state.StateDB),
 ) (
 	*types.Receipt,
-	uint64,
+	*ExecutionResult,
 	bool,
 	error,
 ) {
@@ -132,7 +182,7 @@ func applyTransaction(
 	// Apply the transaction to the current state (included in the env).
 	result, err := ApplyMessage(evm, msg, gp)
 	if err != nil {
-		return nil, 0, result == nil, err
+		return nil, nil, result == nil, err
 	}
 	// Notify about logs with potential state changes
 	logs := statedb.GetLogs(tx.Hash(), blockHash)
@@ -171,7 +221,7 @@ func applyTransaction(
 	receipt.BlockHash = blockHash
 	receipt.BlockNumber = blockNumber
 	receipt.TransactionIndex = uint(statedb.TxIndex())
-	return receipt, result.UsedGas, false, err
+	return receipt, result, false, err
 }
 
 func TxAsMessage(tx *types.Transaction, signer types.Signer, baseFee *big.Int) (types.Message, error) {
@@ -0,0 +1,92 @@
+package evmcore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/substate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+)
+
+func TestStateProcessorRecording(t *testing.T) {
+	require := require.New(t)
+
+	// record substates into memory
+	recorded := []int{}
+	putSubstate = func(block uint64, tx int, _ *substate.Substate) {
+		require.Equal(uint64(1), block)
+		recorded = append(recorded, tx)
+	}
+	defer func() {
+		putSubstate = substate.PutSubstate
+	}()
+	substate.RecordReplay = true
+	defer func() {
+		substate.RecordReplay = false
+	}()
+	substateMetaDB = memorydb.New()
+	defer CloseSubstateMetaDB()
+
+	key, _ := crypto.GenerateKey()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.SetBalance(crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1000000000))
+
+	blockCtx := iblockproc.BlockCtx{
+		Idx:     1,
+		Atropos: hash.FakeEvent(),
+	}
+	block := NewEvmBlock(&EvmHeader{
+		Number:   big.NewInt(1),
+		Hash:     common.Hash(blockCtx.Atropos),
+		GasLimit: 1000000,
+		BaseFee:  big.NewInt(0),
+	}, types.Transactions{
+		transaction(0, 21000, key),
+		transaction(1, 21000, key),
+		// skipped because of the nonce
+		transaction(0, 21000, key),
+	})
+
+	// nothing is recorded without the block context
+	var usedGas uint64
+	_, _, _, err := NewStateProcessor(params.TestChainConfig, nil).Process(block, statedb.Copy(), vm.Config{}, &usedGas, nil)
+	require.NoError(err)
+	require.Empty(recorded)
+	require.Nil(GetSubstateMeta(1, 0))
+
+	// the block is processed after 3 transactions of the same block
+	usedGas = 0
+	_, _, skipped, err := NewStateProcessor(params.TestChainConfig, nil).WithBlockCtx(blockCtx).WithTxOffset(3).Process(block, statedb, vm.Config{}, &usedGas, nil)
+	require.NoError(err)
+	require.Equal([]uint32{2}, skipped)
+
+	// substates and metadata are indexed by the position within the whole block
+	require.Equal([]int{3, 4}, recorded)
+	require.Nil(GetSubstateMeta(1, 2))
+	for i, tx := range block.Transactions {
+		meta := GetSubstateMeta(1, 3+i)
+		require.NotNil(meta)
+		require.Equal(tx.Hash(), meta.TxHash)
+		require.Equal(blockCtx.Atropos, meta.Atropos)
+		require.Equal(blockCtx.Atropos.Epoch(), meta.Epoch)
+		require.False(meta.Internal)
+	}
+	require.False(GetSubstateMeta(1, 3).Skipped)
+	require.True(GetSubstateMeta(1, 5).Skipped)
+	require.Contains(GetSubstateMeta(1, 5).SkipReason, ErrNonceTooLow.Error())
+	// the chain config is recorded for the epoch
+	config := GetSubstateChainConfig(blockCtx.Atropos.Epoch())
+	require.NotNil(config)
+	require.Equal(params.TestChainConfig.String(), config.String())
+}
@@ -30,9 +30,6 @@ import (
 	"github.com/ethereum/go-ethereum/params"
 )
 
-// Profile EVM Call 
-var ProfileEVMCall bool
-
 var emptyCodeHash = crypto.Keccak256Hash(nil)
 
 /*
@@ -84,9 +81,10 @@ type Message interface {
 // ExecutionResult includes all output after executing given evm
 // message no matter the execution itself is successful or not.
 type ExecutionResult struct {
-	UsedGas    uint64 // Total used gas but include the refunded gas
-	Err        error  // Any error encountered during the execution(listed in core/vm/errors.go)
-	ReturnData []byte // Returned data from evm(function result or data supplied with revert opcode)
+	UsedGas    uint64        // Total used gas but include the refunded gas
+	Err        error         // Any error encountered during the execution(listed in core/vm/errors.go)
+	ReturnData []byte        // Returned data from evm(function result or data supplied with revert opcode)
+	Elapsed    time.Duration // Time of the EVM execution, measured only if EVMCallProfiler is set
 }
 
 // Unwrap returns the internal evm error which allows us for further
@@ -288,20 +286,20 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		ret   []byte
 		vmerr error // vm errors do not effect consensus and are therefore not assigned to err
 	)
+	var start time.Time
+	if EVMCallProfiler != nil {
+		start = time.Now()
+	}
 	if contractCreation {
 		ret, _, st.gas, vmerr = st.evm.Create(sender, st.data, st.gas, st.value)
 	} else {
-		var start time.Time
 		// Increment the nonce for the next transaction
 		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
-		if ProfileEVMCall {
-			start = time.Now()
-		}
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
-		if ProfileEVMCall {
-			elapsed := time.Since(start)
-			fmt.Printf("call: %v,%v,%v,%v\n", st.evm.Context.BlockNumber, st.msg.Nonce(), st.to(), elapsed.Nanoseconds())
-		}
+	}
+	var elapsed time.Duration
+	if EVMCallProfiler != nil {
+		elapsed = time.Since(start)
 	}
 	// use 10% of not used gas
 	if !st.internal() {
@@ -320,6 +318,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		UsedGas:    st.gasUsed(),
 		Err:        vmerr,
 		ReturnData: ret,
+		Elapsed:    elapsed,
 	}, nil
 }
 
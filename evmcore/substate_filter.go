@@ -0,0 +1,83 @@
+package evmcore
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/substate"
+)
+
+// SubstateFilter limits which transactions get recorded into the substate DB.
+type SubstateFilter struct {
+	FromBlock uint64
+	ToBlock   uint64 // zero means no upper limit
+	FromEpoch idx.Epoch
+	ToEpoch   idx.Epoch // zero means no upper limit
+	// Addresses limits recording to transactions which touch any of the accounts, no limit if empty
+	Addresses []common.Address
+}
+
+type substateFilter struct {
+	SubstateFilter
+	addresses map[common.Address]struct{}
+}
+
+// recordingFilter is the filter of recorded transactions, records everything by default.
+var recordingFilter = newSubstateFilter(SubstateFilter{})
+
+func newSubstateFilter(cfg SubstateFilter) *substateFilter {
+	f := &substateFilter{
+		SubstateFilter: cfg,
+		addresses:      make(map[common.Address]struct{}, len(cfg.Addresses)),
+	}
+	for _, addr := range cfg.Addresses {
+		f.addresses[addr] = struct{}{}
+	}
+	return f
+}
+
+// SetSubstateFilter sets the filter of transactions recorded into the substate DB.
+func SetSubstateFilter(cfg SubstateFilter) {
+	recordingFilter = newSubstateFilter(cfg)
+}
+
+func (f *substateFilter) recordBlock(block uint64, epoch idx.Epoch) bool {
+	if block < f.FromBlock || f.ToBlock != 0 && block > f.ToBlock {
+		return false
+	}
+	if epoch < f.FromEpoch || f.ToEpoch != 0 && epoch > f.ToEpoch {
+		return false
+	}
+	return true
+}
+
+// recordMsg checks the addresses filter against sender and recipient of the message.
+func (f *substateFilter) recordMsg(msg types.Message) bool {
+	if len(f.addresses) == 0 {
+		return true
+	}
+	if _, ok := f.addresses[msg.From()]; ok {
+		return true
+	}
+	if msg.To() != nil {
+		if _, ok := f.addresses[*msg.To()]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// recordAlloc checks the addresses filter against all the accounts accessed by a transaction.
+func (f *substateFilter) recordAlloc(allocs ...substate.SubstateAlloc) bool {
+	if len(f.addresses) == 0 {
+		return true
+	}
+	for _, alloc := range allocs {
+		for addr := range alloc {
+			if _, ok := f.addresses[addr]; ok {
+				return true
+			}
+		}
+	}
+	return false
+}
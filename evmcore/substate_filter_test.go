@@ -0,0 +1,42 @@
+package evmcore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstateFilter(t *testing.T) {
+	require := require.New(t)
+
+	f := newSubstateFilter(SubstateFilter{})
+	require.True(f.recordBlock(0, 0))
+	require.True(f.recordBlock(1000, 10))
+
+	f = newSubstateFilter(SubstateFilter{
+		FromBlock: 10,
+		ToBlock:   20,
+		FromEpoch: 2,
+	})
+	require.False(f.recordBlock(9, 2))
+	require.True(f.recordBlock(10, 2))
+	require.True(f.recordBlock(20, 100))
+	require.False(f.recordBlock(21, 100))
+	require.False(f.recordBlock(15, 1))
+
+	watched := common.Address{1}
+	other := common.Address{2}
+	msg := func(from common.Address, to *common.Address) types.Message {
+		return types.NewMessage(from, to, 0, new(big.Int), 0, new(big.Int), new(big.Int), new(big.Int), nil, nil, false)
+	}
+	f = newSubstateFilter(SubstateFilter{
+		Addresses: []common.Address{watched},
+	})
+	require.True(f.recordMsg(msg(watched, &other)))
+	require.True(f.recordMsg(msg(other, &watched)))
+	require.False(f.recordMsg(msg(other, &other)))
+	require.False(f.recordMsg(msg(other, nil)))
+}
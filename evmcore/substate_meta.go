@@ -0,0 +1,156 @@
+package evmcore
+
+import (
+	"encoding/json"
+
+	"github.com/Fantom-foundation/lachesis-base/common/bigendian"
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/leveldb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/syndtr/goleveldb/leveldb/opt"
+
+	"github.com/Fantom-foundation/go-opera/utils/signers/internaltx"
+)
+
+// SubstateMeta is Opera-specific metadata of a recorded transaction.
+// It is written for skipped transactions too, which have no recorded substate.
+type SubstateMeta struct {
+	Epoch      idx.Epoch   `json:"epoch"`
+	Atropos    hash.Event  `json:"atropos"`
+	TxHash     common.Hash `json:"txHash"`
+	Internal   bool        `json:"internal"`
+	Skipped    bool        `json:"skipped"`
+	SkipReason string      `json:"skipReason,omitempty"`
+}
+
+// substateMetaDB is a DB of substate metadata, nil if metadata isn't recorded.
+var substateMetaDB kvdb.Store
+
+// OpenSubstateMetaDB opens the DB of substate metadata in the dir.
+func OpenSubstateMetaDB(dir string) error {
+	db, err := leveldb.New(dir, 16*opt.MiB, 0, nil, nil)
+	if err != nil {
+		return err
+	}
+	substateMetaDB = db
+	return nil
+}
+
+// CloseSubstateMetaDB closes the DB of substate metadata.
+func CloseSubstateMetaDB() {
+	if substateMetaDB == nil {
+		return
+	}
+	if err := substateMetaDB.Close(); err != nil {
+		log.Error("Failed to close substate metadata DB", "err", err)
+	}
+	substateMetaDB = nil
+}
+
+func substateMetaKey(block uint64, tx int) []byte {
+	return append(bigendian.Uint64ToBytes(block), bigendian.Uint32ToBytes(uint32(tx))...)
+}
+
+// PutSubstateMeta writes metadata of the tx-th transaction of the block, if the metadata DB is open.
+func PutSubstateMeta(block uint64, tx int, meta *SubstateMeta) {
+	if substateMetaDB == nil {
+		return
+	}
+	buf, err := rlp.EncodeToBytes(meta)
+	if err != nil {
+		log.Crit("Failed to encode rlp", "err", err)
+	}
+	if err := substateMetaDB.Put(substateMetaKey(block, tx), buf); err != nil {
+		log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetSubstateMeta returns metadata of the tx-th transaction of the block,
+// or nil if it isn't recorded or the metadata DB isn't open.
+func GetSubstateMeta(block uint64, tx int) *SubstateMeta {
+	if substateMetaDB == nil {
+		return nil
+	}
+	buf, err := substateMetaDB.Get(substateMetaKey(block, tx))
+	if err != nil {
+		log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil
+	}
+	meta := &SubstateMeta{}
+	if err := rlp.DecodeBytes(buf, meta); err != nil {
+		log.Crit("Failed to decode rlp", "err", err, "size", len(buf))
+	}
+	return meta
+}
+
+// substateChainConfigKey is shorter than the transaction keys, so they don't collide.
+func substateChainConfigKey(epoch idx.Epoch) []byte {
+	return append([]byte("c"), epoch.Bytes()...)
+}
+
+// PutSubstateChainConfig writes the EVM chain config of the epoch, if the metadata DB is open.
+// The config is encoded into JSON, as RLP doesn't preserve the unset forks.
+func PutSubstateChainConfig(epoch idx.Epoch, config *params.ChainConfig) {
+	if substateMetaDB == nil {
+		return
+	}
+	buf, err := json.Marshal(config)
+	if err != nil {
+		log.Crit("Failed to encode json", "err", err)
+	}
+	if err := substateMetaDB.Put(substateChainConfigKey(epoch), buf); err != nil {
+		log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetSubstateChainConfig returns the EVM chain config the transactions of the epoch were recorded with,
+// or nil if it isn't recorded or the metadata DB isn't open.
+func GetSubstateChainConfig(epoch idx.Epoch) *params.ChainConfig {
+	if substateMetaDB == nil {
+		return nil
+	}
+	buf, err := substateMetaDB.Get(substateChainConfigKey(epoch))
+	if err != nil {
+		log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil
+	}
+	config := &params.ChainConfig{}
+	if err := json.Unmarshal(buf, config); err != nil {
+		log.Crit("Failed to decode json", "err", err, "size", len(buf))
+	}
+	return config
+}
+
+// recordSubstateMeta writes metadata of the i-th processed transaction if metadata is recorded.
+func (p *StateProcessor) recordSubstateMeta(block *EvmBlock, i int, tx *types.Transaction, skipErr error) {
+	if substateMetaDB == nil {
+		return
+	}
+	meta := &SubstateMeta{
+		Epoch:    p.blockCtx.Atropos.Epoch(),
+		Atropos:  p.blockCtx.Atropos,
+		TxHash:   tx.Hash(),
+		Internal: internaltx.IsInternal(tx),
+	}
+	if skipErr != nil {
+		meta.Skipped = true
+		meta.SkipReason = skipErr.Error()
+	}
+	PutSubstateMeta(block.NumberU64(), p.txOffset+i, meta)
+	// the rules are changed only by sealing an epoch, so the chain config is recorded once per epoch
+	if ok, err := substateMetaDB.Has(substateChainConfigKey(meta.Epoch)); err != nil {
+		log.Crit("Failed to check key-value", "err", err)
+	} else if !ok {
+		PutSubstateChainConfig(meta.Epoch, p.config)
+	}
+}
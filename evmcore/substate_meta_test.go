@@ -0,0 +1,61 @@
+package evmcore
+
+import (
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubstateMeta(t *testing.T) {
+	require := require.New(t)
+
+	substateMetaDB = memorydb.New()
+	defer CloseSubstateMetaDB()
+
+	meta := &SubstateMeta{
+		Epoch:      5,
+		Atropos:    hash.FakeEvent(),
+		TxHash:     common.Hash{1},
+		Internal:   true,
+		Skipped:    true,
+		SkipReason: ErrNonceTooLow.Error(),
+	}
+	PutSubstateMeta(10, 2, meta)
+
+	require.Equal(meta, GetSubstateMeta(10, 2))
+	require.Nil(GetSubstateMeta(10, 3))
+	require.Nil(GetSubstateMeta(11, 2))
+}
+
+func TestSubstateMetaDisabled(t *testing.T) {
+	require := require.New(t)
+
+	CloseSubstateMetaDB()
+	PutSubstateMeta(10, 2, &SubstateMeta{TxHash: common.Hash{1}})
+	require.Nil(GetSubstateMeta(10, 2))
+}
+
+func TestSubstateChainConfig(t *testing.T) {
+	require := require.New(t)
+
+	substateMetaDB = memorydb.New()
+	defer CloseSubstateMetaDB()
+
+	// unset forks are preserved
+	config := *params.TestChainConfig
+	config.BerlinBlock = nil
+	config.LondonBlock = nil
+	PutSubstateChainConfig(2, &config)
+	PutSubstateMeta(10, 2, &SubstateMeta{Epoch: 2})
+
+	got := GetSubstateChainConfig(GetSubstateMeta(10, 2).Epoch)
+	require.NotNil(got)
+	require.Equal(config.String(), got.String())
+	require.Nil(got.BerlinBlock)
+	require.Nil(got.LondonBlock)
+	require.Nil(GetSubstateChainConfig(3))
+}
@@ -0,0 +1,237 @@
+package evmcore
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/substate"
+)
+
+// SubstateMismatch describes a single difference between a recorded substate
+// and the outcome of its replay.
+type SubstateMismatch struct {
+	Field    string          `json:"field"`
+	Address  *common.Address `json:"address,omitempty"`
+	Key      *common.Hash    `json:"key,omitempty"`
+	Expected string          `json:"expected"`
+	Got      string          `json:"got"`
+}
+
+// SubstateReplayReport is the outcome of replaying a single recorded transaction.
+type SubstateReplayReport struct {
+	Block      uint64             `json:"block"`
+	Tx         int                `json:"tx"`
+	Meta       *SubstateMeta      `json:"meta,omitempty"`
+	Mismatches []SubstateMismatch `json:"mismatches,omitempty"`
+}
+
+// OK returns true if the replay reproduced the recorded result.
+func (r *SubstateReplayReport) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+func (r *SubstateReplayReport) mismatch(field string, addr *common.Address, key *common.Hash, expected, got interface{}) {
+	r.Mismatches = append(r.Mismatches, SubstateMismatch{
+		Field:    field,
+		Address:  addr,
+		Key:      key,
+		Expected: fmt.Sprint(expected),
+		Got:      fmt.Sprint(got),
+	})
+}
+
+// ReplaySubstate re-executes a recorded transaction on top of an in-memory state
+// built from its input alloc, and compares the resulting alloc, logs, status and
+// gas usage against the recorded result.
+//
+// The returned error is non-nil only if the replay environment cannot be built;
+// any divergence from the recording is reported as a mismatch.
+// The report includes the Opera metadata of the transaction, if it's recorded.
+func ReplaySubstate(config *params.ChainConfig, vmConfig vm.Config, block uint64, tx int, recording *substate.Substate) (*SubstateReplayReport, error) {
+	report := &SubstateReplayReport{
+		Block: block,
+		Tx:    tx,
+		Meta:  GetSubstateMeta(block, tx),
+	}
+
+	statedb, err := substateStateDB(recording.InputAlloc)
+	if err != nil {
+		return nil, err
+	}
+
+	env := recording.Env
+	var baseFee *big.Int
+	if env.BaseFee != nil {
+		baseFee = new(big.Int).Set(env.BaseFee)
+	}
+	blockContext := vm.BlockContext{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		GetHash: func(n uint64) common.Hash {
+			return env.BlockHashes[n]
+		},
+		Coinbase:    env.Coinbase,
+		BlockNumber: new(big.Int).SetUint64(env.Number),
+		Time:        new(big.Int).SetUint64(env.Timestamp),
+		Difficulty:  new(big.Int).Set(env.Difficulty),
+		BaseFee:     baseFee,
+		GasLimit:    env.GasLimit,
+	}
+
+	// The original transaction hash is not a part of the substate,
+	// so logs are collected under a placeholder one unless it's known from the metadata
+	var (
+		txHash    = common.Hash{}
+		blockHash = common.Hash{}
+		msg       = recording.Message.AsMessage()
+		gp        = new(GasPool).AddGas(env.GasLimit)
+	)
+	if report.Meta != nil {
+		txHash = report.Meta.TxHash
+	}
+	statedb.Prepare(txHash, tx)
+	evm := vm.NewEVM(blockContext, NewEVMTxContext(msg), statedb, config, vmConfig)
+	result, err := ApplyMessage(evm, msg, gp)
+	if err != nil {
+		// recorded transactions were never skipped
+		report.mismatch("error", nil, nil, "none", err)
+		return report, nil
+	}
+	statedb.Finalise(true)
+
+	expected := recording.Result
+	status := types.ReceiptStatusSuccessful
+	if result.Failed() {
+		status = types.ReceiptStatusFailed
+	}
+	if status != expected.Status {
+		report.mismatch("status", nil, nil, expected.Status, status)
+	}
+	if result.UsedGas != expected.GasUsed {
+		report.mismatch("gasUsed", nil, nil, expected.GasUsed, result.UsedGas)
+	}
+	if msg.To() == nil {
+		contractAddress := crypto.CreateAddress(msg.From(), msg.Nonce())
+		if contractAddress != expected.ContractAddress {
+			report.mismatch("contractAddress", nil, nil, expected.ContractAddress.Hex(), contractAddress.Hex())
+		}
+	}
+	compareSubstateLogs(report, expected.Logs, statedb.GetLogs(txHash, blockHash))
+	compareSubstateAlloc(report, recording.InputAlloc, recording.OutputAlloc, statedb)
+
+	return report, nil
+}
+
+// substateStateDB builds a committed in-memory state from the alloc.
+func substateStateDB(alloc substate.SubstateAlloc) (*state.StateDB, error) {
+	db := state.NewDatabase(rawdb.NewMemoryDatabase())
+	statedb, err := state.New(common.Hash{}, db, nil)
+	if err != nil {
+		return nil, err
+	}
+	for addr, acc := range alloc {
+		statedb.SetNonce(addr, acc.Nonce)
+		statedb.SetBalance(addr, acc.Balance)
+		statedb.SetCode(addr, acc.Code)
+		for key, value := range acc.Storage {
+			statedb.SetState(addr, key, value)
+		}
+	}
+	// commit the pre-state to make original values of storage slots
+	// (used by SSTORE gas accounting) match the recorded execution
+	root, err := statedb.Commit(false)
+	if err != nil {
+		return nil, err
+	}
+	return state.New(root, db, nil)
+}
+
+func compareSubstateLogs(report *SubstateReplayReport, expected, got []*types.Log) {
+	if len(expected) != len(got) {
+		report.mismatch("logs", nil, nil, len(expected), len(got))
+		return
+	}
+	for i := range expected {
+		e, g := expected[i], got[i]
+		field := fmt.Sprintf("logs[%d]", i)
+		if e.Address != g.Address {
+			report.mismatch(field+".address", nil, nil, e.Address.Hex(), g.Address.Hex())
+		}
+		if len(e.Topics) != len(g.Topics) {
+			report.mismatch(field+".topics", nil, nil, len(e.Topics), len(g.Topics))
+		} else {
+			for j := range e.Topics {
+				if e.Topics[j] != g.Topics[j] {
+					report.mismatch(fmt.Sprintf("%s.topics[%d]", field, j), nil, nil, e.Topics[j].Hex(), g.Topics[j].Hex())
+				}
+			}
+		}
+		if !bytes.Equal(e.Data, g.Data) {
+			report.mismatch(field+".data", nil, nil, hexutil.Encode(e.Data), hexutil.Encode(g.Data))
+		}
+	}
+}
+
+// compareSubstateAlloc reports the mismatched accounts in the address order (and storage slots in the key order),
+// so reports are deterministic.
+func compareSubstateAlloc(report *SubstateReplayReport, input, output substate.SubstateAlloc, statedb *state.StateDB) {
+	for _, addr := range sortedSubstateAddresses(output) {
+		addr := addr
+		expected := output[addr]
+		if !statedb.Exist(addr) {
+			report.mismatch("account", &addr, nil, "exists", "missing")
+			continue
+		}
+		if nonce := statedb.GetNonce(addr); nonce != expected.Nonce {
+			report.mismatch("nonce", &addr, nil, expected.Nonce, nonce)
+		}
+		if balance := statedb.GetBalance(addr); balance.Cmp(expected.Balance) != 0 {
+			report.mismatch("balance", &addr, nil, expected.Balance, balance)
+		}
+		if code := statedb.GetCode(addr); !bytes.Equal(code, expected.Code) {
+			report.mismatch("code", &addr, nil, crypto.Keccak256Hash(expected.Code).Hex(), crypto.Keccak256Hash(code).Hex())
+		}
+		keys := make([]common.Hash, 0, len(expected.Storage))
+		for key := range expected.Storage {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return bytes.Compare(keys[i].Bytes(), keys[j].Bytes()) < 0
+		})
+		for _, key := range keys {
+			key := key
+			value := expected.Storage[key]
+			if got := statedb.GetState(addr, key); got != value {
+				report.mismatch("storage", &addr, &key, value.Hex(), got.Hex())
+			}
+		}
+	}
+	// accounts which are missing in the output alloc were destroyed by the transaction
+	for _, addr := range sortedSubstateAddresses(input) {
+		addr := addr
+		if _, ok := output[addr]; !ok && statedb.Exist(addr) {
+			report.mismatch("account", &addr, nil, "missing", "exists")
+		}
+	}
+}
+
+func sortedSubstateAddresses(alloc substate.SubstateAlloc) []common.Address {
+	addrs := make([]common.Address, 0, len(alloc))
+	for addr := range alloc {
+		addrs = append(addrs, addr)
+	}
+	sort.Slice(addrs, func(i, j int) bool {
+		return bytes.Compare(addrs[i].Bytes(), addrs[j].Bytes()) < 0
+	})
+	return addrs
+}
@@ -0,0 +1,211 @@
+package evmcore
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/kvdb/memorydb"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/substate"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	replaySender    = common.Address{0x01}
+	replayRecipient = common.Address{0x02}
+	replayContract  = common.Address{0x03}
+)
+
+// replaySubstate builds a recording of a transaction from replaySender with the given input alloc.
+// The transfer costs 21000 of intrinsic gas and 10% of the remaining 9000 gas, i.e. 21900 gas.
+func replaySubstate(to common.Address, value int64, input, output substate.SubstateAlloc, result *substate.SubstateResult) *substate.Substate {
+	msg := types.NewMessage(replaySender, &to, 0, big.NewInt(value), 30000, big.NewInt(1), big.NewInt(1), big.NewInt(1), nil, nil, false)
+	return &substate.Substate{
+		InputAlloc:  input,
+		OutputAlloc: output,
+		Env: &substate.SubstateEnv{
+			Coinbase:   common.Address{0xff},
+			Difficulty: big.NewInt(1),
+			GasLimit:   1000000,
+			Number:     1,
+			Timestamp:  1,
+			BaseFee:    big.NewInt(0),
+		},
+		Message: substate.NewSubstateMessage(&msg),
+		Result:  result,
+	}
+}
+
+func replayAccount(nonce uint64, balance int64, code []byte) *substate.SubstateAccount {
+	return &substate.SubstateAccount{
+		Nonce:   nonce,
+		Balance: big.NewInt(balance),
+		Code:    code,
+		Storage: map[common.Hash]common.Hash{},
+	}
+}
+
+func mismatchesOf(report *SubstateReplayReport, field string) []SubstateMismatch {
+	var res []SubstateMismatch
+	for _, m := range report.Mismatches {
+		if m.Field == field {
+			res = append(res, m)
+		}
+	}
+	return res
+}
+
+func TestReplaySubstateMatching(t *testing.T) {
+	require := require.New(t)
+
+	recording := replaySubstate(replayRecipient, 1000,
+		substate.SubstateAlloc{
+			replaySender: replayAccount(0, 1000000, nil),
+		},
+		substate.SubstateAlloc{
+			replaySender:    replayAccount(1, 1000000-1000-21900, nil),
+			replayRecipient: replayAccount(0, 1000, nil),
+		},
+		&substate.SubstateResult{
+			Status:  types.ReceiptStatusSuccessful,
+			GasUsed: 21900,
+		})
+
+	report, err := ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+	require.NoError(err)
+	require.True(report.OK(), report.Mismatches)
+	require.Equal(uint64(1), report.Block)
+	require.Equal(0, report.Tx)
+	require.Nil(report.Meta)
+
+	// the recorded metadata is attached to the report
+	substateMetaDB = memorydb.New()
+	defer CloseSubstateMetaDB()
+	meta := &SubstateMeta{
+		Epoch:   2,
+		Atropos: hash.FakeEvent(),
+		TxHash:  common.Hash{1},
+	}
+	PutSubstateMeta(1, 0, meta)
+	report, err = ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+	require.NoError(err)
+	require.True(report.OK(), report.Mismatches)
+	require.Equal(meta, report.Meta)
+}
+
+func TestReplaySubstateMismatching(t *testing.T) {
+	require := require.New(t)
+
+	recording := replaySubstate(replayRecipient, 1000,
+		substate.SubstateAlloc{
+			replaySender: replayAccount(0, 1000000, nil),
+		},
+		substate.SubstateAlloc{
+			replaySender:    replayAccount(1, 1000000-1000-21900, nil),
+			replayRecipient: replayAccount(0, 999, nil),
+		},
+		&substate.SubstateResult{
+			Status:  types.ReceiptStatusFailed,
+			GasUsed: 21000,
+			Logs:    []*types.Log{{Address: replayRecipient}},
+		})
+
+	report, err := ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+	require.NoError(err)
+	require.False(report.OK())
+
+	require.Equal([]SubstateMismatch{{Field: "status", Expected: "0", Got: "1"}}, mismatchesOf(report, "status"))
+	require.Equal([]SubstateMismatch{{Field: "gasUsed", Expected: "21000", Got: "21900"}}, mismatchesOf(report, "gasUsed"))
+	require.Equal([]SubstateMismatch{{Field: "logs", Expected: "1", Got: "0"}}, mismatchesOf(report, "logs"))
+	balance := mismatchesOf(report, "balance")
+	require.Len(balance, 1)
+	require.Equal(replayRecipient, *balance[0].Address)
+	require.Equal("999", balance[0].Expected)
+	require.Equal("1000", balance[0].Got)
+	require.Len(report.Mismatches, 4)
+}
+
+func TestReplaySubstateMismatchesOrder(t *testing.T) {
+	require := require.New(t)
+
+	input := substate.SubstateAlloc{
+		replaySender: replayAccount(0, 1000000, nil),
+	}
+	output := substate.SubstateAlloc{
+		replaySender:    replayAccount(1, 1000000-1000-21900, nil),
+		replayRecipient: replayAccount(0, 1000, nil),
+	}
+	// accounts which aren't touched by the transaction
+	for i := 0; i < 20; i++ {
+		output[common.Address{0x10, byte(i)}] = replayAccount(0, 1, nil)
+	}
+	recording := replaySubstate(replayRecipient, 1000, input, output, &substate.SubstateResult{
+		Status:  types.ReceiptStatusSuccessful,
+		GasUsed: 21900,
+	})
+
+	var prev []SubstateMismatch
+	for i := 0; i < 5; i++ {
+		report, err := ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+		require.NoError(err)
+		require.Len(report.Mismatches, 20)
+		for j := 1; j < len(report.Mismatches); j++ {
+			require.True(bytes.Compare(report.Mismatches[j-1].Address.Bytes(), report.Mismatches[j].Address.Bytes()) < 0)
+		}
+		if prev != nil {
+			require.Equal(prev, report.Mismatches)
+		}
+		prev = report.Mismatches
+	}
+}
+
+func TestReplaySubstateDestroyed(t *testing.T) {
+	require := require.New(t)
+
+	// CALLER SELFDESTRUCT
+	code := []byte{byte(vm.CALLER), byte(vm.SELFDESTRUCT)}
+	input := substate.SubstateAlloc{
+		replaySender:   replayAccount(0, 1000000, nil),
+		replayContract: replayAccount(0, 500, code),
+	}
+	result := &substate.SubstateResult{
+		Status: types.ReceiptStatusSuccessful,
+	}
+
+	// the destroyed contract is missing in the output alloc
+	survived := substate.SubstateAlloc{
+		replaySender: replayAccount(1, 1000000, nil),
+	}
+	recording := replaySubstate(replayContract, 0, input, survived, result)
+	report, err := ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+	require.NoError(err)
+	require.Empty(mismatchesOf(report, "account"))
+
+	// the recording claims the contract survived
+	recording = replaySubstate(replayContract, 0, input, substate.SubstateAlloc{
+		replaySender:   replayAccount(1, 1000000, nil),
+		replayContract: replayAccount(0, 0, code),
+	}, result)
+	report, err = ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+	require.NoError(err)
+	account := mismatchesOf(report, "account")
+	require.Len(account, 1)
+	require.Equal(replayContract, *account[0].Address)
+	require.Equal("exists", account[0].Expected)
+	require.Equal("missing", account[0].Got)
+
+	// the recording claims the sender was destroyed
+	recording = replaySubstate(replayContract, 0, input, substate.SubstateAlloc{}, result)
+	report, err = ReplaySubstate(params.TestChainConfig, vm.Config{}, 1, 0, recording)
+	require.NoError(err)
+	account = mismatchesOf(report, "account")
+	require.Len(account, 1)
+	require.Equal(replaySender, *account[0].Address)
+	require.Equal("missing", account[0].Expected)
+	require.Equal("exists", account[0].Got)
+}
@@ -0,0 +1,126 @@
+package evmcore
+
+import (
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	notify "github.com/ethereum/go-ethereum/event"
+)
+
+// TxDropReason is the reason of a transaction removal from the pool before it's included in a block.
+type TxDropReason string
+
+const (
+	// TxDropReplaced means the transaction is superseded by another one with the same nonce.
+	TxDropReplaced TxDropReason = "replaced"
+	// TxDropUnderpriced means the transaction is evicted by better priced ones when the pool is full,
+	// or is priced below a raised minimum gas price.
+	TxDropUnderpriced TxDropReason = "underpriced"
+	// TxDropNonceTooLow means the nonce of the queued transaction is already used by another transaction.
+	TxDropNonceTooLow TxDropReason = "nonceTooLow"
+	// TxDropUnpayable means the sender balance can't cover the transaction cost any more,
+	// or the transaction gas exceeds the block gas limit.
+	TxDropUnpayable TxDropReason = "unpayable"
+	// TxDropPendingLimit means the transaction is evicted as the pool exceeds the executable transactions limit.
+	TxDropPendingLimit TxDropReason = "pendingLimit"
+	// TxDropQueueLimit means the transaction is evicted as the pool or the account exceeds the queued transactions limit.
+	TxDropQueueLimit TxDropReason = "queueLimit"
+	// TxDropLifetime means the transaction is queued for longer than the allowed lifetime.
+	TxDropLifetime TxDropReason = "lifetime"
+)
+
+// DroppedTx is a transaction removed from the pool before it's included in a block.
+type DroppedTx struct {
+	Tx     *types.Transaction
+	From   common.Address
+	Reason TxDropReason
+	Time   time.Time
+}
+
+// DroppedTxsNotify is posted when a batch of transactions are dropped from the transaction pool.
+type DroppedTxsNotify struct{ Txs []*DroppedTx }
+
+// txDropHistory is a bounded history of the dropped transactions, indexed by sender.
+// Drops are recorded under the pool lock, and are notified asynchronously,
+// so slow subscribers never block the pool.
+type txDropHistory struct {
+	limit int
+
+	mu        sync.Mutex
+	all       []*DroppedTx // the oldest first
+	byAccount map[common.Address][]*DroppedTx
+	unsent    []*DroppedTx
+
+	feed   notify.Feed
+	signal chan struct{}
+}
+
+func newTxDropHistory(limit int) *txDropHistory {
+	return &txDropHistory{
+		limit:     limit,
+		byAccount: make(map[common.Address][]*DroppedTx),
+		signal:    make(chan struct{}, 1),
+	}
+}
+
+// record adds the dropped transaction to the history, evicting the oldest record if the history is full.
+func (h *txDropHistory) record(d *DroppedTx) {
+	if h.limit <= 0 {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if len(h.all) >= h.limit {
+		oldest := h.all[0]
+		h.all = h.all[1:]
+		// the oldest record of the history is the oldest one of its account
+		if records := h.byAccount[oldest.From]; len(records) <= 1 {
+			delete(h.byAccount, oldest.From)
+		} else {
+			h.byAccount[oldest.From] = records[1:]
+		}
+	}
+	h.all = append(h.all, d)
+	h.byAccount[d.From] = append(h.byAccount[d.From], d)
+
+	if len(h.unsent) >= h.limit {
+		h.unsent = h.unsent[1:]
+	}
+	h.unsent = append(h.unsent, d)
+	select {
+	case h.signal <- struct{}{}:
+	default:
+	}
+}
+
+// account returns the recorded drops of the sender, the oldest first.
+func (h *txDropHistory) account(addr common.Address) []*DroppedTx {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	records := h.byAccount[addr]
+	res := make([]*DroppedTx, len(records))
+	copy(res, records)
+	return res
+}
+
+// notifyLoop sends the recorded drops to the subscribers, until quit is closed.
+func (h *txDropHistory) notifyLoop(quit <-chan struct{}) {
+	for {
+		select {
+		case <-h.signal:
+			h.mu.Lock()
+			unsent := h.unsent
+			h.unsent = nil
+			h.mu.Unlock()
+			if len(unsent) != 0 {
+				h.feed.Send(DroppedTxsNotify{unsent})
+			}
+		case <-quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package evmcore
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxDropHistoryLimit(t *testing.T) {
+	require := require.New(t)
+
+	key, _ := crypto.GenerateKey()
+	h := newTxDropHistory(2)
+	a, b := common.Address{0x01}, common.Address{0x02}
+
+	h.record(&DroppedTx{Tx: transaction(0, 100000, key), From: a, Reason: TxDropReplaced})
+	h.record(&DroppedTx{Tx: transaction(1, 100000, key), From: b, Reason: TxDropLifetime})
+	h.record(&DroppedTx{Tx: transaction(2, 100000, key), From: b, Reason: TxDropQueueLimit})
+
+	require.Empty(h.account(a))
+	dropped := h.account(b)
+	require.Len(dropped, 2)
+	require.Equal(TxDropLifetime, dropped[0].Reason)
+	require.Equal(TxDropQueueLimit, dropped[1].Reason)
+
+	// disabled history records nothing
+	h = newTxDropHistory(0)
+	h.record(&DroppedTx{Tx: transaction(0, 100000, key), From: a, Reason: TxDropReplaced})
+	require.Empty(h.account(a))
+}
+
+func TestTransactionDropped(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	dropped := make(chan DroppedTxsNotify, 1)
+	sub := pool.SubscribeDroppedTxsNotify(dropped)
+	defer sub.Unsubscribe()
+
+	// replace a pending transaction with a better priced one
+	original := pricedTransaction(0, 100000, big.NewInt(1), key)
+	require.NoError(pool.addRemoteSync(original))
+	require.NoError(pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(2), key)))
+
+	select {
+	case ev := <-dropped:
+		require.Len(ev.Txs, 1)
+		require.Equal(original.Hash(), ev.Txs[0].Tx.Hash())
+		require.Equal(from, ev.Txs[0].From)
+		require.Equal(TxDropReplaced, ev.Txs[0].Reason)
+	case <-time.After(time.Second):
+		t.Fatal("dropped transaction notification timeout")
+	}
+
+	history := pool.Dropped(from)
+	require.Len(history, 1)
+	require.Equal(original.Hash(), history[0].Tx.Hash())
+	require.Equal(TxDropReplaced, history[0].Reason)
+	require.Empty(pool.Dropped(common.Address{0x01}))
+}
+
+// blocksTestBlockChain is testBlockChain with a chain of blocks and without any tx index.
+type blocksTestBlockChain struct {
+	*testBlockChain
+	blocks []*EvmBlock
+}
+
+func (bc *blocksTestBlockChain) GetBlock(hash common.Hash, number uint64) *EvmBlock {
+	if number >= uint64(len(bc.blocks)) || bc.blocks[number].Hash != hash {
+		return nil
+	}
+	return bc.blocks[number]
+}
+
+func (bc *blocksTestBlockChain) mine(txs ...*types.Transaction) *EvmHeader {
+	parent := bc.blocks[len(bc.blocks)-1]
+	block := &EvmBlock{
+		EvmHeader: EvmHeader{
+			Number:     big.NewInt(int64(len(bc.blocks))),
+			Hash:       common.Hash{byte(len(bc.blocks))},
+			ParentHash: parent.Hash,
+			GasLimit:   bc.gasLimit,
+		},
+		Transactions: txs,
+	}
+	bc.blocks = append(bc.blocks, block)
+	return &block.EvmHeader
+}
+
+func TestTransactionDroppedNotMined(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &blocksTestBlockChain{
+		testBlockChain: &testBlockChain{statedb, 1000000, new(event.Feed)},
+	}
+	genesis := blockchain.testBlockChain.CurrentBlock()
+	genesis.Number = big.NewInt(0)
+	blockchain.blocks = []*EvmBlock{genesis}
+
+	pool := NewTxPool(testTxPoolConfig, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	key, _ := crypto.GenerateKey()
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1000000000))
+
+	// queue transactions with nonce gaps
+	mined1 := transaction(1, 100000, key)
+	stale := transaction(3, 100000, key)
+	mined2 := transaction(5, 100000, key)
+	gapped := transaction(10, 100000, key)
+	for _, tx := range []*types.Transaction{mined1, stale, mined2, gapped} {
+		require.NoError(pool.addRemoteSync(tx))
+	}
+	_, queued := pool.Stats()
+	require.Equal(4, queued)
+
+	// the next block
+	head := &genesis.EvmHeader
+	newHead := blockchain.mine(mined1)
+	testSetNonce(pool, from, 2)
+	<-pool.requestReset(head, newHead)
+	require.Empty(pool.Dropped(from))
+
+	// a few blocks at once, only one of the queued transactions is mined
+	head = newHead
+	blockchain.mine()
+	newHead = blockchain.mine(mined2)
+	testSetNonce(pool, from, 6)
+	<-pool.requestReset(head, newHead)
+
+	pending, queued := pool.Stats()
+	require.Equal(0, pending)
+	require.Equal(1, queued)
+	history := pool.Dropped(from)
+	require.Len(history, 1)
+	require.Equal(stale.Hash(), history[0].Tx.Hash())
+	require.Equal(TxDropNonceTooLow, history[0].Reason)
+}
@@ -0,0 +1,360 @@
+package evmcore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/metrics"
+)
+
+// maxRecentTxPolicyRejections is the number of the most recent rejections kept for the report.
+const maxRecentTxPolicyRejections = 64
+
+var (
+	// ErrTxPolicyDenied is returned if a transaction is rejected by the pool admission policy.
+	ErrTxPolicyDenied = errors.New("transaction denied by txpool policy")
+
+	deniedTxMeter        = metrics.GetOrRegisterMeter("txpool/policy/denied", nil)
+	deprioritisedTxMeter = metrics.GetOrRegisterMeter("txpool/policy/deprioritised", nil)
+)
+
+// TxPolicyAction is a decision of the pool admission policy on a transaction.
+type TxPolicyAction string
+
+const (
+	// TxPolicyAllow admits the transaction as usual.
+	TxPolicyAllow TxPolicyAction = "allow"
+	// TxPolicyDeny rejects the transaction.
+	TxPolicyDeny TxPolicyAction = "deny"
+	// TxPolicyDeprioritise admits the transaction only if the pool isn't full,
+	// i.e. it never displaces other transactions.
+	TxPolicyDeprioritise TxPolicyAction = "deprioritise"
+)
+
+func (a TxPolicyAction) valid() bool {
+	return a == TxPolicyAllow || a == TxPolicyDeny || a == TxPolicyDeprioritise
+}
+
+// TxPolicyInput is the transaction data which the pool admission policy decides on.
+type TxPolicyInput struct {
+	Tx         *types.Transaction
+	From       common.Address
+	ToContract bool // whether the recipient is a contract account at the current state
+	Local      bool
+}
+
+// TxPolicyDecision is the result of the pool admission policy check.
+type TxPolicyDecision struct {
+	Action TxPolicyAction
+	Rule   string // name of the rule which decided, for the report
+}
+
+// TxPolicy is a hook which decides on admission of transactions to the pool.
+// Check is called for every transaction which passed the validation, and Admitted is called
+// once the transaction is added to the pool, both under the pool lock.
+type TxPolicy interface {
+	Check(in TxPolicyInput) TxPolicyDecision
+	Admitted(in TxPolicyInput)
+}
+
+// TxPolicyRule matches transactions by all of its non-empty criteria.
+// A criterion list matches if any of its items matches.
+type TxPolicyRule struct {
+	Name       string           // Name of the rule in the rejections report
+	Action     TxPolicyAction   // Action applied to the matched transactions
+	Senders    []common.Address // Senders of the transactions
+	Recipients []common.Address // Recipients of the transactions
+	Selectors  []hexutil.Bytes  // 4-byte method selectors of the transactions input
+	MinValue   *big.Int         // Minimum transferred value, in wei
+}
+
+// TxRateLimit is a limit of the count of admitted transactions per period.
+type TxRateLimit struct {
+	Txs    uint64        // Max number of transactions per period (0 = no limit)
+	Period time.Duration // Period of the limit
+}
+
+// TxPolicyConfig is the configuration of the default pool admission policy.
+type TxPolicyConfig struct {
+	Rules   []TxPolicyRule // Rules are checked in order, the first matched rule decides
+	Default TxPolicyAction // Action for transactions matched by no rule ("allow" if empty, "deny" to run on an allow list)
+
+	SenderRateLimit   TxRateLimit // Limit of admitted transactions per sender
+	ContractRateLimit TxRateLimit // Limit of admitted transactions per target contract
+}
+
+// Validate checks the policy configuration.
+func (c TxPolicyConfig) Validate() error {
+	if c.Default != "" && !c.Default.valid() {
+		return fmt.Errorf("invalid txpool policy default action %q", c.Default)
+	}
+	for i, rule := range c.Rules {
+		if !rule.Action.valid() {
+			return fmt.Errorf("invalid txpool policy action %q of rule %s", rule.Action, ruleName(i, rule))
+		}
+		for _, selector := range rule.Selectors {
+			if len(selector) != 4 {
+				return fmt.Errorf("invalid txpool policy method selector %s of rule %s", selector, ruleName(i, rule))
+			}
+		}
+	}
+	if c.SenderRateLimit.Txs != 0 && c.SenderRateLimit.Period <= 0 {
+		return errors.New("txpool policy sender rate limit period must be positive")
+	}
+	if c.ContractRateLimit.Txs != 0 && c.ContractRateLimit.Period <= 0 {
+		return errors.New("txpool policy contract rate limit period must be positive")
+	}
+	return nil
+}
+
+func ruleName(i int, rule TxPolicyRule) string {
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return fmt.Sprintf("#%d", i)
+}
+
+// configuredTxPolicy is the TxPolicy defined by TxPolicyConfig.
+type configuredTxPolicy struct {
+	config TxPolicyConfig
+
+	senders   *txRateLimiter
+	contracts *txRateLimiter
+}
+
+// NewTxPolicy creates the admission policy defined by the configuration.
+// The configuration is expected to be validated, unknown actions deny transactions.
+func NewTxPolicy(config TxPolicyConfig) TxPolicy {
+	return &configuredTxPolicy{
+		config:    config,
+		senders:   newTxRateLimiter(config.SenderRateLimit),
+		contracts: newTxRateLimiter(config.ContractRateLimit),
+	}
+}
+
+// Check implements TxPolicy.
+func (p *configuredTxPolicy) Check(in TxPolicyInput) TxPolicyDecision {
+	decision := TxPolicyDecision{
+		Action: TxPolicyAllow,
+		Rule:   "default",
+	}
+	if p.config.Default != "" {
+		decision.Action = p.config.Default
+	}
+	for i, rule := range p.config.Rules {
+		if ruleMatches(rule, in) {
+			decision = TxPolicyDecision{
+				Action: rule.Action,
+				Rule:   ruleName(i, rule),
+			}
+			break
+		}
+	}
+	if decision.Action == TxPolicyDeny {
+		return decision
+	}
+
+	now := time.Now()
+	if p.senders.exceeded(in.From, now) {
+		return TxPolicyDecision{Action: TxPolicyDeny, Rule: "sender rate limit"}
+	}
+	if toContract(in) && p.contracts.exceeded(*in.Tx.To(), now) {
+		return TxPolicyDecision{Action: TxPolicyDeny, Rule: "contract rate limit"}
+	}
+	return decision
+}
+
+// Admitted implements TxPolicy, the rate limits are charged only by the admitted transactions.
+func (p *configuredTxPolicy) Admitted(in TxPolicyInput) {
+	now := time.Now()
+	p.senders.consume(in.From, now)
+	if toContract(in) {
+		p.contracts.consume(*in.Tx.To(), now)
+	}
+}
+
+func toContract(in TxPolicyInput) bool {
+	return in.Tx.To() != nil && in.ToContract
+}
+
+func ruleMatches(rule TxPolicyRule, in TxPolicyInput) bool {
+	if len(rule.Senders) != 0 && !containsAddress(rule.Senders, in.From) {
+		return false
+	}
+	if len(rule.Recipients) != 0 && (in.Tx.To() == nil || !containsAddress(rule.Recipients, *in.Tx.To())) {
+		return false
+	}
+	if len(rule.Selectors) != 0 {
+		data := in.Tx.Data()
+		if len(data) < 4 {
+			return false
+		}
+		matched := false
+		for _, selector := range rule.Selectors {
+			if bytes.Equal(data[:4], selector) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	if rule.MinValue != nil && in.Tx.Value().Cmp(rule.MinValue) < 0 {
+		return false
+	}
+	return true
+}
+
+func containsAddress(addrs []common.Address, addr common.Address) bool {
+	for _, a := range addrs {
+		if a == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// txRateLimiter counts admitted transactions per address within fixed windows.
+type txRateLimiter struct {
+	limit     TxRateLimit
+	windows   map[common.Address]*txRateWindow
+	lastPurge time.Time
+}
+
+type txRateWindow struct {
+	start time.Time
+	count uint64
+}
+
+func newTxRateLimiter(limit TxRateLimit) *txRateLimiter {
+	return &txRateLimiter{
+		limit:   limit,
+		windows: make(map[common.Address]*txRateWindow),
+	}
+}
+
+func (l *txRateLimiter) window(addr common.Address, now time.Time) *txRateWindow {
+	w := l.windows[addr]
+	if w == nil || now.Sub(w.start) >= l.limit.Period {
+		return nil
+	}
+	return w
+}
+
+// exceeded checks whether one more transaction of the address exceeds the limit.
+func (l *txRateLimiter) exceeded(addr common.Address, now time.Time) bool {
+	if l.limit.Txs == 0 {
+		return false
+	}
+	w := l.window(addr, now)
+	return w != nil && w.count >= l.limit.Txs
+}
+
+// consume counts a transaction of the address.
+func (l *txRateLimiter) consume(addr common.Address, now time.Time) {
+	if l.limit.Txs == 0 {
+		return
+	}
+	w := l.window(addr, now)
+	if w == nil {
+		w = &txRateWindow{start: now}
+		l.windows[addr] = w
+	}
+	w.count++
+
+	// drop the expired windows, not to grow the memory indefinitely
+	if now.Sub(l.lastPurge) >= l.limit.Period {
+		for a, w := range l.windows {
+			if now.Sub(w.start) >= l.limit.Period {
+				delete(l.windows, a)
+			}
+		}
+		l.lastPurge = now
+	}
+}
+
+// TxPolicyRejection is a transaction rejected or deprioritised by the pool admission policy.
+type TxPolicyRejection struct {
+	Hash   common.Hash
+	From   common.Address
+	To     *common.Address
+	Action TxPolicyAction
+	Rule   string
+	Time   time.Time
+}
+
+// TxPolicyStats is the report of the pool admission policy decisions.
+type TxPolicyStats struct {
+	Denied        map[string]uint64   // Number of denied transactions by rule
+	Deprioritised map[string]uint64   // Number of deprioritised transactions by rule
+	Recent        []TxPolicyRejection // The most recent rejections, the newest last
+}
+
+// txPolicyStats accumulates the policy decisions.
+type txPolicyStats struct {
+	mu            sync.Mutex
+	denied        map[string]uint64
+	deprioritised map[string]uint64
+	recent        []TxPolicyRejection
+}
+
+func newTxPolicyStats() *txPolicyStats {
+	return &txPolicyStats{
+		denied:        make(map[string]uint64),
+		deprioritised: make(map[string]uint64),
+	}
+}
+
+func (s *txPolicyStats) record(in TxPolicyInput, decision TxPolicyDecision) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch decision.Action {
+	case TxPolicyAllow:
+		return
+	case TxPolicyDeprioritise:
+		s.deprioritised[decision.Rule]++
+		deprioritisedTxMeter.Mark(1)
+	default:
+		s.denied[decision.Rule]++
+		deniedTxMeter.Mark(1)
+	}
+	if len(s.recent) >= maxRecentTxPolicyRejections {
+		s.recent = append(s.recent[:0], s.recent[1:]...)
+	}
+	s.recent = append(s.recent, TxPolicyRejection{
+		Hash:   in.Tx.Hash(),
+		From:   in.From,
+		To:     in.Tx.To(),
+		Action: decision.Action,
+		Rule:   decision.Rule,
+		Time:   time.Now(),
+	})
+}
+
+func (s *txPolicyStats) copy() TxPolicyStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cp := TxPolicyStats{
+		Denied:        make(map[string]uint64, len(s.denied)),
+		Deprioritised: make(map[string]uint64, len(s.deprioritised)),
+		Recent:        make([]TxPolicyRejection, len(s.recent)),
+	}
+	for rule, n := range s.denied {
+		cp.Denied[rule] = n
+	}
+	for rule, n := range s.deprioritised {
+		cp.Deprioritised[rule] = n
+	}
+	copy(cp.Recent, s.recent)
+	return cp
+}
@@ -0,0 +1,137 @@
+package evmcore
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/rawdb"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxPolicyConfigValidate(t *testing.T) {
+	require := require.New(t)
+
+	require.NoError(TxPolicyConfig{}.Validate())
+	require.NoError(TxPolicyConfig{
+		Rules:           []TxPolicyRule{{Action: TxPolicyDeprioritise, Selectors: []hexutil.Bytes{{1, 2, 3, 4}}}},
+		Default:         TxPolicyDeny,
+		SenderRateLimit: TxRateLimit{Txs: 1, Period: time.Second},
+	}.Validate())
+
+	require.Error(TxPolicyConfig{Default: "block"}.Validate())
+	require.Error(TxPolicyConfig{Rules: []TxPolicyRule{{}}}.Validate())
+	require.Error(TxPolicyConfig{Rules: []TxPolicyRule{{Action: TxPolicyDeny, Selectors: []hexutil.Bytes{{1, 2, 3}}}}}.Validate())
+	require.Error(TxPolicyConfig{ContractRateLimit: TxRateLimit{Txs: 1}}.Validate())
+}
+
+func TestTransactionPolicy(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	var (
+		spammer, _ = crypto.GenerateKey()
+		sender, _  = crypto.GenerateKey()
+		token      = common.Address{0x01}
+		transfer   = hexutil.Bytes{0xa9, 0x05, 0x9c, 0xbb}
+	)
+	policyTx := func(nonce uint64, to common.Address, data []byte) *types.Transaction {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, to, big.NewInt(100), 100000, big.NewInt(1), data), types.HomesteadSigner{}, sender)
+		return tx
+	}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.Policy = TxPolicyConfig{
+		Rules: []TxPolicyRule{
+			{Name: "spammer", Action: TxPolicyDeny, Senders: []common.Address{crypto.PubkeyToAddress(spammer.PublicKey)}},
+			{Name: "token transfers", Action: TxPolicyDeny, Recipients: []common.Address{token}, Selectors: []hexutil.Bytes{transfer}},
+		},
+		SenderRateLimit: TxRateLimit{Txs: 2, Period: time.Hour},
+	}
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(spammer.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(sender.PublicKey), big.NewInt(1000000000))
+
+	// denied by the sender
+	err := pool.addRemoteSync(transaction(0, 100000, spammer))
+	require.True(errors.Is(err, ErrTxPolicyDenied), err)
+
+	// denied by the recipient and method, other calls of the recipient are allowed
+	err = pool.addRemoteSync(policyTx(0, token, append(transfer, make([]byte, 64)...)))
+	require.True(errors.Is(err, ErrTxPolicyDenied), err)
+	require.NoError(pool.addRemoteSync(policyTx(0, token, []byte{0x01, 0x02, 0x03, 0x04})))
+
+	// a transaction which isn't added doesn't count against the rate limit
+	err = pool.addRemoteSync(policyTx(0, token, []byte{0x05, 0x06, 0x07, 0x08}))
+	require.Equal(ErrReplaceUnderpriced, err)
+
+	// denied by the sender rate limit
+	require.NoError(pool.addRemoteSync(policyTx(1, common.Address{0x02}, nil)))
+	err = pool.addRemoteSync(policyTx(2, common.Address{0x02}, nil))
+	require.True(errors.Is(err, ErrTxPolicyDenied), err)
+
+	pending, queued := pool.Stats()
+	require.Equal(2, pending)
+	require.Equal(0, queued)
+
+	stats := pool.PolicyStats()
+	require.Equal(map[string]uint64{
+		"spammer":           1,
+		"token transfers":   1,
+		"sender rate limit": 1,
+	}, stats.Denied)
+	require.Empty(stats.Deprioritised)
+	require.Len(stats.Recent, 3)
+	require.Equal("sender rate limit", stats.Recent[2].Rule)
+	require.Equal(crypto.PubkeyToAddress(sender.PublicKey), stats.Recent[2].From)
+}
+
+func TestTransactionPolicyDeprioritise(t *testing.T) {
+	t.Parallel()
+	require := require.New(t)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	deprioritised, _ := crypto.GenerateKey()
+	regular, _ := crypto.GenerateKey()
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 1
+	config.GlobalQueue = 1
+	config.Policy = TxPolicyConfig{
+		Rules: []TxPolicyRule{
+			{Name: "low", Action: TxPolicyDeprioritise, Senders: []common.Address{crypto.PubkeyToAddress(deprioritised.PublicKey)}},
+		},
+	}
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+
+	testAddBalance(pool, crypto.PubkeyToAddress(deprioritised.PublicKey), big.NewInt(1000000000))
+	testAddBalance(pool, crypto.PubkeyToAddress(regular.PublicKey), big.NewInt(1000000000))
+
+	// deprioritised transactions are admitted while the pool isn't full
+	require.NoError(pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(1), regular)))
+	require.NoError(pool.addRemoteSync(pricedTransaction(0, 100000, big.NewInt(10), deprioritised)))
+
+	// but don't displace others even if better priced
+	err := pool.addRemoteSync(pricedTransaction(1, 100000, big.NewInt(10), deprioritised))
+	require.Equal(ErrTxPoolOverflow, err)
+
+	pending, _ := pool.Stats()
+	require.Equal(2, pending)
+	require.Equal(map[string]uint64{"low": 2}, pool.PolicyStats().Deprioritised)
+}
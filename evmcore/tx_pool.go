@@ -18,6 +18,7 @@ package evmcore
 
 import (
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
 	"math/rand"
@@ -152,6 +153,9 @@ type TxPoolConfig struct {
 	Journal   string           // Journal of local transactions to survive node restarts
 	Rejournal time.Duration    // Time interval to regenerate the local transaction journal
 
+	Snapshot   string        // Snapshot of remote transactions to survive node restarts (empty = disabled)
+	Resnapshot time.Duration // Time interval to regenerate the remote transactions snapshot
+
 	PriceLimit uint64 // Minimum gas price to enforce for acceptance into the pool
 	PriceBump  uint64 // Minimum price bump percentage to replace an already existing transaction (nonce)
 
@@ -161,6 +165,10 @@ type TxPoolConfig struct {
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
+
+	DroppedHistory int // Number of the most recently dropped transactions to keep reasons of (0 = disabled)
+
+	Policy TxPolicyConfig // Admission policy of transactions
 }
 
 // DefaultTxPoolConfig contains the default configurations for the transaction
@@ -169,6 +177,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	Journal:   "transactions.rlp",
 	Rejournal: time.Hour,
 
+	Resnapshot: 10 * time.Minute,
+
 	PriceLimit: 1,
 	PriceBump:  10,
 
@@ -178,6 +188,8 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	GlobalQueue:  256,
 
 	Lifetime: 3 * time.Hour,
+
+	DroppedHistory: 4096,
 }
 
 // sanitize checks the provided user configurations and changes anything that's
@@ -188,6 +200,10 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool journal time", "provided", conf.Rejournal, "updated", time.Second)
 		conf.Rejournal = time.Second
 	}
+	if conf.Resnapshot < time.Second {
+		log.Warn("Sanitizing invalid txpool snapshot time", "provided", conf.Resnapshot, "updated", time.Second)
+		conf.Resnapshot = time.Second
+	}
 	if conf.PriceLimit < 1 {
 		log.Warn("Sanitizing invalid txpool price limit", "provided", conf.PriceLimit, "updated", DefaultTxPoolConfig.PriceLimit)
 		conf.PriceLimit = DefaultTxPoolConfig.PriceLimit
@@ -247,6 +263,14 @@ type TxPool struct {
 	locals  *accountSet // Set of local transaction to exempt from eviction rules
 	journal *txJournal  // Journal of local transaction to back up to disk
 
+	snapshot *txSnapshot // Snapshot of remote transactions to back up to disk
+
+	policy      TxPolicy       // Admission policy of transactions
+	policyStats *txPolicyStats // Report of the admission policy decisions
+
+	dropped *txDropHistory           // History of the transactions dropped from the pool
+	mined   map[common.Hash]struct{} // Transactions of the blocks applied by the last reset, nil if unknown
+
 	pending map[common.Address]*txList   // All currently processable transactions
 	queue   map[common.Address]*txList   // Queued but non-processable transactions
 	beats   map[common.Address]time.Time // Last heartbeat from each known account
@@ -259,8 +283,8 @@ type TxPool struct {
 	reqPromoteCh    chan *accountSet
 	queueTxEventCh  chan *types.Transaction
 	reorgDoneCh     chan chan struct{}
-	reorgShutdownCh chan struct{}  // requests shutdown of scheduleReorgLoop
-	wg              sync.WaitGroup // tracks loop, scheduleReorgLoop
+	reorgShutdownCh chan struct{}  // requests shutdown of scheduleReorgLoop and dropped txs notifications
+	wg              sync.WaitGroup // tracks loop, scheduleReorgLoop, dropped txs notifications
 }
 
 type txpoolResetRequest struct {
@@ -290,6 +314,9 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain State
 		reorgDoneCh:     make(chan chan struct{}),
 		reorgShutdownCh: make(chan struct{}),
 		gasPrice:        new(big.Int).SetUint64(config.PriceLimit),
+		policy:          NewTxPolicy(config.Policy),
+		policyStats:     newTxPolicyStats(),
+		dropped:         newTxDropHistory(config.DroppedHistory),
 	}
 	pool.locals = newAccountSet(pool.signer)
 	for _, addr := range config.Locals {
@@ -303,6 +330,12 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain State
 	pool.wg.Add(1)
 	go pool.scheduleReorgLoop()
 
+	pool.wg.Add(1)
+	go func() {
+		defer pool.wg.Done()
+		pool.dropped.notifyLoop(pool.reorgShutdownCh)
+	}()
+
 	// If local transactions and journaling is enabled, load from disk
 	if !config.NoLocals && config.Journal != "" {
 		pool.journal = newTxJournal(config.Journal)
@@ -314,6 +347,14 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain State
 			log.Warn("Failed to rotate transaction journal", "err", err)
 		}
 	}
+	// If the snapshot is enabled, reload remote transactions from disk
+	if config.Snapshot != "" {
+		pool.snapshot = newTxSnapshot(config.Snapshot)
+
+		if err := pool.snapshot.load(pool.AddRemotesSync); err != nil {
+			log.Warn("Failed to load transaction pool snapshot", "err", err)
+		}
+	}
 
 	// Subscribe events from blockchain and start the main event loop.
 	pool.chainHeadSub = pool.chain.SubscribeNewBlock(pool.chainHeadCh)
@@ -332,15 +373,17 @@ func (pool *TxPool) loop() {
 	var (
 		prevPending, prevQueued, prevStales int
 		// Start the stats reporting and transaction eviction tickers
-		report  = time.NewTicker(statsReportInterval)
-		evict   = time.NewTicker(evictionInterval)
-		journal = time.NewTicker(pool.config.Rejournal)
+		report   = time.NewTicker(statsReportInterval)
+		evict    = time.NewTicker(evictionInterval)
+		journal  = time.NewTicker(pool.config.Rejournal)
+		snapshot = time.NewTicker(pool.config.Resnapshot)
 		// Track the previous head headers for transaction reorgs
 		head = pool.chain.CurrentBlock()
 	)
 	defer report.Stop()
 	defer evict.Stop()
 	defer journal.Stop()
+	defer snapshot.Stop()
 
 	for {
 		select {
@@ -381,6 +424,7 @@ func (pool *TxPool) loop() {
 					list := pool.queue[addr].Flatten()
 					for _, tx := range list {
 						pool.removeTx(tx.Hash(), true)
+						pool.recordDropped(tx, TxDropLifetime)
 					}
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
@@ -396,6 +440,17 @@ func (pool *TxPool) loop() {
 				}
 				pool.mu.Unlock()
 			}
+
+		// Handle remote transactions snapshot regeneration
+		case <-snapshot.C:
+			if pool.snapshot != nil {
+				pool.mu.RLock()
+				remotes := pool.remote()
+				pool.mu.RUnlock()
+				if err := pool.snapshot.save(remotes); err != nil {
+					log.Warn("Failed to save tx pool snapshot", "err", err)
+				}
+			}
 		}
 	}
 }
@@ -412,9 +467,42 @@ func (pool *TxPool) Stop() {
 	if pool.journal != nil {
 		pool.journal.close()
 	}
+	if pool.snapshot != nil {
+		pool.mu.RLock()
+		remotes := pool.remote()
+		pool.mu.RUnlock()
+		if err := pool.snapshot.save(remotes); err != nil {
+			log.Warn("Failed to save tx pool snapshot", "err", err)
+		}
+	}
 	log.Info("Transaction pool stopped")
 }
 
+// SetPolicy replaces the admission policy of transactions.
+// The policy isn't applied to the transactions which are already in the pool.
+func (pool *TxPool) SetPolicy(policy TxPolicy) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.policy = policy
+}
+
+// PolicyStats returns the report of the admission policy decisions.
+func (pool *TxPool) PolicyStats() TxPolicyStats {
+	return pool.policyStats.copy()
+}
+
+// Dropped returns the recently dropped transactions of the account, the oldest first.
+func (pool *TxPool) Dropped(addr common.Address) []*DroppedTx {
+	return pool.dropped.account(addr)
+}
+
+// SubscribeDroppedTxsNotify registers a subscription of DroppedTxsNotify and
+// starts sending event to the given channel.
+func (pool *TxPool) SubscribeDroppedTxsNotify(ch chan<- DroppedTxsNotify) notify.Subscription {
+	return pool.scope.Track(pool.dropped.feed.Subscribe(ch))
+}
+
 // SubscribeNewTxsNotify registers a subscription of NewTxsNotify and
 // starts sending event to the given channel.
 func (pool *TxPool) SubscribeNewTxsNotify(ch chan<- NewTxsNotify) notify.Subscription {
@@ -443,6 +531,7 @@ func (pool *TxPool) SetGasPrice(price *big.Int) {
 		drop := pool.all.RemotesBelowTip(price)
 		for _, tx := range drop {
 			pool.removeTx(tx.Hash(), false)
+			pool.recordDropped(tx, TxDropUnderpriced)
 		}
 		pool.priced.Removed(len(drop))
 	}
@@ -594,6 +683,24 @@ func (pool *TxPool) local() map[common.Address]types.Transactions {
 	return txs
 }
 
+// remote retrieves all currently known remote transactions, grouped by origin
+// account and sorted by nonce. The returned transaction set is a copy and can be
+// freely modified by calling code.
+func (pool *TxPool) remote() map[common.Address]types.Transactions {
+	txs := make(map[common.Address]types.Transactions)
+	for addr, pending := range pool.pending {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], pending.Flatten()...)
+		}
+	}
+	for addr, queued := range pool.queue {
+		if !pool.locals.contains(addr) {
+			txs[addr] = append(txs[addr], queued.Flatten()...)
+		}
+	}
+	return txs
+}
+
 // validateTx checks whether a transaction is valid according to the consensus
 // rules and adheres to some heuristic limits of the local node (price and size).
 func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
@@ -668,6 +775,30 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 	return nil
 }
 
+// checkPolicy applies the admission policy to a validated transaction and records the decision.
+func (pool *TxPool) checkPolicy(tx *types.Transaction, local bool) (TxPolicyInput, TxPolicyDecision) {
+	if pool.policy == nil {
+		return TxPolicyInput{}, TxPolicyDecision{Action: TxPolicyAllow}
+	}
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	in := TxPolicyInput{
+		Tx:         tx,
+		From:       from,
+		ToContract: tx.To() != nil && pool.currentState.GetCodeSize(*tx.To()) != 0,
+		Local:      local,
+	}
+	decision := pool.policy.Check(in)
+	pool.policyStats.record(in, decision)
+	return in, decision
+}
+
+// policyAdmitted notifies the admission policy that the checked transaction is added to the pool.
+func (pool *TxPool) policyAdmitted(in TxPolicyInput) {
+	if pool.policy != nil && in.Tx != nil {
+		pool.policy.Admitted(in)
+	}
+}
+
 // add validates a transaction and inserts it into the non-executable queue for later
 // pending promotion and execution. If the transaction is a replacement for an already
 // pending or queued one, it overwrites the previous transaction if its price is higher.
@@ -692,8 +823,20 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
+	// Apply the admission policy
+	policyIn, decision := pool.checkPolicy(tx, isLocal)
+	if decision.Action != TxPolicyAllow && decision.Action != TxPolicyDeprioritise {
+		log.Trace("Discarding transaction denied by policy", "hash", hash, "rule", decision.Rule)
+		return false, fmt.Errorf("%w: %s", ErrTxPolicyDenied, decision.Rule)
+	}
 	// If the transaction pool is full, discard underpriced transactions
 	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
+		// Deprioritised transactions never displace others
+		if decision.Action == TxPolicyDeprioritise {
+			log.Trace("Discarding deprioritised transaction", "hash", hash, "rule", decision.Rule)
+			overflowedTxMeter.Mark(1)
+			return false, ErrTxPoolOverflow
+		}
 		// If the new transaction is underpriced, don't accept it
 		if !isLocal && pool.priced.Underpriced(tx) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
@@ -716,6 +859,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			log.Trace("Discarding freshly underpriced transaction", "hash", tx.Hash(), "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
 			underpricedTxMeter.Mark(1)
 			pool.removeTx(tx.Hash(), false)
+			pool.recordDropped(tx, TxDropUnderpriced)
 		}
 	}
 	// Try to replace an existing transaction in the pending pool
@@ -732,6 +876,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 			pool.all.Remove(old.Hash())
 			pool.priced.Removed(1)
 			pendingReplaceMeter.Mark(1)
+			pool.recordDropped(old, TxDropReplaced)
 		}
 		pool.all.Add(tx, isLocal)
 		pool.priced.Put(tx, isLocal)
@@ -741,6 +886,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 
 		// Successful promotion, bump the heartbeat
 		pool.beats[from] = time.Now()
+		pool.policyAdmitted(policyIn)
 		return old != nil, nil
 	}
 	// New transaction isn't replacing a pending one, push into queue
@@ -758,6 +904,7 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		localGauge.Inc(1)
 	}
 	pool.journalTx(from, tx)
+	pool.policyAdmitted(policyIn)
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replaced, nil
@@ -783,6 +930,7 @@ func (pool *TxPool) enqueueTx(hash common.Hash, tx *types.Transaction, local boo
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		queuedReplaceMeter.Mark(1)
+		pool.recordDropped(old, TxDropReplaced)
 	} else {
 		// Nothing was replaced, bump the queued counter
 		queuedGauge.Inc(1)
@@ -815,6 +963,19 @@ func (pool *TxPool) journalTx(from common.Address, tx *types.Transaction) {
 	}
 }
 
+// recordDropped adds the transaction to the history of dropped transactions.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) recordDropped(tx *types.Transaction, reason TxDropReason) {
+	from, _ := types.Sender(pool.signer, tx) // already validated
+	pool.dropped.record(&DroppedTx{
+		Tx:     tx,
+		From:   from,
+		Reason: reason,
+		Time:   time.Now(),
+	})
+}
+
 // promoteTx adds a transaction to the pending (processable) list of transactions
 // and returns whether it was inserted or an older was better.
 //
@@ -832,6 +993,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(hash)
 		pool.priced.Removed(1)
 		pendingDiscardMeter.Mark(1)
+		pool.recordDropped(tx, TxDropReplaced)
 		return false
 	}
 	// Otherwise discard any previous transaction and mark this
@@ -839,6 +1001,7 @@ func (pool *TxPool) promoteTx(addr common.Address, hash common.Hash, tx *types.T
 		pool.all.Remove(old.Hash())
 		pool.priced.Removed(1)
 		pendingReplaceMeter.Mark(1)
+		pool.recordDropped(old, TxDropReplaced)
 	} else {
 		// Nothing was replaced, bump the pending counter
 		pendingGauge.Inc(1)
@@ -1238,6 +1401,11 @@ func (pool *TxPool) reset(oldHead, newHead *EvmHeader) {
 
 	// If we're reorging an old state, reinject all dropped transactions
 	var reinject types.Transactions
+	// Remember the transactions of the new blocks to tell the mined queued transactions
+	// from the dropped ones without relying on the tx index (Opera-specific)
+	var included types.Transactions
+	minedKnown := true
+	pool.mined = nil
 
 	if oldHead != nil && oldHead.Hash != newHead.ParentHash {
 		// If the reorg is too deep, avoid doing it (will happen during fast sync)
@@ -1246,9 +1414,10 @@ func (pool *TxPool) reset(oldHead, newHead *EvmHeader) {
 
 		if depth := uint64(math.Abs(float64(oldNum) - float64(newNum))); depth > 64 {
 			log.Debug("Skipping deep transaction reorg", "depth", depth)
+			minedKnown = false
 		} else {
 			// Reorg seems shallow enough to pull in all transactions into memory
-			var discarded, included types.Transactions
+			var discarded types.Transactions
 			var (
 				rem = pool.chain.GetBlock(oldHead.Hash, oldHead.Number.Uint64())
 				add = pool.chain.GetBlock(newHead.Hash, newHead.Number.Uint64())
@@ -1298,6 +1467,13 @@ func (pool *TxPool) reset(oldHead, newHead *EvmHeader) {
 				reinject = types.TxDifference(discarded, included)
 			}
 		}
+	} else if oldHead != nil {
+		// The new head is the next block
+		if add := pool.chain.GetBlock(newHead.Hash, newHead.Number.Uint64()); add != nil {
+			included = add.Transactions
+		} else {
+			minedKnown = false
+		}
 	}
 	// Initialize the internal state to the current head
 	if newHead == nil {
@@ -1315,6 +1491,12 @@ func (pool *TxPool) reset(oldHead, newHead *EvmHeader) {
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = pool.chain.MaxGasLimit()
+	if minedKnown {
+		pool.mined = make(map[common.Hash]struct{}, len(included))
+		for _, tx := range included {
+			pool.mined[tx.Hash()] = struct{}{}
+		}
+	}
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
@@ -1346,6 +1528,10 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		for _, tx := range forwards {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			// the mined transactions aren't dropped, the outcome is unknown after a deep reset
+			if _, mined := pool.mined[hash]; pool.mined != nil && !mined {
+				pool.recordDropped(tx, TxDropNonceTooLow)
+			}
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
@@ -1353,6 +1539,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
+			pool.recordDropped(tx, TxDropUnpayable)
 		}
 		log.Trace("Removed unpayable queued transactions", "count", len(drops))
 		queuedNofundsMeter.Mark(int64(len(drops)))
@@ -1375,6 +1562,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 			for _, tx := range caps {
 				hash := tx.Hash()
 				pool.all.Remove(hash)
+				pool.recordDropped(tx, TxDropQueueLimit)
 				log.Trace("Removed cap-exceeding queued transaction", "hash", hash)
 			}
 			queuedRateLimitMeter.Mark(int64(len(caps)))
@@ -1437,6 +1625,7 @@ func (pool *TxPool) truncatePending() {
 						// Drop the transaction from the global pools too
 						hash := tx.Hash()
 						pool.all.Remove(hash)
+						pool.recordDropped(tx, TxDropPendingLimit)
 
 						// Update the account nonce to the dropped transaction
 						pool.pendingNonces.setIfLower(offenders[i], tx.Nonce())
@@ -1464,6 +1653,7 @@ func (pool *TxPool) truncatePending() {
 					// Drop the transaction from the global pools too
 					hash := tx.Hash()
 					pool.all.Remove(hash)
+					pool.recordDropped(tx, TxDropPendingLimit)
 
 					// Update the account nonce to the dropped transaction
 					pool.pendingNonces.setIfLower(addr, tx.Nonce())
@@ -1511,6 +1701,7 @@ func (pool *TxPool) truncateQueue() {
 		if size := uint64(list.Len()); size <= drop {
 			for _, tx := range list.Flatten() {
 				pool.removeTx(tx.Hash(), true)
+				pool.recordDropped(tx, TxDropQueueLimit)
 			}
 			drop -= size
 			queuedRateLimitMeter.Mark(int64(size))
@@ -1520,6 +1711,7 @@ func (pool *TxPool) truncateQueue() {
 		txs := list.Flatten()
 		for i := len(txs) - 1; i >= 0 && drop > 0; i-- {
 			pool.removeTx(txs[i].Hash(), true)
+			pool.recordDropped(txs[i], TxDropQueueLimit)
 			drop--
 			queuedRateLimitMeter.Mark(1)
 		}
@@ -1551,6 +1743,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
 			pool.all.Remove(hash)
+			pool.recordDropped(tx, TxDropUnpayable)
 		}
 		pendingNofundsMeter.Mark(int64(len(drops)))
 
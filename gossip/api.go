@@ -3,6 +3,8 @@ package gossip
 import (
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
+
+	"github.com/Fantom-foundation/go-opera/inter"
 )
 
 // PublicEthereumAPI provides an API to access Ethereum-like information.
@@ -35,3 +37,31 @@ func (api *PublicEthereumAPI) Hashrate() hexutil.Uint64 {
 func (api *PublicEthereumAPI) ChainId() hexutil.Uint64 {
 	return hexutil.Uint64(api.s.store.GetRules().EvmChainConfig().ChainID.Uint64())
 }
+
+// PublicShadowEmitterAPI provides the events computed by the emitters in shadow mode.
+type PublicShadowEmitterAPI struct {
+	s *Service
+}
+
+// NewPublicShadowEmitterAPI creates a new shadow emitter API.
+func NewPublicShadowEmitterAPI(s *Service) *PublicShadowEmitterAPI {
+	return &PublicShadowEmitterAPI{s}
+}
+
+// ShadowEvents returns the most recent events which would have been emitted in shadow mode, the oldest first.
+// The events are neither signed nor broadcast.
+func (api *PublicShadowEmitterAPI) ShadowEvents() []map[string]interface{} {
+	res := make([]map[string]interface{}, 0)
+	for _, em := range api.s.emitters {
+		for _, e := range em.ShadowEvents() {
+			fields := inter.RPCMarshalEvent(e)
+			txs := make([]common.Hash, e.Txs().Len())
+			for i, tx := range e.Txs() {
+				txs[i] = tx.Hash()
+			}
+			fields["transactions"] = txs
+			res = append(res, fields)
+		}
+	}
+	return res
+}
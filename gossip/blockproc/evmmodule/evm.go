@@ -14,21 +14,35 @@ import (
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 	"github.com/Fantom-foundation/go-opera/utils"
 )
 
-type EVMModule struct{}
+type EVMModule struct {
+	callTraces bool
+}
 
 func New() *EVMModule {
 	return &EVMModule{}
 }
 
+// WithCallTraces enables collecting of flat call traces of the executed txs.
+func (m *EVMModule) WithCallTraces() *EVMModule {
+	m.callTraces = true
+	return m
+}
+
 func (p *EVMModule) Start(block iblockproc.BlockCtx, statedb *state.StateDB, reader evmcore.DummyChain, onNewLog func(*types.Log), net opera.Rules) blockproc.EVMProcessor {
 	var prevBlockHash common.Hash
 	if block.Idx != 0 {
 		prevBlockHash = reader.GetHeader(common.Hash{}, uint64(block.Idx-1)).Hash
 	}
+	var tracer *txtrace.CallTracer
+	if p.callTraces {
+		tracer = txtrace.NewCallTracer()
+	}
 	return &OperaEVMProcessor{
+		tracer:        tracer,
 		block:         block,
 		reader:        reader,
 		statedb:       statedb,
@@ -45,6 +59,7 @@ type OperaEVMProcessor struct {
 	statedb  *state.StateDB
 	onNewLog func(*types.Log)
 	net      opera.Rules
+	tracer   *txtrace.CallTracer
 
 	blockIdx      *big.Int
 	prevBlockHash common.Hash
@@ -54,6 +69,7 @@ type OperaEVMProcessor struct {
 	incomingTxs types.Transactions
 	skippedTxs  []uint32
 	receipts    types.Receipts
+	traces      [][]txtrace.ActionTrace
 }
 
 func (p *OperaEVMProcessor) evmBlockWith(txs types.Transactions) *evmcore.EvmBlock {
@@ -77,12 +93,17 @@ func (p *OperaEVMProcessor) evmBlockWith(txs types.Transactions) *evmcore.EvmBlo
 }
 
 func (p *OperaEVMProcessor) Execute(txs types.Transactions) types.Receipts {
-	evmProcessor := evmcore.NewStateProcessor(p.net.EvmChainConfig(), p.reader)
 	txsOffset := uint(len(p.incomingTxs))
+	evmProcessor := evmcore.NewStateProcessor(p.net.EvmChainConfig(), p.reader).WithBlockCtx(p.block).WithTxOffset(txsOffset)
 
 	// Process txs
 	evmBlock := p.evmBlockWith(txs)
-	receipts, _, skipped, err := evmProcessor.Process(evmBlock, p.statedb, opera.DefaultVMConfig, &p.gasUsed, func(l *types.Log, _ *state.StateDB) {
+	vmConfig := opera.DefaultVMConfig
+	if p.tracer != nil {
+		vmConfig.Debug = true
+		vmConfig.Tracer = p.tracer
+	}
+	receipts, _, skipped, err := evmProcessor.Process(evmBlock, p.statedb, vmConfig, &p.gasUsed, func(l *types.Log, _ *state.StateDB) {
 		// Note: l.Index is properly set before
 		l.TxIndex += txsOffset
 		p.onNewLog(l)
@@ -103,6 +124,17 @@ func (p *OperaEVMProcessor) Execute(txs types.Transactions) types.Receipts {
 	p.incomingTxs = append(p.incomingTxs, txs...)
 	p.skippedTxs = append(p.skippedTxs, skipped...)
 	p.receipts = append(p.receipts, receipts...)
+	if p.tracer != nil {
+		// every not skipped tx is traced exactly once
+		traces := p.tracer.Take()
+		if len(traces) == len(receipts) {
+			p.traces = append(p.traces, traces...)
+		} else {
+			// leave the block not indexed rather than index wrong traces
+			log.Warn("Call traces mismatch receipts", "block", p.block.Idx, "traces", len(traces), "receipts", len(receipts))
+			p.tracer = nil
+		}
+	}
 
 	return receipts
 }
@@ -124,3 +156,13 @@ func (p *OperaEVMProcessor) Finalize() (evmBlock *evmcore.EvmBlock, skippedTxs [
 
 	return
 }
+
+func (p *OperaEVMProcessor) CallTraces() [][]txtrace.ActionTrace {
+	if p.tracer == nil {
+		return nil
+	}
+	if p.traces == nil {
+		return [][]txtrace.ActionTrace{}
+	}
+	return p.traces
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/inter"
 	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
 	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 )
 
 type TxListener interface {
@@ -48,6 +49,8 @@ type ConfirmedEventsModule interface {
 type EVMProcessor interface {
 	Execute(txs types.Transactions) types.Receipts
 	Finalize() (evmBlock *evmcore.EvmBlock, skippedTxs []uint32, receipts types.Receipts)
+	// CallTraces returns flat call traces of the not skipped txs, or nil if call tracing is disabled
+	CallTraces() [][]txtrace.ActionTrace
 }
 
 type EVM interface {
@@ -0,0 +1,148 @@
+package gossip
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/utils"
+)
+
+func transferArgs(from, to common.Address, gas uint64, amount *big.Int) ethapi.TransactionArgs {
+	return ethapi.TransactionArgs{
+		From:  &from,
+		To:    &to,
+		Gas:   (*hexutil.Uint64)(&gas),
+		Value: (*hexutil.Big)(amount),
+	}
+}
+
+func TestSimulateBundle(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	api := ethapi.NewPublicBlockChainAPI(env.EthAPI)
+	ctx := context.Background()
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, big.NewInt(100)))
+	require.NoError(err)
+
+	fresh := common.Address{0xc0}
+	amount := utils.ToFtm(1)
+
+	// the transfer from an empty account fails alone
+	results, err := api.SimulateBundle(ctx, []ethapi.TransactionArgs{
+		transferArgs(fresh, env.Address(2), 21000, amount),
+	}, latest, nil, nil)
+	require.NoError(err)
+	require.Len(results, 1)
+	require.NotEmpty(results[0].Error)
+
+	// the second transaction sees the state of the first one
+	results, err = api.SimulateBundle(ctx, []ethapi.TransactionArgs{
+		transferArgs(env.Address(1), fresh, 21000, amount),
+		transferArgs(fresh, env.Address(2), 21000, amount),
+	}, latest, nil, nil)
+	require.NoError(err)
+	require.Len(results, 2)
+	for _, res := range results {
+		require.Empty(res.Error)
+		require.Equal(hexutil.Uint64(21000), res.GasUsed)
+		require.Contains(res.StateDiff, fresh)
+	}
+	require.Contains(results[0].StateDiff, env.Address(1))
+	require.Contains(results[1].StateDiff, env.Address(2))
+
+	// the state isn't changed
+	require.Equal(0, env.State().GetBalance(fresh).Sign())
+}
+
+func TestSimulateBundleBlockOverrides(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	api := ethapi.NewPublicBlockChainAPI(env.EthAPI)
+	ctx := context.Background()
+
+	header := &evmcore.EvmHeader{
+		Number:  big.NewInt(1),
+		Time:    inter.FromUnix(1000),
+		BaseFee: big.NewInt(10),
+	}
+	var overrides *ethapi.BlockOverrides
+	require.Equal(header, overrides.Apply(header))
+	overrides = &ethapi.BlockOverrides{}
+	require.Equal(header, overrides.Apply(header))
+
+	number, time, baseFee := hexutil.Big(*big.NewInt(100)), hexutil.Uint64(2000), hexutil.Big(*big.NewInt(20))
+	overrides = &ethapi.BlockOverrides{
+		Number:  &number,
+		Time:    &time,
+		BaseFee: &baseFee,
+	}
+	overridden := overrides.Apply(header)
+	require.Equal(big.NewInt(100), overridden.Number)
+	require.Equal(inter.FromUnix(2000), overridden.Time)
+	require.Equal(big.NewInt(20), overridden.BaseFee)
+	// the original header isn't changed
+	require.Equal(big.NewInt(1), header.Number)
+	require.Equal(inter.FromUnix(1000), header.Time)
+	require.Equal(big.NewInt(10), header.BaseFee)
+
+	// the EVM observes the overridden fields:
+	// NUMBER PUSH1 0 MSTORE TIMESTAMP PUSH1 32 MSTORE PUSH1 64 PUSH1 0 RETURN
+	from := env.Address(1)
+	code := hexutil.Bytes(common.FromHex("0x436000524260205260406000f3"))
+	results, err := api.SimulateBundle(ctx, []ethapi.TransactionArgs{{
+		From: &from,
+		Data: &code,
+	}}, rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber), nil, overrides)
+	require.NoError(err)
+	require.Len(results, 1)
+	require.Empty(results[0].Error)
+	require.Len(results[0].ReturnValue, 64)
+	require.Equal(common.BigToHash(big.NewInt(100)).Bytes(), []byte(results[0].ReturnValue[:32]))
+	require.Equal(common.BigToHash(big.NewInt(2000)).Bytes(), []byte(results[0].ReturnValue[32:]))
+}
+
+func TestSimulateBundleGasPool(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	api := ethapi.NewPublicBlockChainAPI(env.EthAPI)
+	ctx := context.Background()
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+
+	// the whole bundle is limited by the gas cap, the unused gas is returned to the pool
+	env.config.RPCGasCap = 50000
+	txs := []ethapi.TransactionArgs{
+		transferArgs(env.Address(1), env.Address(2), 25000, big.NewInt(1)),
+		transferArgs(env.Address(1), env.Address(2), 25000, big.NewInt(1)),
+	}
+	results, err := api.SimulateBundle(ctx, txs, latest, nil, nil)
+	require.NoError(err)
+	require.Len(results, 2)
+
+	// the gas pool runs out in the middle of the bundle
+	txs = []ethapi.TransactionArgs{
+		transferArgs(env.Address(1), env.Address(2), 30000, big.NewInt(1)),
+		transferArgs(env.Address(1), env.Address(2), 30000, big.NewInt(1)),
+	}
+	_, err = api.SimulateBundle(ctx, txs, latest, nil, nil)
+	require.Error(err)
+	require.True(errors.Is(err, evmcore.ErrGasLimitReached))
+	require.Contains(err.Error(), "tx 1:")
+}
@@ -363,9 +363,11 @@ func consensusCallbackBeginBlockFn(
 						// Note: it's possible for receipts to get indexed twice by BR and block processing
 						if allReceipts.Len() != 0 {
 							store.evm.SetReceipts(blockCtx.Idx, allReceipts)
-							for _, r := range allReceipts {
-								store.evm.IndexLogs(r.Logs...)
-							}
+							store.evm.IndexReceiptsLogs(allReceipts)
+						}
+						// Index call traces
+						if traces := evmProcessor.CallTraces(); traces != nil {
+							store.evm.SetCallTraces(blockCtx.Idx, traces)
 						}
 					}
 					for _, tx := range append(preInternalTxs, internalTxs...) {
@@ -68,6 +68,38 @@ func (s *Service) buildEvent(e *inter.MutableEventPayload, onIndexed func()) err
 	return s.engine.Build(e)
 }
 
+// buildShadowEvent is a version of buildEvent for the shadow emitter,
+// which doesn't touch the DAG index and the consensus engine of the live node
+func (s *Service) buildShadowEvent(e *inter.MutableEventPayload) error {
+	// set some unique ID
+	e.SetID(s.uniqueEventIDs.sample())
+
+	// set PrevEpochHash
+	if e.Lamport() <= 1 {
+		prevEpochHash := s.store.GetEpochState().Hash()
+		e.SetPrevEpochHash(&prevEpochHash)
+	}
+
+	// the median time isn't known without indexing, the creation time is its upper bound
+	e.SetMedianTime(e.CreationTime())
+
+	// calc initial GasPower
+	e.SetGasPowerUsed(epochcheck.CalcGasPowerUsed(e, s.store.GetRules()))
+	var selfParent *inter.Event
+	if e.SelfParent() != nil {
+		selfParent = s.store.GetEvent(*e.SelfParent())
+	}
+	availableGasPower, err := s.checkers.Gaspowercheck.CalcGasPower(e, selfParent)
+	if err != nil {
+		return err
+	}
+	if e.GasPowerUsed() > availableGasPower.Min() {
+		return emitter.ErrNotEnoughGasPower
+	}
+	e.SetGasPowerLeft(availableGasPower.Sub(e.GasPowerUsed()))
+	return nil
+}
+
 // processSavedEvent performs processing which depends on event being saved in DB
 func (s *Service) processSavedEvent(e *inter.EventPayload, es *iblockproc.EpochState) error {
 	err := s.dagIndexer.Add(e)
@@ -173,6 +205,13 @@ func (s *Service) EvmSnapshotGeneration() bool {
 	return gen
 }
 
+// CurrentEpoch returns the current epoch, all the previous epochs are sealed and committed
+func (s *Service) CurrentEpoch() idx.Epoch {
+	s.engineMu.RLock()
+	defer s.engineMu.RUnlock()
+	return s.store.GetEpoch()
+}
+
 // processEvent extends the engine.Process with gossip-specific actions on each event processing
 func (s *Service) processEvent(e *inter.EventPayload) error {
 	// s.engineMu is locked here
@@ -239,6 +278,7 @@ func (s *Service) processEvent(e *inter.EventPayload) error {
 	for _, em := range s.emitters {
 		em.OnEventConnected(e)
 	}
+	s.newEvents.Push(e)
 
 	if newEpoch != oldEpoch {
 		s.switchEpochTo(newEpoch)
@@ -100,9 +100,7 @@ func (s *Service) ProcessBlockVotes(bvs inter.LlrSignedBlockVotes) error {
 func indexRawReceipts(s *Store, receiptsForStorage []*types.ReceiptForStorage, txs types.Transactions, blockIdx idx.Block, atropos hash.Event) {
 	s.evm.SetRawReceipts(blockIdx, receiptsForStorage)
 	receipts, _ := evmstore.UnwrapStorageReceipts(receiptsForStorage, blockIdx, nil, common.Hash(atropos), txs)
-	for _, r := range receipts {
-		s.evm.IndexLogs(r.Logs...)
-	}
+	s.evm.IndexReceiptsLogs(receipts)
 }
 
 func (s *Store) WriteFullBlockRecord(br ibr.LlrIdxFullBlockRecord) {
@@ -141,6 +139,10 @@ func (s *Service) ProcessFullBlockRecord(br ibr.LlrIdxFullBlockRecord) error {
 	if s.store.HasBlock(br.Idx) {
 		return eventcheck.ErrAlreadyProcessedBR
 	}
+	if s.config.RecordSubstates {
+		// block records are written without EVM execution, so blocks are processed from events instead
+		return eventcheck.ErrRejectedBR
+	}
 	done := s.procLogger.BlockRecordConnectionStarted(br)
 	defer done()
 	res := s.store.GetLlrBlockResult(br.Idx)
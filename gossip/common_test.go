@@ -195,6 +195,7 @@ func newTestEnv(firstEpoch idx.Epoch, validatorsNum idx.Validator) *testEnv {
 
 	_ = env.store.GenerateSnapshotAt(common.Hash(store.GetBlockState().FinalizedStateRoot), false)
 	env.blockProcTasks.Start(1)
+	env.newEvents.Start()
 	env.verWatcher.Start()
 
 	return env
@@ -202,6 +203,8 @@ func newTestEnv(firstEpoch idx.Epoch, validatorsNum idx.Validator) *testEnv {
 
 func (env *testEnv) Close() {
 	env.verWatcher.Stop()
+	env.feed.scope.Close()
+	env.newEvents.Stop()
 	env.store.Close()
 	env.tflusher.Stop()
 }
@@ -13,6 +13,7 @@ import (
 	"github.com/syndtr/goleveldb/leveldb/opt"
 
 	"github.com/Fantom-foundation/go-opera/eventcheck/heavycheck"
+	"github.com/Fantom-foundation/go-opera/evmcore"
 	"github.com/Fantom-foundation/go-opera/gossip/evmstore"
 	"github.com/Fantom-foundation/go-opera/gossip/filters"
 	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
@@ -80,7 +81,8 @@ type (
 
 		AllowSnapsync bool
 
-		TxIndex bool // Whether to enable indexing transactions and receipts or not
+		TxIndex    bool // Whether to enable indexing transactions and receipts or not
+		TraceIndex bool // Whether to enable indexing flat call traces or not (requires TxIndex)
 
 		// Protocol options
 		Protocol ProtocolConfig
@@ -97,12 +99,26 @@ type (
 		// send-transction variants. The unit is ether.
 		RPCTxFeeCap float64 `toml:",omitempty"`
 
+		// RPCReceiptsRangeCap is the max number of blocks in ftm_getReceiptsRange.
+		RPCReceiptsRangeCap uint64 `toml:",omitempty"`
+
+		// PrivateTxEpochs is the number of epochs after which transactions submitted
+		// via eth_sendPrivateTransaction fall back to public gossip.
+		PrivateTxEpochs idx.Epoch
+
 		// allows only for EIP155 transactions.
 		AllowUnprotectedTxs bool
 
 		ExtRPCEnabled bool
 
 		RPCBlockExt bool
+
+		// RecordSubstates enables recording of transaction substates for every processed block
+		RecordSubstates bool
+		// SubstateMetaDir is a directory for Opera-specific metadata of recorded substates (disabled if empty)
+		SubstateMetaDir string
+		// SubstateFilter limits which transactions of processed blocks are recorded
+		SubstateFilter evmcore.SubstateFilter
 	}
 
 	StoreCacheConfig struct {
@@ -209,6 +225,10 @@ func DefaultConfig(scale cachescale.Func) Config {
 
 		RPCGasCap:   50000000,
 		RPCTxFeeCap: 100, // 100 FTM
+
+		RPCReceiptsRangeCap: 1000,
+
+		PrivateTxEpochs: 2,
 	}
 	sessionCfg := cfg.Protocol.DagStreamLeecher.Session
 	cfg.Protocol.DagProcessor.EventsBufferLimit.Num = idx.Event(sessionCfg.ParallelChunksDownload)*
@@ -0,0 +1,189 @@
+package gossip
+
+import (
+	"context"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+func dagSubscribe(t *testing.T, client *rpc.Client, name string) (chan map[string]interface{}, *rpc.ClientSubscription) {
+	ch := make(chan map[string]interface{}, 1000)
+	sub, err := client.Subscribe(context.Background(), "dag", ch, name)
+	require.NoError(t, err)
+	return ch, sub
+}
+
+func TestDAGSubscriptions(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+
+	server := rpc.NewServer()
+	defer server.Stop()
+	require.NoError(server.RegisterName("dag", ethapi.NewPublicDAGChainAPI(env.EthAPI)))
+	client := rpc.DialInProc(server)
+	defer client.Close()
+
+	events, eventsSub := dagSubscribe(t, client, "newEvents")
+	defer eventsSub.Unsubscribe()
+	blocks, blocksSub := dagSubscribe(t, client, "newBlock")
+	defer blocksSub.Unsubscribe()
+	epochs, epochsSub := dagSubscribe(t, client, "newEpoch")
+	defer epochsSub.Unsubscribe()
+
+	// seal the epoch
+	startEpoch := env.store.GetEpoch()
+	env.t = env.t.Add(nextEpoch)
+	require.NoError(env.EmitUntil(func() bool {
+		return len(epochs) != 0 && len(blocks) != 0 && len(events) != 0
+	}))
+
+	// connected events
+	for len(events) != 0 {
+		e := <-events
+		id, err := hexutil.Decode(e["id"].(string))
+		require.NoError(err)
+		require.True(env.store.HasEvent(hash.BytesToEvent(id)))
+	}
+
+	// decided blocks
+	for len(blocks) != 0 {
+		b := <-blocks
+		number, err := hexutil.DecodeUint64(b["number"].(string))
+		require.NoError(err)
+		block := env.store.GetBlock(idx.Block(number))
+		require.NotNil(block)
+		atropos, err := hexutil.Decode(b["atropos"].(string))
+		require.NoError(err)
+		require.Equal(block.Atropos, hash.BytesToEvent(atropos))
+		require.Len(b["events"], len(block.Events))
+	}
+
+	// started epochs
+	e := <-epochs
+	epoch, err := hexutil.DecodeUint64(e["epoch"].(string))
+	require.NoError(err)
+	require.Equal(uint64(startEpoch+1), epoch)
+	require.Len(e["validators"], 3)
+	require.NotEmpty(e["rules"])
+}
+
+func TestDAGSubscriptionsSlowSubscriber(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+
+	// a subscriber which never reads doesn't block events processing
+	sub := env.feed.SubscribeNewEvent(make(chan *inter.EventPayload))
+	defer sub.Unsubscribe()
+
+	start := env.store.GetLatestBlockIndex()
+	require.NoError(env.EmitUntil(func() bool {
+		return env.store.GetLatestBlockIndex() >= start+3
+	}))
+}
+
+func TestDAGGetEvents(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	api := ethapi.NewPublicDAGChainAPI(env.EthAPI)
+	ctx := context.Background()
+
+	startEpoch := env.store.GetEpoch()
+	start := env.store.GetLatestBlockIndex()
+	require.NoError(env.EmitUntil(func() bool {
+		return env.store.GetLatestBlockIndex() >= start+3
+	}))
+	epoch := rpc.BlockNumber(startEpoch)
+
+	var expected hash.Events
+	env.store.ForEachEpochEvent(startEpoch, func(e *inter.EventPayload) bool {
+		expected = append(expected, e.ID())
+		return true
+	})
+	require.Greater(len(expected), 4)
+
+	// pages through the epoch events
+	getAll := func(args ethapi.EventsFilterArgs) (hash.Events, int) {
+		var (
+			got   hash.Events
+			pages int
+		)
+		for {
+			res, err := api.GetEvents(ctx, epoch, args)
+			require.NoError(err)
+			pages++
+			events := res["events"].([]map[string]interface{})
+			require.LessOrEqual(len(events), int(*args.Limit))
+			for _, e := range events {
+				got = append(got, hash.BytesToEvent(e["id"].(hexutil.Bytes)))
+			}
+			if res["cursor"] == nil {
+				return got, pages
+			}
+			cursor := res["cursor"].(hexutil.Bytes)
+			require.Equal(got[len(got)-1].Bytes(), []byte(cursor))
+			args.Cursor = &cursor
+		}
+	}
+	limit := hexutil.Uint64(2)
+	got, pages := getAll(ethapi.EventsFilterArgs{Limit: &limit})
+	require.Equal(expected, got)
+	require.Equal(len(expected)/2+1, pages)
+	for i := 1; i < len(got); i++ {
+		require.LessOrEqual(uint32(got[i-1].Lamport()), uint32(got[i].Lamport()))
+	}
+
+	// filters by creator
+	creator := hexutil.Uint64(env.store.GetValidators().GetID(0))
+	got, _ = getAll(ethapi.EventsFilterArgs{Limit: &limit, Creator: &creator})
+	require.NotEmpty(got)
+	var expectedByCreator hash.Events
+	for _, id := range expected {
+		if env.store.GetEvent(id).Creator() == idx.ValidatorID(creator) {
+			expectedByCreator = append(expectedByCreator, id)
+		}
+	}
+	require.Equal(expectedByCreator, got)
+
+	// filters by Lamport time range
+	from, to := hexutil.Uint64(2), hexutil.Uint64(3)
+	got, _ = getAll(ethapi.EventsFilterArgs{Limit: &limit, FromLamport: &from, ToLamport: &to})
+	require.NotEmpty(got)
+	for _, id := range got {
+		require.True(id.Lamport() >= 2 && id.Lamport() <= 3)
+	}
+
+	// cursor of another epoch is rejected
+	env.t = env.t.Add(nextEpoch)
+	require.NoError(env.EmitUntil(func() bool {
+		return env.store.GetEpoch() > startEpoch
+	}))
+	cursor := hexutil.Bytes(expected[0].Bytes())
+	_, err := api.GetEvents(ctx, epoch+1, ethapi.EventsFilterArgs{Cursor: &cursor})
+	require.Error(err)
+	require.NoError(env.EmitUntil(func() bool {
+		found := false
+		env.store.ForEachEpochEvent(startEpoch+1, func(*inter.EventPayload) bool {
+			found = true
+			return false
+		})
+		return found
+	}))
+	_, err = api.GetEvents(ctx, rpc.PendingBlockNumber, ethapi.EventsFilterArgs{Cursor: &cursor})
+	require.Error(err)
+	_, err = api.GetEvents(ctx, epoch, ethapi.EventsFilterArgs{Cursor: &cursor})
+	require.NoError(err)
+}
@@ -0,0 +1,146 @@
+package gossip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+)
+
+func isInternalTx(tx *types.Transaction) bool {
+	_, r, _ := tx.RawSignatureValues()
+	return r.Sign() == 0
+}
+
+func TestDebugTraceTransaction(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	api := ethapi.NewPrivateDebugAPI(env.EthAPI)
+	ctx := context.Background()
+
+	// use the first nonce of the sender
+	_, err := env.ApplyTxs(sameEpoch, env.Transfer(1, 2, big.NewInt(100)))
+	require.NoError(err)
+
+	// a transaction reusing the nonce is skipped, the next ones are traced
+	dup, err := types.SignTx(types.NewTransaction(0, env.Address(3), big.NewInt(1), gasLimit, env.store.GetRules().Economy.MinGasPrice, nil),
+		env.EthAPI.signer, env.privateKey(1))
+	require.NoError(err)
+	transfer := env.Transfer(1, 3, big.NewInt(100))
+	// PUSH1 0 PUSH1 0 REVERT
+	reverted := env.Contract(1, big.NewInt(0), "0x60006000fd")
+
+	// the transactions are included into the epoch sealing block, after the internal transactions
+	env.txpool.AddRemotes(types.Transactions{dup, transfer, reverted})
+	env.t = env.t.Add(nextEpoch)
+	require.NoError(env.EmitUntil(func() bool {
+		return env.store.evm.GetTxPosition(reverted.Hash()) != nil
+	}))
+	env.txpool.(*dummyTxPool).Clear()
+
+	position := env.store.evm.GetTxPosition(transfer.Hash())
+	require.NotNil(position)
+	require.Equal(position.Block, env.store.evm.GetTxPosition(reverted.Hash()).Block)
+	require.NotEmpty(env.store.GetBlock(position.Block).SkippedTxs)
+	require.Nil(env.store.evm.GetTxPosition(dup.Hash()))
+
+	block, err := env.EthAPI.BlockByNumber(ctx, rpc.BlockNumber(position.Block))
+	require.NoError(err)
+	require.True(isInternalTx(block.Transactions[0]))
+	require.Equal(transfer.Hash(), block.Transactions[position.BlockOffset].Hash())
+	receipts := env.store.evm.GetReceipts(position.Block, env.EthAPI.signer, block.Hash, block.Transactions)
+	require.Len(receipts, len(block.Transactions))
+
+	// transactions
+	for _, tx := range []*types.Transaction{transfer, reverted} {
+		i := env.store.evm.GetTxPosition(tx.Hash()).BlockOffset
+		res, err := api.TraceTransaction(ctx, tx.Hash(), nil)
+		require.NoError(err)
+		trace, ok := res.(*ethapi.ExecutionResult)
+		require.True(ok)
+		require.Equal(receipts[i].GasUsed, trace.Gas)
+		require.Equal(receipts[i].Status == types.ReceiptStatusFailed, trace.Failed)
+	}
+	require.Equal(types.ReceiptStatusFailed, receipts[env.store.evm.GetTxPosition(reverted.Hash()).BlockOffset].Status)
+	res, err := api.TraceTransaction(ctx, reverted.Hash(), nil)
+	require.NoError(err)
+	require.Len(res.(*ethapi.ExecutionResult).StructLogs, 3)
+
+	// blocks
+	byNumber, err := api.TraceBlockByNumber(ctx, rpc.BlockNumber(position.Block), nil)
+	require.NoError(err)
+	byHash, err := api.TraceBlockByHash(ctx, block.Hash, nil)
+	require.NoError(err)
+	require.Equal(byNumber, byHash)
+	require.Len(byNumber, len(block.Transactions))
+	for i, res := range byNumber {
+		require.Empty(res.Error)
+		trace := res.Result.(*ethapi.ExecutionResult)
+		require.Equal(receipts[i].GasUsed, trace.Gas, "tx %d", i)
+		require.Equal(receipts[i].Status == types.ReceiptStatusFailed, trace.Failed, "tx %d", i)
+	}
+
+	// unknown transaction
+	_, err = api.TraceTransaction(ctx, common.Hash{1}, nil)
+	require.Error(err)
+	// genesis
+	_, err = api.TraceBlockByNumber(ctx, 0, nil)
+	require.Error(err)
+}
+
+func TestDebugTraceCallTimeout(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	api := ethapi.NewPrivateDebugAPI(env.EthAPI)
+	ctx := context.Background()
+	latest := rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+	env.config.RPCGasCap = 50000000
+
+	// JUMPDEST PUSH1 0 JUMP
+	loop := common.Address{0x10}
+	code := hexutil.Bytes(common.FromHex("0x5b600056"))
+	overrides := ethapi.StateOverride{loop: {Code: &code}}
+	args := ethapi.TransactionArgs{To: &loop}
+	tracer := `{steps: 0, step: function() { this.steps++ }, fault: function() {}, result: function() { return this.steps }}`
+
+	// the tracer is stopped on timeout
+	timeout := "10ms"
+	_, err := api.TraceCall(ctx, args, latest, &ethapi.TraceCallConfig{
+		Tracer:         &tracer,
+		Timeout:        &timeout,
+		StateOverrides: &overrides,
+	})
+	require.Error(err)
+	require.Contains(err.Error(), "execution timeout")
+
+	// invalid timeout
+	invalid := "ten seconds"
+	_, err = api.TraceCall(ctx, args, latest, &ethapi.TraceCallConfig{
+		Tracer:  &tracer,
+		Timeout: &invalid,
+	})
+	require.Error(err)
+
+	// the tracer finishes in time, the loop runs out of gas
+	gas := hexutil.Uint64(100000)
+	args.Gas = &gas
+	timeout = "1m"
+	res, err := api.TraceCall(ctx, args, latest, &ethapi.TraceCallConfig{
+		Tracer:         &tracer,
+		Timeout:        &timeout,
+		StateOverrides: &overrides,
+	})
+	require.NoError(err)
+	require.NotEmpty(res)
+}
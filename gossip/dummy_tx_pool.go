@@ -14,9 +14,10 @@ import (
 
 // dummyTxPool is a fake, helper transaction pool for testing purposes
 type dummyTxPool struct {
-	txFeed notify.Feed
-	pool   []*types.Transaction        // Collection of all transactions
-	added  chan<- []*types.Transaction // Notification channel for new transactions
+	txFeed      notify.Feed
+	droppedFeed notify.Feed
+	pool        []*types.Transaction        // Collection of all transactions
+	added       chan<- []*types.Transaction // Notification channel for new transactions
 
 	signer types.Signer
 
@@ -151,6 +152,18 @@ func (p *dummyTxPool) SampleHashes(max int) []common.Hash {
 	return res
 }
 
+func (p *dummyTxPool) PolicyStats() evmcore.TxPolicyStats {
+	return evmcore.TxPolicyStats{}
+}
+
+func (p *dummyTxPool) Dropped(addr common.Address) []*evmcore.DroppedTx {
+	return nil
+}
+
+func (p *dummyTxPool) SubscribeDroppedTxsNotify(ch chan<- evmcore.DroppedTxsNotify) notify.Subscription {
+	return p.droppedFeed.Subscribe(ch)
+}
+
 func (p *dummyTxPool) Count() int {
 	return len(p.pool)
 }
@@ -36,6 +36,10 @@ type Config struct {
 
 	Validator ValidatorConfig
 
+	// Shadow mode computes the events of the validator against the live DAG without signing,
+	// processing or broadcasting them. It allows to test the emitter configuration on a non-validator node.
+	Shadow bool
+
 	EmitIntervals EmitIntervals // event emission intervals
 
 	MaxTxsPerAddress int
@@ -81,6 +81,8 @@ type Emitter struct {
 	emittedEvFile    *os.File
 	busyRate         *rate.Gauge
 
+	shadow shadowEvents
+
 	logger.Periodic
 }
 
@@ -113,6 +115,11 @@ func (em *Emitter) init() {
 	validators, epoch := em.world.GetEpochValidators()
 	em.OnNewEpoch(validators, epoch)
 
+	if em.config.Shadow {
+		// never touch the files of the real validator instance
+		em.busyRate = rate.NewGauge()
+		return
+	}
 	if len(em.config.PrevEmittedEventFile.Path) != 0 {
 		em.emittedEventFile = openPrevActionFile(em.config.PrevEmittedEventFile.Path, em.config.PrevEmittedEventFile.SyncMode)
 	}
@@ -248,6 +255,10 @@ func (em *Emitter) EmitEvent() (*inter.EventPayload, error) {
 	if e == nil || err != nil {
 		return nil, err
 	}
+	if em.config.Shadow {
+		em.emitShadowEvent(e)
+		return e, nil
+	}
 	em.syncStatus.prevLocalEmittedID = e.ID()
 
 	err = em.world.Process(e)
@@ -298,7 +309,12 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 		return nil, nil
 	}
 
-	if synced := em.logSyncStatus(em.isSyncedToEmit()); !synced {
+	if em.config.Shadow {
+		// events of the real validator instance are expected, so the doublesign protection isn't applicable
+		if !em.world.IsSynced() {
+			return nil, nil
+		}
+	} else if synced := em.logSyncStatus(em.isSyncedToEmit()); !synced {
 		// I'm reindexing my old events, so don't create events until connect all the existing self-events
 		return nil, nil
 	}
@@ -370,11 +386,18 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 
 	// set consensus fields
 	var metric ancestor.Metric
-	err := em.world.Build(mutEvent, func() {
-		// calculate event metric when it is indexed by the vector clock
-		metric = eventMetric(em.quorumIndexer.GetMetricOf(mutEvent.ID()), mutEvent.Seq())
-		metric = overheadAdjustedEventMetricF(em.validators.Len(), uint64(em.busyRate.Rate1()*piecefunc.DecimalUnit), metric)
-	})
+	var err error
+	if em.config.Shadow {
+		// shadow events aren't indexed by the vector clock, so their metric is unknown
+		metric = piecefunc.DecimalUnit
+		err = em.world.BuildShadow(mutEvent)
+	} else {
+		err = em.world.Build(mutEvent, func() {
+			// calculate event metric when it is indexed by the vector clock
+			metric = eventMetric(em.quorumIndexer.GetMetricOf(mutEvent.ID()), mutEvent.Seq())
+			metric = overheadAdjustedEventMetricF(em.validators.Len(), uint64(em.busyRate.Rate1()*piecefunc.DecimalUnit), metric)
+		})
+	}
 	if err != nil {
 		if err == ErrNotEnoughGasPower {
 			em.Periodic.Warn(time.Second, "Not enough gas power to emit event. Too small stake?",
@@ -405,6 +428,11 @@ func (em *Emitter) createEvent(sortedTxs *types.TransactionsByPriceAndNonce) (*i
 	// calc Payload hash
 	mutEvent.SetPayloadHash(inter.CalcPayloadHash(mutEvent))
 
+	// shadow events are neither signed nor checked, as the signature check would fail
+	if em.config.Shadow {
+		return mutEvent.Build(), nil
+	}
+
 	// sign
 	bSig, err := em.world.Signer.Sign(em.config.Validator.PubKey, mutEvent.HashToSign().Bytes())
 	if err != nil {
@@ -434,6 +462,12 @@ func (em *Emitter) idle() bool {
 	return em.originatedTxs.Empty()
 }
 
+// Originates returns true if the emitter signs and broadcasts the events of a validator,
+// i.e. it has a validator ID and isn't in shadow mode.
+func (em *Emitter) Originates() bool {
+	return em.config.Validator.ID != 0 && !em.config.Shadow
+}
+
 func (em *Emitter) isValidator() bool {
 	return em.config.Validator.ID != 0 && em.validators.Exists(em.config.Validator.ID)
 }
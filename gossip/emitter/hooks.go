@@ -62,7 +62,7 @@ func (em *Emitter) OnEventConnected(e inter.EventPayloadI) {
 		em.originatedTxs.Inc(addr)
 	}
 	em.pendingGas += e.GasPowerUsed()
-	if e.Creator() == em.config.Validator.ID && em.syncStatus.prevLocalEmittedID != e.ID() {
+	if e.Creator() == em.config.Validator.ID && em.syncStatus.prevLocalEmittedID != e.ID() && !em.config.Shadow {
 		// event was emitted by me on another instance
 		em.onNewExternalEvent(e)
 	}
@@ -70,6 +70,20 @@ func (mr *MockExternalMockRecorder) Build(arg0, arg1 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Build", reflect.TypeOf((*MockExternal)(nil).Build), arg0, arg1)
 }
 
+// BuildShadow mocks base method
+func (m *MockExternal) BuildShadow(arg0 *inter.MutableEventPayload) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BuildShadow", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// BuildShadow indicates an expected call of BuildShadow
+func (mr *MockExternalMockRecorder) BuildShadow(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BuildShadow", reflect.TypeOf((*MockExternal)(nil).BuildShadow), arg0)
+}
+
 // Check mocks base method
 func (m *MockExternal) Check(arg0 *inter.EventPayload, arg1 inter.Events) error {
 	m.ctrl.T.Helper()
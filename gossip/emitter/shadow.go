@@ -0,0 +1,57 @@
+package emitter
+
+import (
+	"sync"
+	"time"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+// maxShadowEvents is the number of the most recent shadow events kept for inspection.
+const maxShadowEvents = 256
+
+// shadowEvents is a buffer of the events computed in shadow mode.
+// safe for concurrent use
+type shadowEvents struct {
+	mu     sync.Mutex
+	events []*inter.EventPayload // the oldest first
+}
+
+func (s *shadowEvents) add(e *inter.EventPayload) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.events) >= maxShadowEvents {
+		s.events = append(s.events[:0], s.events[1:]...)
+	}
+	s.events = append(s.events, e)
+}
+
+func (s *shadowEvents) copy() []*inter.EventPayload {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append(make([]*inter.EventPayload, 0, len(s.events)), s.events...)
+}
+
+// emitShadowEvent records the event which would have been emitted, instead of processing and broadcasting it.
+func (em *Emitter) emitShadowEvent(e *inter.EventPayload) {
+	em.shadow.add(e)
+	em.Log.Info("Shadow event", "epoch", e.Epoch(), "seq", e.Seq(), "lamport", e.Lamport(),
+		"parents", len(e.Parents()), "txs", e.Txs().Len(), "gasUsed", e.GasPowerUsed(), "gasLeft", e.GasPowerLeft().String(),
+		"blockVotes", len(e.BlockVotes().Votes), "epochVote", e.EpochVote().Epoch)
+
+	em.prevEmittedAtTime = time.Now()
+	em.prevEmittedAtBlock = em.world.GetLatestBlockIndex()
+}
+
+// IsShadow returns true if the emitter runs in shadow mode.
+func (em *Emitter) IsShadow() bool {
+	return em.config.Shadow
+}
+
+// ShadowEvents returns the most recent events computed in shadow mode, the oldest first.
+// The events aren't signed, so their IDs are based on the unsigned data.
+func (em *Emitter) ShadowEvents() []*inter.EventPayload {
+	return em.shadow.copy()
+}
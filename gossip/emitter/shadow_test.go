@@ -0,0 +1,144 @@
+package emitter
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/gossip/emitter/mock"
+	"github.com/Fantom-foundation/go-opera/integration/makefakegenesis"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/opera"
+	"github.com/Fantom-foundation/go-opera/vecmt"
+)
+
+func TestShadowEvents(t *testing.T) {
+	require := require.New(t)
+
+	var buf shadowEvents
+	for seq := idx.Event(1); seq <= maxShadowEvents+2; seq++ {
+		e := &inter.MutableEventPayload{}
+		e.SetSeq(seq)
+		buf.add(e.Build())
+	}
+
+	events := buf.copy()
+	require.Len(events, maxShadowEvents)
+	require.Equal(idx.Event(3), events[0].Seq())
+	require.Equal(idx.Event(maxShadowEvents+2), events[len(events)-1].Seq())
+
+	// the copy isn't affected by new events
+	e := &inter.MutableEventPayload{}
+	e.SetSeq(maxShadowEvents + 3)
+	buf.add(e.Build())
+	require.Equal(idx.Event(3), events[0].Seq())
+}
+
+func TestShadowEmitter(t *testing.T) {
+	require := require.New(t)
+
+	cfg := DefaultConfig()
+	cfg.Shadow = true
+	gValidators := makefakegenesis.GetFakeValidators(1)
+	vv := pos.NewBuilder()
+	vv.Set(gValidators[0].ID, pos.Weight(1))
+	validators := vv.Build()
+	cfg.Validator.ID = gValidators[0].ID
+	rules := opera.FakeNetRules()
+
+	ctrl := gomock.NewController(t)
+	external := mock.NewMockExternal(ctrl)
+	txPool := mock.NewMockTxPool(ctrl)
+	signer := mock.NewMockSigner(ctrl)
+	txSigner := mock.NewMockTxSigner(ctrl)
+
+	// the world of the live node is only read
+	external.EXPECT().Lock().AnyTimes()
+	external.EXPECT().Unlock().AnyTimes()
+	external.EXPECT().DagIndex().Return((*vecmt.Index)(nil)).AnyTimes()
+	external.EXPECT().IsBusy().Return(false).AnyTimes()
+	external.EXPECT().IsSynced().Return(true).AnyTimes()
+	external.EXPECT().PeersNum().Return(1).AnyTimes()
+	external.EXPECT().GetRules().Return(rules).AnyTimes()
+	external.EXPECT().GetEpochValidators().Return(validators, idx.Epoch(1)).AnyTimes()
+	external.EXPECT().GetLastEvent(idx.Epoch(1), cfg.Validator.ID).Return((*hash.Event)(nil)).AnyTimes()
+	external.EXPECT().GetHeads(idx.Epoch(1)).Return(hash.Events{}).AnyTimes()
+	external.EXPECT().GetLatestBlockIndex().Return(idx.Block(1)).AnyTimes()
+	external.EXPECT().GetLowestBlockToDecide().Return(idx.Block(1)).AnyTimes()
+	external.EXPECT().GetLastBV(gomock.Any()).Return((*idx.Block)(nil)).AnyTimes()
+	external.EXPECT().GetBlockRecordHash(gomock.Any()).Return((*hash.Hash)(nil)).AnyTimes()
+	external.EXPECT().GetLowestEpochToDecide().Return(idx.Epoch(1)).AnyTimes()
+	external.EXPECT().GetLastEV(gomock.Any()).Return((*idx.Epoch)(nil)).AnyTimes()
+	external.EXPECT().GetEpochRecordHash(gomock.Any()).Return((*hash.Hash)(nil)).AnyTimes()
+	// shadow events are neither built, signed, checked, processed nor broadcast
+	external.EXPECT().Build(gomock.Any(), gomock.Any()).Times(0)
+	external.EXPECT().Check(gomock.Any(), gomock.Any()).Times(0)
+	external.EXPECT().Process(gomock.Any()).Times(0)
+	external.EXPECT().Broadcast(gomock.Any()).Times(0)
+	signer.EXPECT().Sign(gomock.Any(), gomock.Any()).Times(0)
+
+	sender := common.Address{0x01}
+	tx := types.NewTransaction(0, common.Address{0x02}, big.NewInt(1), 21000, rules.Economy.MinGasPrice, nil)
+	txPool.EXPECT().Count().Return(1).AnyTimes()
+	txPool.EXPECT().Pending(true).DoAndReturn(func(bool) (map[common.Address]types.Transactions, error) {
+		return map[common.Address]types.Transactions{sender: {tx}}, nil
+	}).AnyTimes()
+	txPool.EXPECT().Has(tx.Hash()).Return(true).AnyTimes()
+	txSigner.EXPECT().Sender(gomock.Any()).Return(sender, nil).AnyTimes()
+	txSigner.EXPECT().Equal(gomock.Any()).Return(true).AnyTimes()
+
+	em := NewEmitter(cfg, World{
+		External: external,
+		TxPool:   txPool,
+		Signer:   signer,
+		TxSigner: txSigner,
+	})
+	em.init()
+
+	const gasUsed = 1000
+	gasPower := func(left uint64) func(*inter.MutableEventPayload) error {
+		return func(e *inter.MutableEventPayload) error {
+			e.SetGasPowerUsed(gasUsed)
+			e.SetGasPowerLeft(inter.GasPowerLeft{Gas: [inter.GasPowerConfigs]uint64{left, left}})
+			return nil
+		}
+	}
+
+	// not enough gas power
+	external.EXPECT().BuildShadow(gomock.Any()).Return(ErrNotEnoughGasPower).Times(1)
+	e, err := em.EmitEvent()
+	require.NoError(err)
+	require.Nil(e)
+	require.Empty(em.ShadowEvents())
+
+	// too low gas power to originate transactions
+	external.EXPECT().BuildShadow(gomock.Any()).DoAndReturn(gasPower(cfg.NoTxsThreshold)).Times(1)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	require.Empty(e.Txs())
+	require.Equal(uint64(gasUsed), e.GasPowerUsed())
+	require.Equal([]*inter.EventPayload{e}, em.ShadowEvents())
+
+	// the transactions are originated
+	em.prevEmittedAtTime = time.Time{}
+	left := 1000 * cfg.LimitedTpsThreshold
+	external.EXPECT().BuildShadow(gomock.Any()).DoAndReturn(gasPower(left)).Times(1)
+	e, err = em.EmitEvent()
+	require.NoError(err)
+	require.NotNil(e)
+	require.Equal(types.Transactions{tx}, e.Txs())
+	require.Equal(gasUsed+tx.Gas(), e.GasPowerUsed())
+	require.Equal(left-tx.Gas(), e.GasPowerLeft().Min())
+	require.Equal(inter.Signature{}, e.Sig())
+	require.Len(em.ShadowEvents(), 2)
+	require.Equal(e, em.ShadowEvents()[1])
+}
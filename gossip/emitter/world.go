@@ -32,6 +32,8 @@ type (
 		Process(*inter.EventPayload) error
 		Broadcast(*inter.EventPayload)
 		Build(*inter.MutableEventPayload, func()) error
+		// BuildShadow sets the gas power of a shadow event without indexing it
+		BuildShadow(*inter.MutableEventPayload) error
 		DagIndex() *vecmt.Index
 
 		IsBusy() bool
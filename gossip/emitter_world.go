@@ -52,6 +52,10 @@ func (ew *emitterWorldProc) Build(e *inter.MutableEventPayload, onIndexed func()
 	return ew.s.buildEvent(e, onIndexed)
 }
 
+func (ew *emitterWorldProc) BuildShadow(e *inter.MutableEventPayload) error {
+	return ew.s.buildShadowEvent(e)
+}
+
 func (ew *emitterWorldProc) DagIndex() *vecmt.Index {
 	return ew.s.dagIndexer
 }
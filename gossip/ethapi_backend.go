@@ -17,6 +17,7 @@ import (
 	"github.com/ethereum/go-ethereum/ethdb"
 	notify "github.com/ethereum/go-ethereum/event"
 	"github.com/ethereum/go-ethereum/params"
+	"github.com/ethereum/go-ethereum/rlp"
 	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/pkg/errors"
 
@@ -28,6 +29,7 @@ import (
 	"github.com/Fantom-foundation/go-opera/opera"
 	"github.com/Fantom-foundation/go-opera/topicsdb"
 	"github.com/Fantom-foundation/go-opera/tracing"
+	"github.com/Fantom-foundation/go-opera/txtrace"
 )
 
 // EthAPIBackend implements ethapi.Backend.
@@ -244,6 +246,19 @@ func (b *EthAPIBackend) ForEachEpochEvent(ctx context.Context, epoch rpc.BlockNu
 	return nil
 }
 
+// ForEachEpochEventFrom iterates the epoch events ordered by Lamport time, starting from the Lamport time.
+func (b *EthAPIBackend) ForEachEpochEventFrom(ctx context.Context, epoch rpc.BlockNumber, lamport idx.Lamport, onEvent func(event *inter.EventPayload) bool) error {
+	requested, err := b.epochWithDefault(ctx, epoch)
+	if err != nil {
+		return err
+	}
+
+	b.svc.store.ForEachEpochEventFrom(requested, lamport, func(event *inter.EventPayload) bool {
+		return ctx.Err() == nil && onEvent(event)
+	})
+	return ctx.Err()
+}
+
 func (b *EthAPIBackend) BlockByHash(ctx context.Context, h common.Hash) (*evmcore.EvmBlock, error) {
 	index := b.svc.store.GetBlockIndex(hash.Event(h))
 	if index == nil {
@@ -265,6 +280,18 @@ func (b *EthAPIBackend) BlockByHash(ctx context.Context, h common.Hash) (*evmcor
 	return blk, nil
 }
 
+// GetBlockEvents returns IDs of the events confirmed by the block, ordered as they were processed.
+func (b *EthAPIBackend) GetBlockEvents(ctx context.Context, number rpc.BlockNumber) (hash.Events, error) {
+	if number == rpc.LatestBlockNumber || number == rpc.PendingBlockNumber {
+		number = rpc.BlockNumber(b.svc.store.GetLatestBlockIndex())
+	}
+	block := b.svc.store.GetBlock(idx.Block(number))
+	if block == nil {
+		return nil, nil
+	}
+	return block.Events, nil
+}
+
 // GetReceiptsByNumber returns receipts by block number.
 func (b *EthAPIBackend) GetReceiptsByNumber(ctx context.Context, number rpc.BlockNumber) (types.Receipts, error) {
 	if !b.svc.config.TxIndex {
@@ -284,6 +311,34 @@ func (b *EthAPIBackend) GetReceiptsByNumber(ctx context.Context, number rpc.Bloc
 	return receipts, nil
 }
 
+// CallTracesIndexed returns true if call traces of processed blocks are indexed.
+func (b *EthAPIBackend) CallTracesIndexed() bool {
+	return b.svc.config.TxIndex && b.svc.config.TraceIndex
+}
+
+// GetCallTraces returns indexed call traces of the block txs, or nil if the block isn't indexed.
+func (b *EthAPIBackend) GetCallTraces(ctx context.Context, number idx.Block) ([][]txtrace.ActionTrace, error) {
+	return b.svc.store.evm.GetCallTraces(number), nil
+}
+
+// ForEachCallTracePosition iterates over positions of the indexed txs which call traces
+// contain the address as a sender (or as a recipient).
+func (b *EthAPIBackend) ForEachCallTracePosition(ctx context.Context, addr common.Address, recipient bool, from, to idx.Block, onPosition func(txtrace.Position) bool) error {
+	if !b.CallTracesIndexed() {
+		return errors.New("call traces index is disabled (enable TraceIndex and re-process the DAGs)")
+	}
+	b.svc.store.evm.ForEachCallTracePosition(addr, recipient, from, to, onPosition)
+	return ctx.Err()
+}
+
+// GetRawReceiptsRLP returns RLP-encoded storage receipts of the block, or nil if the block has no receipts.
+func (b *EthAPIBackend) GetRawReceiptsRLP(ctx context.Context, number idx.Block) (rlp.RawValue, error) {
+	if !b.svc.config.TxIndex {
+		return nil, errors.New("transactions index is disabled (enable TxIndex and re-process the DAGs)")
+	}
+	return b.svc.store.evm.GetRawReceiptsRLP(number), nil
+}
+
 // GetReceipts retrieves the receipts for all transactions in a given block.
 func (b *EthAPIBackend) GetReceipts(ctx context.Context, block common.Hash) (types.Receipts, error) {
 	number := b.svc.store.GetBlockIndex(hash.Event(block))
@@ -331,6 +386,27 @@ func (b *EthAPIBackend) SendTx(ctx context.Context, signedTx *types.Transaction)
 	return err
 }
 
+// SendPrivateTx adds the transaction to the local pool without gossiping it to peers,
+// until it falls back to public gossip after the configured number of epochs.
+// Note, the private mark isn't persisted, so the transaction is gossiped if it's reloaded after a node restart.
+func (b *EthAPIBackend) SendPrivateTx(ctx context.Context, signedTx *types.Transaction) error {
+	// only a local validator may include the transaction, otherwise it'd be stuck until the fallback
+	if !b.svc.originatesEvents() {
+		return errNoLocalEmitter
+	}
+	// mark before adding, so the new transaction notification isn't broadcast
+	marked := b.svc.privateTxs.Add(signedTx.Hash(), b.svc.store.GetEpoch())
+	err := b.svc.txpool.AddLocal(signedTx)
+	if err != nil {
+		if marked {
+			b.svc.privateTxs.Remove(signedTx.Hash())
+		}
+		return err
+	}
+	tracing.StartTx(signedTx.Hash(), "EthAPIBackend.SendPrivateTx()")
+	return nil
+}
+
 func (b *EthAPIBackend) SubscribeLogsNotify(ch chan<- []*types.Log) notify.Subscription {
 	return b.svc.feed.SubscribeNewLogs(ch)
 }
@@ -339,6 +415,14 @@ func (b *EthAPIBackend) SubscribeNewBlockNotify(ch chan<- evmcore.ChainHeadNotif
 	return b.svc.feed.SubscribeNewBlock(ch)
 }
 
+func (b *EthAPIBackend) SubscribeNewEventNotify(ch chan<- *inter.EventPayload) notify.Subscription {
+	return b.svc.feed.SubscribeNewEvent(ch)
+}
+
+func (b *EthAPIBackend) SubscribeNewEpochNotify(ch chan<- idx.Epoch) notify.Subscription {
+	return b.svc.feed.SubscribeNewEpoch(ch)
+}
+
 func (b *EthAPIBackend) SubscribeNewTxsNotify(ch chan<- evmcore.NewTxsNotify) notify.Subscription {
 	return b.svc.txpool.SubscribeNewTxsNotify(ch)
 }
@@ -424,6 +508,18 @@ func (b *EthAPIBackend) TxPoolContentFrom(addr common.Address) (types.Transactio
 	return b.svc.txpool.ContentFrom(addr)
 }
 
+func (b *EthAPIBackend) TxPoolPolicyStats() evmcore.TxPolicyStats {
+	return b.svc.txpool.PolicyStats()
+}
+
+func (b *EthAPIBackend) TxPoolDropped(addr common.Address) []*evmcore.DroppedTx {
+	return b.svc.txpool.Dropped(addr)
+}
+
+func (b *EthAPIBackend) SubscribeDroppedTxsNotify(ch chan<- evmcore.DroppedTxsNotify) notify.Subscription {
+	return b.svc.txpool.SubscribeDroppedTxsNotify(ch)
+}
+
 func (b *EthAPIBackend) SuggestGasTipCap(ctx context.Context, certainty uint64) *big.Int {
 	return b.svc.gpo.SuggestTip(certainty)
 }
@@ -448,6 +544,10 @@ func (b *EthAPIBackend) UnprotectedAllowed() bool {
 	return b.allowUnprotectedTxs
 }
 
+func (b *EthAPIBackend) RPCReceiptsRangeCap() uint64 {
+	return b.svc.config.RPCReceiptsRangeCap
+}
+
 func (b *EthAPIBackend) RPCGasCap() uint64 {
 	return b.svc.config.RPCGasCap
 }
@@ -0,0 +1,93 @@
+package gossip
+
+import (
+	"sync"
+
+	notify "github.com/ethereum/go-ethereum/event"
+	"github.com/ethereum/go-ethereum/log"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+const eventsNotifyQueueLimit = 4096
+
+// eventsNotifyQueue sends connected events to the feed asynchronously,
+// so slow subscribers never block events processing under the engine mutex.
+// Up to limit of unsent events are queued, the oldest ones are dropped on overflow.
+type eventsNotifyQueue struct {
+	feed  *notify.Feed
+	limit int
+
+	mu      sync.Mutex
+	unsent  []*inter.EventPayload
+	dropped int
+
+	signal chan struct{}
+	quit   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newEventsNotifyQueue(feed *notify.Feed, limit int) *eventsNotifyQueue {
+	return &eventsNotifyQueue{
+		feed:   feed,
+		limit:  limit,
+		signal: make(chan struct{}, 1),
+		quit:   make(chan struct{}),
+	}
+}
+
+// Push queues the event to be sent, never blocks.
+func (q *eventsNotifyQueue) Push(e *inter.EventPayload) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.unsent) >= q.limit {
+		q.unsent = q.unsent[1:]
+		q.dropped++
+	}
+	q.unsent = append(q.unsent, e)
+	select {
+	case q.signal <- struct{}{}:
+	default:
+	}
+}
+
+// Start starts sending the queued events.
+func (q *eventsNotifyQueue) Start() {
+	q.wg.Add(1)
+	go func() {
+		defer q.wg.Done()
+		q.loop()
+	}()
+}
+
+// Stop stops sending, the unsent events are discarded.
+func (q *eventsNotifyQueue) Stop() {
+	close(q.quit)
+	q.wg.Wait()
+}
+
+func (q *eventsNotifyQueue) loop() {
+	for {
+		select {
+		case <-q.signal:
+			q.mu.Lock()
+			unsent, dropped := q.unsent, q.dropped
+			q.unsent, q.dropped = nil, 0
+			q.mu.Unlock()
+			if dropped != 0 {
+				log.Warn("Slow new events subscribers, notifications are dropped", "dropped", dropped)
+			}
+			for _, e := range unsent {
+				select {
+				case <-q.quit:
+					return
+				default:
+				}
+				q.feed.Send(e)
+			}
+		case <-q.quit:
+			return
+		}
+	}
+}
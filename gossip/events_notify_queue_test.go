@@ -0,0 +1,63 @@
+package gossip
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	notify "github.com/ethereum/go-ethereum/event"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/inter"
+)
+
+func TestEventsNotifyQueue(t *testing.T) {
+	require := require.New(t)
+
+	var feed notify.Feed
+	q := newEventsNotifyQueue(&feed, 3)
+	q.Start()
+	defer q.Stop()
+
+	// the subscriber doesn't read the events for a while
+	ch := make(chan *inter.EventPayload)
+	sub := feed.Subscribe(ch)
+	defer sub.Unsubscribe()
+
+	events := make([]*inter.EventPayload, 10)
+	for i := range events {
+		e := &inter.MutableEventPayload{}
+		e.SetSeq(idx.Event(i + 1))
+		events[i] = e.Build()
+	}
+
+	// pushes never block
+	pushed := make(chan struct{})
+	go func() {
+		defer close(pushed)
+		for _, e := range events {
+			q.Push(e)
+		}
+	}()
+	select {
+	case <-pushed:
+	case <-time.After(5 * time.Second):
+		require.FailNow("push is blocked by the subscriber")
+	}
+
+	// a batch may be taken by the sender before the overflow, the oldest of the others are dropped
+	var received []idx.Event
+	for len(received) == 0 || received[len(received)-1] != 10 {
+		select {
+		case e := <-ch:
+			received = append(received, e.Seq())
+		case <-time.After(5 * time.Second):
+			require.FailNow("events aren't sent", received)
+		}
+	}
+	require.LessOrEqual(len(received), 2*3)
+	require.Equal([]idx.Event{8, 9, 10}, received[len(received)-3:])
+	for i := 1; i < len(received); i++ {
+		require.Less(uint32(received[i-1]), uint32(received[i]))
+	}
+}
@@ -42,6 +42,9 @@ type Store struct {
 		Receipts    kvdb.Store `table:"r"`
 		TxPositions kvdb.Store `table:"x"`
 		Txs         kvdb.Store `table:"X"`
+		// Optional call traces index
+		CallTraces     kvdb.Store `table:"T"`
+		CallTraceAddrs kvdb.Store `table:"A"`
 	}
 
 	EvmDb    ethdb.Database
@@ -254,6 +257,15 @@ func (s *Store) IndexLogs(recs ...*types.Log) {
 	}
 }
 
+// IndexReceiptsLogs indexes EVM logs of the block receipts at once
+func (s *Store) IndexReceiptsLogs(receipts types.Receipts) {
+	var recs []*types.Log
+	for _, r := range receipts {
+		recs = append(recs, r.Logs...)
+	}
+	s.IndexLogs(recs...)
+}
+
 func (s *Store) Snapshots() *snapshot.Tree {
 	return s.Snaps
 }
@@ -0,0 +1,97 @@
+package evmstore
+
+import (
+	"encoding/binary"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rlp"
+
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+// Directions of call traces index.
+const (
+	callTraceFrom byte = 0
+	callTraceTo   byte = 1
+)
+
+func callTraceKey(addr common.Address, dir byte, n idx.Block) []byte {
+	key := make([]byte, 0, common.AddressLength+1+8+4)
+	key = append(key, addr.Bytes()...)
+	key = append(key, dir)
+	return append(key, n.Bytes()...)
+}
+
+// SetCallTraces stores flat call traces of the block txs (ordered as the not skipped block txs),
+// and indexes them by the senders and recipients.
+func (s *Store) SetCallTraces(n idx.Block, traces [][]txtrace.ActionTrace) {
+	buf, err := rlp.EncodeToBytes(traces)
+	if err != nil {
+		s.Log.Crit("Failed to encode rlp", "err", err)
+	}
+	if err := s.table.CallTraces.Put(n.Bytes(), buf); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+
+	for i, txTraces := range traces {
+		from, to := txtrace.Addresses(txTraces)
+		for _, addr := range from {
+			s.indexCallTrace(addr, callTraceFrom, n, uint32(i))
+		}
+		for _, addr := range to {
+			s.indexCallTrace(addr, callTraceTo, n, uint32(i))
+		}
+	}
+}
+
+func (s *Store) indexCallTrace(addr common.Address, dir byte, n idx.Block, offset uint32) {
+	key := callTraceKey(addr, dir, n)
+	key = key[:len(key)+4]
+	binary.BigEndian.PutUint32(key[len(key)-4:], offset)
+	if err := s.table.CallTraceAddrs.Put(key, []byte{}); err != nil {
+		s.Log.Crit("Failed to put key-value", "err", err)
+	}
+}
+
+// GetCallTraces returns flat call traces of the block txs, or nil if the block isn't indexed.
+func (s *Store) GetCallTraces(n idx.Block) [][]txtrace.ActionTrace {
+	buf, err := s.table.CallTraces.Get(n.Bytes())
+	if err != nil {
+		s.Log.Crit("Failed to get key-value", "err", err)
+	}
+	if buf == nil {
+		return nil
+	}
+	traces := make([][]txtrace.ActionTrace, 0)
+	if err := rlp.DecodeBytes(buf, &traces); err != nil {
+		s.Log.Crit("Failed to decode rlp", "err", err, "size", len(buf))
+	}
+	return traces
+}
+
+// ForEachCallTracePosition iterates over positions of txs within [from, to] blocks range,
+// which traces contain the address as a sender (or as a recipient if recipient is true).
+// Positions are iterated in ascending order until onPosition returns false.
+func (s *Store) ForEachCallTracePosition(addr common.Address, recipient bool, from, to idx.Block, onPosition func(txtrace.Position) bool) {
+	dir := callTraceFrom
+	if recipient {
+		dir = callTraceTo
+	}
+	prefix := callTraceKey(addr, dir, 0)[:common.AddressLength+1]
+	it := s.table.CallTraceAddrs.NewIterator(prefix, from.Bytes())
+	defer it.Release()
+	for it.Next() {
+		key := it.Key()[len(prefix):]
+		pos := txtrace.Position{
+			Block:       idx.BytesToBlock(key[:8]),
+			BlockOffset: binary.BigEndian.Uint32(key[8:]),
+		}
+		if pos.Block > to || !onPosition(pos) {
+			break
+		}
+	}
+	if it.Error() != nil {
+		s.Log.Crit("Failed to iterate keys", "err", it.Error())
+	}
+}
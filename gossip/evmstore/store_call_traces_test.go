@@ -0,0 +1,68 @@
+package evmstore
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/logger"
+	"github.com/Fantom-foundation/go-opera/txtrace"
+)
+
+func TestStoreCallTraces(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+	store := cachedStore()
+
+	var (
+		a = common.Address{1}
+		b = common.Address{2}
+		c = common.Address{3}
+	)
+	trace := func(from, to common.Address) txtrace.ActionTrace {
+		return txtrace.ActionTrace{
+			Type:     txtrace.CallAction,
+			CallType: txtrace.CallAction,
+			From:     from,
+			To:       to,
+			Value:    big.NewInt(1),
+			Input:    []byte{},
+			Output:   []byte{},
+		}
+	}
+	store.SetCallTraces(5, [][]txtrace.ActionTrace{
+		{trace(a, b), trace(b, c)},
+		{trace(c, a)},
+	})
+	store.SetCallTraces(7, [][]txtrace.ActionTrace{
+		{trace(a, c)},
+	})
+	store.SetCallTraces(8, [][]txtrace.ActionTrace{})
+
+	require.Nil(store.GetCallTraces(6))
+	require.NotNil(store.GetCallTraces(8))
+	got := store.GetCallTraces(5)
+	require.Len(got, 2)
+	require.Equal(b, got[0][1].From)
+	require.Equal(c, got[0][1].To)
+	require.Equal(big.NewInt(1), got[1][0].Value)
+
+	positions := func(addr common.Address, recipient bool, from, to idx.Block) []txtrace.Position {
+		var res []txtrace.Position
+		store.ForEachCallTracePosition(addr, recipient, from, to, func(pos txtrace.Position) bool {
+			res = append(res, pos)
+			return true
+		})
+		return res
+	}
+	require.Equal([]txtrace.Position{{5, 0}, {7, 0}}, positions(a, false, 0, 10))
+	require.Equal([]txtrace.Position{{7, 0}}, positions(a, false, 6, 10))
+	require.Equal([]txtrace.Position{{5, 0}}, positions(a, false, 0, 6))
+	require.Equal([]txtrace.Position{{5, 0}, {7, 0}}, positions(c, true, 0, 10))
+	require.Equal([]txtrace.Position{{5, 1}}, positions(c, false, 0, 10))
+	require.Equal([]txtrace.Position{{5, 1}}, positions(a, true, 0, 10))
+	require.Empty(positions(b, false, 6, 10))
+}
@@ -38,6 +38,11 @@ var (
 	deadline = 5 * time.Minute // consider a filter inactive if it has not been polled for within deadline
 )
 
+const (
+	defaultLogsPageLimit = 1000
+	maxLogsPageLimit     = 10000
+)
+
 // filter is a helper struct that holds meta information over the filter type
 // and associated subscription in the event system.
 type filter struct {
@@ -258,7 +263,9 @@ func (api *PublicFilterAPI) NewHeads(ctx context.Context) (*rpc.Subscription, er
 }
 
 // Logs creates a subscription that fires for all new log that match the given filter criteria.
-func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc.Subscription, error) {
+// If the resumeFrom option is set, the matching historical logs are sent first, starting from the given
+// block number or following the given cursor of the last received log.
+func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria, opts *LogsSubscriptionArgs) (*rpc.Subscription, error) {
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -274,6 +281,11 @@ func (api *PublicFilterAPI) Logs(ctx context.Context, crit FilterCriteria) (*rpc
 		return nil, err
 	}
 
+	if opts != nil && opts.ResumeFrom != nil {
+		go api.resumeLogs(notifier, rpcSub, logsSub, matchedLogs, crit, *opts.ResumeFrom)
+		return rpcSub, nil
+	}
+
 	go func() {
 
 		for {
@@ -348,31 +360,78 @@ func (api *PublicFilterAPI) NewFilter(crit FilterCriteria) (rpc.ID, error) {
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_getlogs
 func (api *PublicFilterAPI) GetLogs(ctx context.Context, crit FilterCriteria) ([]*types.Log, error) {
-	var filter *Filter
-	if crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, api.config, *crit.BlockHash, crit.Addresses, crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if crit.FromBlock != nil {
-			begin = crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if crit.ToBlock != nil {
-			end = crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, api.config, begin, end, crit.Addresses, crit.Topics)
-	}
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.newCriteriaFilter(crit).Logs(ctx)
 	if err != nil {
 		return nil, err
 	}
 	return returnLogs(logs), err
 }
 
+// LogCursor points to a log in the block/log-index ordered logs.
+type LogCursor struct {
+	BlockNumber hexutil.Uint64 `json:"blockNumber"`
+	LogIndex    hexutil.Uint   `json:"logIndex"`
+}
+
+// LogsPageArgs selects a page of logs.
+type LogsPageArgs struct {
+	Cursor *LogCursor    `json:"cursor"` // cursor of the previous page, nil for the first one
+	Limit  *hexutil.Uint `json:"limit"`
+}
+
+// LogsPage is a page of logs. Cursor is nil if there are no more logs.
+type LogsPage struct {
+	Logs   []*types.Log `json:"logs"`
+	Cursor *LogCursor   `json:"cursor"`
+}
+
+// GetLogsPage is the paginated form of GetLogs. It returns a page of the matching logs in block/log-index order,
+// and the cursor to pass for the next page.
+func (api *PublicFilterAPI) GetLogsPage(ctx context.Context, crit FilterCriteria, page *LogsPageArgs) (*LogsPage, error) {
+	var (
+		after *LogCursor
+		limit = defaultLogsPageLimit
+	)
+	if page != nil {
+		after = page.Cursor
+		if page.Limit != nil {
+			limit = int(*page.Limit)
+		}
+	}
+	if limit < 1 || limit > maxLogsPageLimit {
+		return nil, fmt.Errorf("page limit must be within [1, %d]", maxLogsPageLimit)
+	}
+
+	logs, next, err := api.newCriteriaFilter(crit).LogsPage(ctx, after, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &LogsPage{
+		Logs:   returnLogs(logs),
+		Cursor: next,
+	}, nil
+}
+
+// newCriteriaFilter constructs a single-shot filter by the criteria.
+func (api *PublicFilterAPI) newCriteriaFilter(crit FilterCriteria) *Filter {
+	if crit.BlockHash != nil {
+		// Block filter requested, construct a single-shot filter
+		return NewBlockFilter(api.backend, api.config, *crit.BlockHash, crit.Addresses, crit.Topics)
+	}
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if crit.FromBlock != nil {
+		begin = crit.FromBlock.Int64()
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if crit.ToBlock != nil {
+		end = crit.ToBlock.Int64()
+	}
+	// Construct the range filter
+	return NewRangeFilter(api.backend, api.config, begin, end, crit.Addresses, crit.Topics)
+}
+
 // UninstallFilter removes the filter with the given filter id.
 //
 // https://github.com/ethereum/wiki/wiki/JSON-RPC#eth_uninstallfilter
@@ -403,25 +462,8 @@ func (api *PublicFilterAPI) GetFilterLogs(ctx context.Context, id rpc.ID) ([]*ty
 		return nil, fmt.Errorf("filter not found")
 	}
 
-	var filter *Filter
-	if f.crit.BlockHash != nil {
-		// Block filter requested, construct a single-shot filter
-		filter = NewBlockFilter(api.backend, api.config, *f.crit.BlockHash, f.crit.Addresses, f.crit.Topics)
-	} else {
-		// Convert the RPC block numbers into internal representations
-		begin := rpc.LatestBlockNumber.Int64()
-		if f.crit.FromBlock != nil {
-			begin = f.crit.FromBlock.Int64()
-		}
-		end := rpc.LatestBlockNumber.Int64()
-		if f.crit.ToBlock != nil {
-			end = f.crit.ToBlock.Int64()
-		}
-		// Construct the range filter
-		filter = NewRangeFilter(api.backend, api.config, begin, end, f.crit.Addresses, f.crit.Topics)
-	}
 	// Run the filter and return all the logs
-	logs, err := filter.Logs(ctx)
+	logs, err := api.newCriteriaFilter(f.crit).Logs(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -183,3 +183,24 @@ func TestUnmarshalJSONNewFilterArgs(t *testing.T) {
 		t.Fatalf("expected 0 topics, got %d topics", len(test7.Topics[2]))
 	}
 }
+
+func TestUnmarshalJSONResumePoint(t *testing.T) {
+	var args LogsSubscriptionArgs
+	if err := json.Unmarshal([]byte(`{"resumeFrom":"0x10"}`), &args); err != nil {
+		t.Fatal(err)
+	}
+	if args.ResumeFrom.Block == nil || *args.ResumeFrom.Block != 0x10 || args.ResumeFrom.Cursor != nil {
+		t.Fatalf("expected block 0x10, got %v", args.ResumeFrom)
+	}
+
+	args = LogsSubscriptionArgs{}
+	if err := json.Unmarshal([]byte(`{"resumeFrom":{"blockNumber":"0x10","logIndex":"0x2"}}`), &args); err != nil {
+		t.Fatal(err)
+	}
+	if args.ResumeFrom.Cursor == nil || args.ResumeFrom.Cursor.BlockNumber != 0x10 || args.ResumeFrom.Cursor.LogIndex != 2 {
+		t.Fatalf("expected cursor 0x10:2, got %v", args.ResumeFrom)
+	}
+	if args.ResumeFrom.Block != nil {
+		t.Fatalf("expected no block, got %d", *args.ResumeFrom.Block)
+	}
+}
@@ -25,6 +25,7 @@ import (
 	"github.com/Fantom-foundation/lachesis-base/hash"
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethdb"
 	notify "github.com/ethereum/go-ethereum/event"
@@ -62,6 +63,9 @@ type Filter struct {
 
 	block      common.Hash // Block hash if filtering a single block
 	begin, end int64       // Range interval if filtering multiple blocks
+
+	after *LogCursor // Page cursor, logs up to it are skipped
+	limit int        // Page size, 0 if not paginated
 }
 
 // NewRangeFilter creates a new filter which inspects the blocks to
@@ -99,8 +103,25 @@ func newFilter(backend Backend, cfg Config, addresses []common.Address, topics [
 	}
 }
 
+// LogsPage is the same as Logs, but returns at most limit logs following the after cursor (if not nil),
+// and the cursor of the next page (nil if there are no more logs).
+func (f *Filter) LogsPage(ctx context.Context, after *LogCursor, limit int) ([]*types.Log, *LogCursor, error) {
+	f.after, f.limit = after, limit
+	logs, err := f.Logs(ctx)
+	if err != nil || len(logs) <= limit {
+		return logs, nil, err
+	}
+	logs = logs[:limit]
+	last := logs[limit-1]
+	return logs, &LogCursor{
+		BlockNumber: hexutil.Uint64(last.BlockNumber),
+		LogIndex:    hexutil.Uint(last.Index),
+	}, nil
+}
+
 // Logs searches the blockchain for matching log entries, returning all from the
 // first block that contains matches, updating the start of the filter accordingly.
+// Logs are returned in block/log-index order.
 func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	// If we're doing singleton block filtering, execute and return
 	if f.block != common.Hash(hash.Zero) {
@@ -111,7 +132,11 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 		if header == nil {
 			return nil, errors.New("unknown block")
 		}
-		return f.blockLogs(ctx, header.Hash)
+		logs, err := f.blockLogs(ctx, header.Hash)
+		if err != nil {
+			return nil, err
+		}
+		return f.page(nil, logs), nil
 	}
 	// Figure out the limits of the filter range
 	header, _ := f.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
@@ -128,6 +153,9 @@ func (f *Filter) Logs(ctx context.Context) ([]*types.Log, error) {
 	if f.end < 0 {
 		end = head
 	}
+	if f.after != nil && idx.Block(f.after.BlockNumber) > begin {
+		begin = idx.Block(f.after.BlockNumber)
+	}
 	if begin > end {
 		return []*types.Log{}, nil
 	}
@@ -154,7 +182,13 @@ func (f *Filter) indexedLogs(ctx context.Context, begin, end idx.Block) ([]*type
 	pattern[0] = addresses
 	pattern = append(pattern, f.topics...)
 
-	logs, err := f.backend.EvmLogIndex().FindInBlocks(ctx, begin, end, pattern)
+	var logs []*types.Log
+	err := f.backend.EvmLogIndex().ForEachInBlocks(ctx, begin, end, pattern, func(l *types.Log) bool {
+		if f.follows(l) {
+			logs = append(logs, l)
+		}
+		return !f.full(logs)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -196,11 +230,46 @@ func (f *Filter) unindexedLogs(ctx context.Context, begin, end idx.Block) (logs
 		if err != nil {
 			return
 		}
-		logs = append(logs, found...)
+		logs = f.page(logs, found)
+		if f.full(logs) {
+			return
+		}
 	}
 	return
 }
 
+// follows returns true if the log follows the page cursor.
+func (f *Filter) follows(l *types.Log) bool {
+	return logFollows(l, f.after)
+}
+
+// logFollows returns true if the log follows the cursor (or if there is no cursor).
+func logFollows(l *types.Log, after *LogCursor) bool {
+	if after == nil {
+		return true
+	}
+	block := uint64(after.BlockNumber)
+	return l.BlockNumber > block || l.BlockNumber == block && l.Index > uint(after.LogIndex)
+}
+
+// full returns true if the page has an extra log, so there is a next page.
+func (f *Filter) full(logs []*types.Log) bool {
+	return f.limit > 0 && len(logs) > f.limit
+}
+
+// page appends the found logs which follow the page cursor, until the page is full.
+func (f *Filter) page(logs, found []*types.Log) []*types.Log {
+	for _, l := range found {
+		if f.full(logs) {
+			break
+		}
+		if f.follows(l) {
+			logs = append(logs, l)
+		}
+	}
+	return logs
+}
+
 // blockLogs returns the logs matching the filter criteria within a single block.
 func (f *Filter) blockLogs(ctx context.Context, header common.Hash) ([]*types.Log, error) {
 	// Get the logs of the block
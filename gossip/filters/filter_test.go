@@ -264,4 +264,40 @@ func TestFilters(t *testing.T) {
 		t.Error("expected 0 log, got", len(logs))
 	}
 
+	var (
+		cursor *LogCursor
+		paged  []*types.Log
+	)
+	for page := 0; page < 2; page++ {
+		filter = NewRangeFilter(backend, testConfig(), 0, -1, []common.Address{addr}, [][]common.Hash{{hash1, hash2, hash3, hash4}})
+		logs, cursor, err = filter.LogsPage(context.Background(), cursor, 3)
+		if err != nil {
+			t.Error(err)
+		}
+		paged = append(paged, logs...)
+	}
+	if len(paged) != 4 {
+		t.Error("expected 4 paged logs, got", len(paged))
+	}
+	if cursor != nil {
+		t.Error("expected no next page")
+	}
+	for i := 1; i < len(paged); i++ {
+		if paged[i-1].BlockNumber >= paged[i].BlockNumber {
+			t.Error("expected logs in block order")
+		}
+	}
+
+	api := &PublicFilterAPI{config: testConfig(), backend: backend}
+	crit := FilterCriteria{Addresses: []common.Address{addr}, Topics: [][]common.Hash{{hash1, hash2, hash3, hash4}}}
+	var replayed []*types.Log
+	err = api.replayLogs(context.Background(), crit, 0, 998, &LogCursor{BlockNumber: 1}, func(l *types.Log) {
+		replayed = append(replayed, l)
+	})
+	if err != nil {
+		t.Error(err)
+	}
+	if len(replayed) != 2 || replayed[0].BlockNumber != 2 || replayed[1].BlockNumber != 998 {
+		t.Error("expected logs of blocks 2 and 998 replayed, got", len(replayed))
+	}
 }
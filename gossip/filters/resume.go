@@ -0,0 +1,172 @@
+package filters
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/log"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const (
+	// resumePageLimit is the page size of the historical logs replay.
+	resumePageLimit = 1000
+	// maxResumeBufferedLogs limits the count of live logs buffered while the historical logs are replayed.
+	maxResumeBufferedLogs = 100000
+)
+
+// LogsSubscriptionArgs are the options of a logs subscription.
+type LogsSubscriptionArgs struct {
+	ResumeFrom *ResumePoint `json:"resumeFrom"`
+}
+
+// ResumePoint is either a block number to replay the logs from (inclusively),
+// or a cursor of the last received log to replay the logs after it.
+type ResumePoint struct {
+	Block  *rpc.BlockNumber
+	Cursor *LogCursor
+}
+
+// UnmarshalJSON accepts either a block number or a {blockNumber, logIndex} cursor.
+func (p *ResumePoint) UnmarshalJSON(data []byte) error {
+	if len(data) > 0 && data[0] == '{' {
+		p.Cursor = new(LogCursor)
+		return json.Unmarshal(data, p.Cursor)
+	}
+	p.Block = new(rpc.BlockNumber)
+	return p.Block.UnmarshalJSON(data)
+}
+
+// resumeLogs serves a resumed logs subscription. It replays the matching historical logs from the resume point
+// up to the current head, and then switches to the live logs. Live logs are subscribed before the head is read,
+// and the ones of blocks up to the head are skipped, so there is neither a gap nor a duplicate.
+func (api *PublicFilterAPI) resumeLogs(
+	notifier *rpc.Notifier, rpcSub *rpc.Subscription, logsSub *Subscription, matchedLogs chan []*types.Log,
+	crit FilterCriteria, from ResumePoint,
+) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// buffer the live logs, not to block the event system during the replay
+	var (
+		mu       sync.Mutex
+		buffered []*types.Log
+		overflow bool
+		ready    = make(chan struct{}, 1)
+	)
+	go func() {
+		defer cancel()
+		defer logsSub.Unsubscribe()
+		for {
+			select {
+			case logs := <-matchedLogs:
+				mu.Lock()
+				if len(buffered)+len(logs) > maxResumeBufferedLogs {
+					overflow = true
+				} else {
+					buffered = append(buffered, logs...)
+				}
+				mu.Unlock()
+				select {
+				case ready <- struct{}{}:
+				default:
+				}
+			case <-rpcSub.Err(): // client send an unsubscribe request
+				return
+			case <-notifier.Closed(): // connection dropped
+				return
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	header, err := api.backend.HeaderByNumber(ctx, rpc.LatestBlockNumber)
+	if header == nil || err != nil {
+		log.Warn("Failed to resume logs subscription", "err", err)
+		return
+	}
+	head := idx.Block(header.Number.Uint64())
+
+	var (
+		begin = head + 1
+		after = from.Cursor
+	)
+	if from.Cursor != nil {
+		begin = idx.Block(from.Cursor.BlockNumber)
+	} else if from.Block != nil && *from.Block >= 0 {
+		begin = idx.Block(*from.Block)
+	}
+
+	err = api.replayLogs(ctx, crit, begin, head, after, func(l *types.Log) {
+		_ = notifier.Notify(rpcSub.ID, l)
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			log.Warn("Failed to replay logs", "err", err)
+		}
+		return
+	}
+
+	for {
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return
+		}
+		mu.Lock()
+		logs := buffered
+		buffered = nil
+		failed := overflow
+		mu.Unlock()
+
+		for _, l := range logs {
+			if l.BlockNumber <= uint64(head) || l.BlockNumber < uint64(begin) || !logFollows(l, after) {
+				continue
+			}
+			_ = notifier.Notify(rpcSub.ID, l)
+		}
+		if failed {
+			log.Warn("Too many logs buffered during resuming, subscription is dropped", "limit", maxResumeBufferedLogs)
+			return
+		}
+	}
+}
+
+// replayLogs passes the logs matching the criteria within [from, to] blocks range and following the cursor to onLog,
+// in block/log-index order. The range is queried by windows of the configured limit.
+func (api *PublicFilterAPI) replayLogs(ctx context.Context, crit FilterCriteria, from, to idx.Block, after *LogCursor, onLog func(*types.Log)) error {
+	window := api.config.IndexedLogsBlockRangeLimit
+	if isEmpty(crit.Topics) && len(crit.Addresses) == 0 {
+		window = api.config.UnindexedLogsBlockRangeLimit
+	}
+
+	for begin := from; begin <= to; {
+		end := to
+		if end-begin > window {
+			end = begin + window
+		}
+		for {
+			filter := NewRangeFilter(api.backend, api.config, int64(begin), int64(end), crit.Addresses, crit.Topics)
+			logs, next, err := filter.LogsPage(ctx, after, resumePageLimit)
+			if err != nil {
+				return err
+			}
+			for _, l := range logs {
+				onLog(l)
+			}
+			if next == nil {
+				break
+			}
+			after = next
+		}
+		if end == to {
+			break
+		}
+		begin = end + 1
+	}
+	return ctx.Err()
+}
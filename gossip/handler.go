@@ -93,13 +93,14 @@ type processCallback struct {
 // handlerConfig is the collection of initialization parameters to create a full
 // node network handler.
 type handlerConfig struct {
-	config   Config
-	notifier dagNotifier
-	txpool   TxPool
-	engineMu sync.Locker
-	checkers *eventcheck.Checkers
-	s        *Store
-	process  processCallback
+	config     Config
+	notifier   dagNotifier
+	txpool     TxPool
+	privateTxs *privateTxs
+	engineMu   sync.Locker
+	checkers   *eventcheck.Checkers
+	s          *Store
+	process    processCallback
 }
 
 type snapsyncEpochUpd struct {
@@ -129,8 +130,9 @@ type handler struct {
 
 	syncStatus syncStatus
 
-	txpool   TxPool
-	maxPeers int
+	txpool     TxPool
+	privateTxs *privateTxs // transactions which aren't gossiped
+	maxPeers   int
 
 	peers *peerSet
 
@@ -205,6 +207,7 @@ func newHandler(
 		config:               c.config,
 		notifier:             c.notifier,
 		txpool:               c.txpool,
+		privateTxs:           c.privateTxs,
 		msgSemaphore:         datasemaphore.New(c.config.Protocol.MsgsSemaphoreLimit, getSemaphoreWarningFn("P2P messages")),
 		store:                c.s,
 		process:              c.process,
@@ -809,9 +812,12 @@ func (h *handler) handle(p *peer) error {
 			p.Log().Warn("Leecher peer registration failed", "err", err)
 			return err
 		}
-		if err := h.brLeecher.RegisterPeer(p.id); err != nil {
-			p.Log().Warn("Leecher peer registration failed", "err", err)
-			return err
+		// block records aren't requested while recording substates, as they'd be rejected
+		if !h.config.RecordSubstates {
+			if err := h.brLeecher.RegisterPeer(p.id); err != nil {
+				p.Log().Warn("Leecher peer registration failed", "err", err)
+				return err
+			}
 		}
 	}
 	if snap != nil {
@@ -824,7 +830,7 @@ func (h *handler) handle(p *peer) error {
 
 	// Propagate existing transactions. new transactions appearing
 	// after this will be sent via broadcasts.
-	h.syncTransactions(p, h.txpool.SampleHashes(h.config.Protocol.MaxInitialTxHashesSend))
+	h.syncPublicTransactions(p, h.config.Protocol.MaxInitialTxHashesSend)
 
 	// Handle incoming messages until the connection is torn down
 	for {
@@ -1033,6 +1039,9 @@ func (h *handler) handleMsg(p *peer) error {
 
 		txs := make(types.Transactions, 0, len(requests))
 		for _, txid := range requests {
+			if h.privateTxs.Has(txid) {
+				continue
+			}
 			tx := h.txpool.Get(txid)
 			if tx == nil {
 				continue
@@ -1441,6 +1450,7 @@ func (h *handler) onNewEpochLoop() {
 				}
 			}
 			h.dagLeecher.OnNewEpoch(myEpoch)
+			h.publishPrivateTxs(myEpoch)
 		// Err() channel will be closed when unsubscribing.
 		case <-h.newEpochsSub.Err():
 			return
@@ -1448,6 +1458,22 @@ func (h *handler) onNewEpochLoop() {
 	}
 }
 
+// publishPrivateTxs falls back the private transactions which weren't originated in time to public gossip
+func (h *handler) publishPrivateTxs(epoch idx.Epoch) {
+	expired := h.privateTxs.Expire(epoch, h.config.PrivateTxEpochs)
+	txs := make(types.Transactions, 0, len(expired))
+	for _, txid := range expired {
+		// skip the transactions which already left the pool
+		if tx := h.txpool.Get(txid); tx != nil {
+			txs = append(txs, tx)
+		}
+	}
+	if len(txs) != 0 {
+		log.Debug("Private transactions fall back to public gossip", "count", len(txs), "epoch", epoch)
+		h.BroadcastTxs(txs)
+	}
+}
+
 func (h *handler) txBroadcastLoop() {
 	ticker := time.NewTicker(h.config.Protocol.RandomTxHashesSendPeriod)
 	defer ticker.Stop()
@@ -1455,7 +1481,7 @@ func (h *handler) txBroadcastLoop() {
 	for {
 		select {
 		case notify := <-h.txsCh:
-			h.BroadcastTxs(notify.Txs)
+			h.BroadcastTxs(h.privateTxs.Public(notify.Txs))
 
 		// Err() channel will be closed when unsubscribing.
 		case <-h.txsSub.Err():
@@ -1470,7 +1496,7 @@ func (h *handler) txBroadcastLoop() {
 				continue
 			}
 			randPeer := peers[rand.Intn(len(peers))]
-			h.syncTransactions(randPeer, h.txpool.SampleHashes(h.config.Protocol.MaxRandomTxHashesSend))
+			h.syncPublicTransactions(randPeer, h.config.Protocol.MaxRandomTxHashesSend)
 		}
 	}
 }
@@ -98,12 +98,13 @@ func makeFuzzedHandler() (h *handler, err error) {
 
 	h, err = newHandler(
 		handlerConfig{
-			config:   config,
-			notifier: feed,
-			txpool:   txpool,
-			engineMu: mu,
-			checkers: checkers,
-			s:        store,
+			config:     config,
+			notifier:   feed,
+			txpool:     txpool,
+			privateTxs: newPrivateTxs(),
+			engineMu:   mu,
+			checkers:   checkers,
+			s:          store,
 			process: processCallback{
 				Event: func(event *inter.EventPayload) error {
 					return nil
@@ -0,0 +1,106 @@
+package gossip
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+var errNoLocalEmitter = errors.New("private transactions require a local validator emitter")
+
+// privateTxs is a set of transactions submitted privately to the local pool.
+// The private transactions aren't gossiped to peers, so only the local emitter may originate them,
+// until they fall back to public gossip after the configured number of epochs.
+// safe for concurrent use
+type privateTxs struct {
+	mu  sync.RWMutex
+	txs map[common.Hash]idx.Epoch // epoch of the submission
+}
+
+func newPrivateTxs() *privateTxs {
+	return &privateTxs{
+		txs: make(map[common.Hash]idx.Epoch),
+	}
+}
+
+// Add marks the transaction as private, returns false if it's already marked.
+func (p *privateTxs) Add(txid common.Hash, epoch idx.Epoch) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.txs[txid]; ok {
+		return false
+	}
+	p.txs[txid] = epoch
+	return true
+}
+
+// Remove unmarks the transaction.
+func (p *privateTxs) Remove(txid common.Hash) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	delete(p.txs, txid)
+}
+
+// Has checks whether the transaction is private.
+func (p *privateTxs) Has(txid common.Hash) bool {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	_, ok := p.txs[txid]
+	return ok
+}
+
+// Public returns the transactions which aren't private.
+func (p *privateTxs) Public(txs types.Transactions) types.Transactions {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.txs) == 0 {
+		return txs
+	}
+	res := make(types.Transactions, 0, len(txs))
+	for _, tx := range txs {
+		if _, ok := p.txs[tx.Hash()]; !ok {
+			res = append(res, tx)
+		}
+	}
+	return res
+}
+
+// PublicHashes returns the transaction hashes which aren't private.
+func (p *privateTxs) PublicHashes(txids []common.Hash) []common.Hash {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if len(p.txs) == 0 {
+		return txids
+	}
+	res := make([]common.Hash, 0, len(txids))
+	for _, txid := range txids {
+		if _, ok := p.txs[txid]; !ok {
+			res = append(res, txid)
+		}
+	}
+	return res
+}
+
+// Expire unmarks the transactions which were submitted at least the given number of epochs ago,
+// and returns them.
+func (p *privateTxs) Expire(epoch idx.Epoch, epochs idx.Epoch) []common.Hash {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	var expired []common.Hash
+	for txid, submitted := range p.txs {
+		if submitted+epochs <= epoch {
+			expired = append(expired, txid)
+			delete(p.txs, txid)
+		}
+	}
+	return expired
+}
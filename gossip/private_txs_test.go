@@ -0,0 +1,118 @@
+package gossip
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/p2p"
+	"github.com/ethereum/go-ethereum/p2p/enode"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+)
+
+func TestPrivateTxs(t *testing.T) {
+	require := require.New(t)
+
+	tx1 := types.NewTransaction(1, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	tx2 := types.NewTransaction(2, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)
+	tx3 := types.NewTransaction(3, common.Address{0x01}, big.NewInt(1), 21000, big.NewInt(1), nil)
+
+	p := newPrivateTxs()
+	require.True(p.Add(tx1.Hash(), 10))
+	require.False(p.Add(tx1.Hash(), 11))
+	require.True(p.Add(tx2.Hash(), 11))
+
+	require.True(p.Has(tx1.Hash()))
+	require.False(p.Has(tx3.Hash()))
+	require.Equal(types.Transactions{tx3}, p.Public(types.Transactions{tx1, tx2, tx3}))
+	require.Equal([]common.Hash{tx3.Hash()}, p.PublicHashes([]common.Hash{tx1.Hash(), tx2.Hash(), tx3.Hash()}))
+
+	// transactions fall back to public after the given number of epochs
+	require.Empty(p.Expire(11, 2))
+	require.Equal([]common.Hash{tx1.Hash()}, p.Expire(12, 2))
+	require.False(p.Has(tx1.Hash()))
+	require.True(p.Has(tx2.Hash()))
+
+	p.Remove(tx2.Hash())
+	require.False(p.Has(tx2.Hash()))
+}
+
+func TestPrivateTxsPropagation(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	ctx := context.Background()
+	h := env.handler
+	pool := env.txpool.(*dummyTxPool)
+
+	private := env.Transfer(1, 2, big.NewInt(1))
+	public := env.Transfer(2, 3, big.NewInt(1))
+
+	// private transactions are rejected without a local emitter
+	emitters := env.emitters
+	env.emitters = nil
+	require.Equal(errNoLocalEmitter, env.EthAPI.SendPrivateTx(ctx, private))
+	require.False(h.privateTxs.Has(private.Hash()))
+	env.emitters = emitters
+
+	require.NoError(env.EthAPI.SendPrivateTx(ctx, private))
+	require.NoError(env.EthAPI.SendTx(ctx, public))
+	require.True(h.privateTxs.Has(private.Hash()))
+
+	// connect a peer
+	remote, local := p2p.MsgPipe()
+	defer remote.Close()
+	p := newPeer(ProtocolVersion, p2p.NewPeer(enode.ID{1}, "test", nil), local, h.config.Protocol.PeerCache)
+	defer p.Close()
+	require.NoError(h.peers.RegisterPeer(p, nil))
+
+	// receivedTxs reads the next transactions or transaction hashes sent to the peer
+	receivedTxs := func() []common.Hash {
+		msg, err := remote.ReadMsg()
+		require.NoError(err)
+		defer msg.Discard()
+		switch msg.Code {
+		case EvmTxsMsg:
+			var txs types.Transactions
+			require.NoError(msg.Decode(&txs))
+			txids := make([]common.Hash, len(txs))
+			for i, tx := range txs {
+				txids[i] = tx.Hash()
+			}
+			return txids
+		case NewEvmTxHashesMsg:
+			var txids []common.Hash
+			require.NoError(msg.Decode(&txids))
+			return txids
+		}
+		require.Failf("unexpected message", "code %d", msg.Code)
+		return nil
+	}
+
+	// new transactions broadcast
+	h.txsCh = make(chan evmcore.NewTxsNotify, txChanSize)
+	h.txsSub = pool.SubscribeNewTxsNotify(h.txsCh)
+	defer h.txsSub.Unsubscribe()
+	h.loopsWg.Add(1)
+	go h.txBroadcastLoop()
+	pool.txFeed.Send(evmcore.NewTxsNotify{Txs: types.Transactions{private, public}})
+	require.Equal([]common.Hash{public.Hash()}, receivedTxs())
+
+	// transactions sync
+	go h.txsyncLoop()
+	defer close(h.quitSync)
+	h.syncPublicTransactions(p, 10)
+	require.Equal([]common.Hash{public.Hash()}, receivedTxs())
+
+	// transactions requested by hashes
+	go func() {
+		_ = p2p.Send(remote, GetEvmTxsMsg, []common.Hash{private.Hash(), public.Hash()})
+	}()
+	require.NoError(h.handleMsg(p))
+	require.Equal([]common.Hash{public.Hash()}, receivedTxs())
+}
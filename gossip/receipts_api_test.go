@@ -0,0 +1,86 @@
+package gossip
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rlp"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+)
+
+func TestReceiptsAPI(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 3)
+	defer env.Close()
+	ctx := context.Background()
+	receiptsAPI := ethapi.NewPublicReceiptsAPI(env.EthAPI)
+	blocksAPI := ethapi.NewPublicBlockChainAPI(env.EthAPI)
+
+	receipts, err := env.ApplyTxs(sameEpoch,
+		env.Transfer(1, 2, big.NewInt(100)),
+		env.Transfer(2, 3, big.NewInt(100)),
+	)
+	require.NoError(err)
+	require.Len(receipts, 2)
+	txBlock := rpc.BlockNumber(receipts[0].BlockNumber.Uint64())
+	latest := rpc.BlockNumber(env.store.GetLatestBlockIndex())
+
+	// raw receipts decode into the receipts of the blocks
+	raws, err := receiptsAPI.GetReceiptsRange(ctx, 1, latest)
+	require.NoError(err)
+	require.NotEmpty(raws)
+	found := 0
+	for _, raw := range raws {
+		var stored []*types.ReceiptForStorage
+		require.NoError(rlp.DecodeBytes(raw.Receipts, &stored))
+
+		fields, err := blocksAPI.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(raw.BlockNumber)))
+		require.NoError(err)
+		require.Len(fields, len(stored))
+		for i, r := range stored {
+			require.Equal(hexutil.Uint64(r.CumulativeGasUsed), fields[i]["cumulativeGasUsed"])
+			require.Equal(hexutil.Uint(r.Status), fields[i]["status"])
+			require.Len(fields[i]["logs"], len(r.Logs))
+			for _, receipt := range receipts {
+				if receipt.TxHash == fields[i]["transactionHash"].(common.Hash) {
+					require.Equal(receipt.CumulativeGasUsed, r.CumulativeGasUsed)
+					found++
+				}
+			}
+		}
+	}
+	require.Equal(len(receipts), found)
+
+	// a range within a block
+	raws, err = receiptsAPI.GetReceiptsRange(ctx, txBlock, txBlock)
+	require.NoError(err)
+	require.Len(raws, 1)
+	require.Equal(hexutil.Uint64(txBlock), raws[0].BlockNumber)
+	require.Equal(hexutil.Bytes(env.store.evm.GetRawReceiptsRLP(idx.Block(txBlock))), raws[0].Receipts)
+
+	// the range is capped
+	env.config.RPCReceiptsRangeCap = uint64(latest)
+	_, err = receiptsAPI.GetReceiptsRange(ctx, 1, latest)
+	require.NoError(err)
+	_, err = receiptsAPI.GetReceiptsRange(ctx, 0, latest)
+	require.EqualError(err, fmt.Sprintf("blocks range exceeds the cap of %d blocks", latest))
+	_, err = receiptsAPI.GetReceiptsRange(ctx, latest, 1)
+	require.Error(err)
+
+	// receipts aren't indexed
+	env.config.TxIndex = false
+	_, err = receiptsAPI.GetReceiptsRange(ctx, txBlock, txBlock)
+	require.Error(err)
+	_, err = blocksAPI.GetBlockReceipts(ctx, rpc.BlockNumberOrHashWithNumber(txBlock))
+	require.Error(err)
+}
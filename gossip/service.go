@@ -27,6 +27,7 @@ import (
 	"github.com/ethereum/go-ethereum/p2p/enode"
 	"github.com/ethereum/go-ethereum/p2p/enr"
 	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/substate"
 
 	"github.com/Fantom-foundation/go-opera/ethapi"
 	"github.com/Fantom-foundation/go-opera/eventcheck"
@@ -60,6 +61,7 @@ type ServiceFeed struct {
 
 	newEpoch        notify.Feed
 	newEmittedEvent notify.Feed
+	newEvent        notify.Feed
 	newBlock        notify.Feed
 	newLogs         notify.Feed
 }
@@ -72,6 +74,10 @@ func (f *ServiceFeed) SubscribeNewEmitted(ch chan<- *inter.EventPayload) notify.
 	return f.scope.Track(f.newEmittedEvent.Subscribe(ch))
 }
 
+func (f *ServiceFeed) SubscribeNewEvent(ch chan<- *inter.EventPayload) notify.Subscription {
+	return f.scope.Track(f.newEvent.Subscribe(ch))
+}
+
 func (f *ServiceFeed) SubscribeNewBlock(ch chan<- evmcore.ChainHeadNotify) notify.Subscription {
 	return f.scope.Track(f.newBlock.Subscribe(ch))
 }
@@ -117,6 +123,7 @@ type Service struct {
 	engineMu            *sync.RWMutex
 	emitters            []*emitter.Emitter
 	txpool              TxPool
+	privateTxs          *privateTxs
 	heavyCheckReader    HeavyCheckReader
 	gasPowerCheckReader GasPowerCheckReader
 	checkers            *eventcheck.Checkers
@@ -133,8 +140,9 @@ type Service struct {
 	blockBusyFlag uint32
 	eventBusyFlag uint32
 
-	feed     ServiceFeed
-	eventMux *event.TypeMux
+	feed      ServiceFeed
+	newEvents *eventsNotifyQueue // sends connected events to the feed without blocking events processing
+	eventMux  *event.TypeMux
 
 	gpo *gasprice.Oracle
 
@@ -195,6 +203,7 @@ func newService(config Config, store *Store, blockProc BlockProc, engine lachesi
 	}
 
 	svc.blockProcTasks = workers.New(new(sync.WaitGroup), svc.blockProcTasksDone, 1)
+	svc.newEvents = newEventsNotifyQueue(&svc.feed.newEvent, eventsNotifyQueueLimit)
 
 	// load epoch DB
 	svc.store.loadEpochStore(svc.store.GetEpoch())
@@ -223,6 +232,7 @@ func newService(config Config, store *Store, blockProc BlockProc, engine lachesi
 	// create tx pool
 	stateReader := svc.GetEvmStateReader()
 	svc.txpool = newTxPool(stateReader)
+	svc.privateTxs = newPrivateTxs()
 
 	// init dialCandidates
 	dnsclient := dnsdisc.NewClient(dnsdisc.Config{})
@@ -238,12 +248,13 @@ func newService(config Config, store *Store, blockProc BlockProc, engine lachesi
 
 	// create protocol manager
 	svc.handler, err = newHandler(handlerConfig{
-		config:   config,
-		notifier: &svc.feed,
-		txpool:   svc.txpool,
-		engineMu: svc.engineMu,
-		checkers: svc.checkers,
-		s:        store,
+		config:     config,
+		notifier:   &svc.feed,
+		txpool:     svc.txpool,
+		privateTxs: svc.privateTxs,
+		engineMu:   svc.engineMu,
+		checkers:   svc.checkers,
+		s:          store,
 		process: processCallback{
 			Event: func(event *inter.EventPayload) error {
 				done := svc.procLogger.EventConnectionStarted(event, false)
@@ -337,6 +348,26 @@ func (s *Service) RegisterEmitter(em *emitter.Emitter) {
 	s.emitters = append(s.emitters, em)
 }
 
+// originatesEvents returns true if any of the registered emitters signs and broadcasts events
+func (s *Service) originatesEvents() bool {
+	for _, em := range s.emitters {
+		if em.Originates() {
+			return true
+		}
+	}
+	return false
+}
+
+// hasShadowEmitter returns true if any of the registered emitters runs in shadow mode
+func (s *Service) hasShadowEmitter() bool {
+	for _, em := range s.emitters {
+		if em.IsShadow() {
+			return true
+		}
+	}
+	return false
+}
+
 // MakeProtocols constructs the P2P protocol definitions for `opera`.
 func MakeProtocols(svc *Service, backend *handler, disc enode.Iterator) []p2p.Protocol {
 	protocols := make([]p2p.Protocol, len(ProtocolVersions))
@@ -414,11 +445,24 @@ func (s *Service) APIs() []rpc.API {
 		},
 	}...)
 
+	if s.hasShadowEmitter() {
+		apis = append(apis, rpc.API{
+			Namespace: "dag",
+			Version:   "1.0",
+			Service:   NewPublicShadowEmitterAPI(s),
+			Public:    true,
+		})
+	}
+
 	return apis
 }
 
 // Start method invoked when the node is ready to start the service.
 func (s *Service) Start() error {
+	// open substate DB before any block gets processed
+	if err := s.startSubstateRecording(); err != nil {
+		return err
+	}
 	s.gpo.Start(&GPOBackend{s.store, s.txpool})
 	// start tflusher before starting snapshots generation
 	s.tflusher.Start()
@@ -437,6 +481,7 @@ func (s *Service) Start() error {
 
 	// start blocks processor
 	s.blockProcTasks.Start(1)
+	s.newEvents.Start()
 
 	// start p2p
 	StartENRUpdater(s, s.p2pServer.LocalNode())
@@ -457,6 +502,41 @@ func (s *Service) Start() error {
 	return nil
 }
 
+// substate DB lifecycle, replaced in tests
+var (
+	openSubstateDB  = substate.OpenSubstateDB
+	closeSubstateDB = substate.CloseSubstateDB
+)
+
+// startSubstateRecording opens the substate DBs and enables recording, if it's configured.
+func (s *Service) startSubstateRecording() error {
+	if !s.config.RecordSubstates {
+		return nil
+	}
+	s.Log.Warn("LLR block records are rejected while recording substates, blocks are processed from events")
+	evmcore.SetSubstateFilter(s.config.SubstateFilter)
+	openSubstateDB()
+	if s.config.SubstateMetaDir != "" {
+		if err := evmcore.OpenSubstateMetaDB(s.config.SubstateMetaDir); err != nil {
+			closeSubstateDB()
+			return err
+		}
+	}
+	substate.RecordReplay = true
+	return nil
+}
+
+// stopSubstateRecording disables recording and closes the substate DBs, if recording is configured.
+// Must be called after all the blocks are processed.
+func (s *Service) stopSubstateRecording() {
+	if !s.config.RecordSubstates {
+		return
+	}
+	substate.RecordReplay = false
+	closeSubstateDB()
+	evmcore.CloseSubstateMetaDB()
+}
+
 // WaitBlockEnd waits until parallel block processing is complete (if any)
 func (s *Service) WaitBlockEnd() {
 	s.blockProcWg.Wait()
@@ -475,7 +555,9 @@ func (s *Service) Stop() error {
 	s.snapDialCandidates.Close()
 
 	s.handler.Stop()
+	// close the subscriptions first, so the pending notifications don't block
 	s.feed.scope.Close()
+	s.newEvents.Stop()
 	s.eventMux.Stop()
 	s.gpo.Stop()
 	// it's safe to stop tflusher only before locking engineMu
@@ -488,6 +570,7 @@ func (s *Service) Stop() error {
 
 	s.blockProcWg.Wait()
 	close(s.blockProcTasksDone)
+	s.stopSubstateRecording()
 	s.store.evm.Flush(s.store.GetBlockState(), s.store.GetBlock)
 	return s.store.Commit()
 }
@@ -0,0 +1,85 @@
+package gossip
+
+import (
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/substate"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/eventcheck"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/inter/ibr"
+)
+
+func TestSubstateRecordingLifecycle(t *testing.T) {
+	require := require.New(t)
+
+	var opened, closed int
+	openSubstateDB = func() {
+		opened++
+	}
+	closeSubstateDB = func() {
+		closed++
+	}
+	defer func() {
+		openSubstateDB = substate.OpenSubstateDB
+		closeSubstateDB = substate.CloseSubstateDB
+	}()
+
+	dir, err := ioutil.TempDir("", "substate-meta")
+	require.NoError(err)
+	defer os.RemoveAll(dir)
+
+	env := newTestEnv(2, 1)
+	defer env.Close()
+
+	// recording is disabled
+	require.NoError(env.startSubstateRecording())
+	env.stopSubstateRecording()
+	require.Equal(0, opened)
+	require.Equal(0, closed)
+	require.False(substate.RecordReplay)
+
+	// recording is enabled
+	env.config.RecordSubstates = true
+	env.config.SubstateMetaDir = path.Join(dir, "meta")
+	require.NoError(env.startSubstateRecording())
+	require.Equal(1, opened)
+	require.Equal(0, closed)
+	require.True(substate.RecordReplay)
+	require.DirExists(env.config.SubstateMetaDir)
+	evmcore.PutSubstateMeta(1, 0, &evmcore.SubstateMeta{})
+	require.NotNil(evmcore.GetSubstateMeta(1, 0))
+
+	env.stopSubstateRecording()
+	require.Equal(1, opened)
+	require.Equal(1, closed)
+	require.False(substate.RecordReplay)
+
+	// the substate DB is closed if the metadata DB can't be opened
+	env.config.SubstateMetaDir = path.Join(dir, "file")
+	require.NoError(ioutil.WriteFile(env.config.SubstateMetaDir, []byte{}, 0600))
+	require.Error(env.startSubstateRecording())
+	require.Equal(2, opened)
+	require.Equal(2, closed)
+	require.False(substate.RecordReplay)
+}
+
+func TestSubstateRecordingRejectsBR(t *testing.T) {
+	require := require.New(t)
+
+	env := newTestEnv(2, 1)
+	defer env.Close()
+
+	br := ibr.LlrIdxFullBlockRecord{Idx: env.store.GetLatestBlockIndex() + 1}
+	require.Equal(eventcheck.ErrUndecidedBR, env.ProcessFullBlockRecord(br))
+
+	env.config.RecordSubstates = true
+	err := env.ProcessFullBlockRecord(br)
+	require.Equal(eventcheck.ErrRejectedBR, err)
+	require.False(eventcheck.IsBan(err))
+	require.False(env.store.HasBlock(br.Idx))
+}
@@ -108,6 +108,13 @@ func (s *Store) ForEachEpochEvent(epoch idx.Epoch, onEvent func(event *inter.Eve
 	s.forEachEvent(it, onEvent)
 }
 
+// ForEachEpochEventFrom iterates the epoch events ordered by Lamport time (and ID), starting from the Lamport time.
+func (s *Store) ForEachEpochEventFrom(epoch idx.Epoch, lamport idx.Lamport, onEvent func(event *inter.EventPayload) bool) {
+	it := s.table.Events.NewIterator(epoch.Bytes(), lamport.Bytes())
+	defer it.Release()
+	s.forEachEvent(it, onEvent)
+}
+
 func (s *Store) ForEachEvent(start idx.Epoch, onEvent func(event *inter.EventPayload) bool) {
 	it := s.table.Events.NewIterator(nil, start.Bytes())
 	defer it.Release()
@@ -79,6 +79,12 @@ func (h *handler) syncTransactions(p *peer, txids []common.Hash) {
 	}
 }
 
+// syncPublicTransactions starts sending a sample of the pool transactions to the given peer,
+// except for the private transactions.
+func (h *handler) syncPublicTransactions(p *peer, max int) {
+	h.syncTransactions(p, h.privateTxs.PublicHashes(h.txpool.SampleHashes(max)))
+}
+
 // txsyncLoop takes care of the initial transaction sync for each new
 // connection. When a new peer appears, we relay all currently pending
 // transactions. In order to minimise egress bandwidth usage, we send
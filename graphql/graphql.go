@@ -0,0 +1,1100 @@
+// Package graphql provides a GraphQL interface to Opera node data.
+package graphql
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/common/math"
+	"github.com/ethereum/go-ethereum/core/state"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
+	"github.com/Fantom-foundation/go-opera/gossip/gasprice"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/drivertype"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+)
+
+var (
+	errBlockInvariant = errors.New("block objects must be instantiated with at least one of num or hash")
+)
+
+// Long is a 64 bit unsigned integer.
+type Long uint64
+
+// ImplementsGraphQLType returns true if Long implements the provided GraphQL type.
+func (b Long) ImplementsGraphQLType(name string) bool { return name == "Long" }
+
+// UnmarshalGraphQL unmarshals the provided GraphQL query data.
+func (b *Long) UnmarshalGraphQL(input interface{}) error {
+	var err error
+	switch input := input.(type) {
+	case string:
+		var value uint64
+		value, err = hexutil.DecodeUint64(input)
+		*b = Long(value)
+	case int32:
+		if input < 0 {
+			return fmt.Errorf("negative value %d for Long", input)
+		}
+		*b = Long(input)
+	case int64:
+		if input < 0 {
+			return fmt.Errorf("negative value %d for Long", input)
+		}
+		*b = Long(input)
+	case float64:
+		if input < 0 {
+			return fmt.Errorf("negative value %v for Long", input)
+		}
+		*b = Long(input)
+	default:
+		err = fmt.Errorf("unexpected type %T for Long", input)
+	}
+	return err
+}
+
+// Account represents an Ethereum account at a particular block.
+type Account struct {
+	backend       ethapi.Backend
+	address       common.Address
+	blockNrOrHash rpc.BlockNumberOrHash
+}
+
+// getState fetches the StateDB object for an account.
+func (a *Account) getState(ctx context.Context) (*state.StateDB, error) {
+	statedb, _, err := a.backend.StateAndHeaderByNumberOrHash(ctx, a.blockNrOrHash)
+	if statedb == nil && err == nil {
+		err = errors.New("state not found")
+	}
+	return statedb, err
+}
+
+func (a *Account) Address(ctx context.Context) (common.Address, error) {
+	return a.address, nil
+}
+
+func (a *Account) Balance(ctx context.Context) (hexutil.Big, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*statedb.GetBalance(a.address)), nil
+}
+
+func (a *Account) TransactionCount(ctx context.Context) (Long, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(statedb.GetNonce(a.address)), nil
+}
+
+func (a *Account) Code(ctx context.Context) (hexutil.Bytes, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return statedb.GetCode(a.address), nil
+}
+
+func (a *Account) Storage(ctx context.Context, args struct{ Slot common.Hash }) (common.Hash, error) {
+	statedb, err := a.getState(ctx)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return statedb.GetState(a.address, args.Slot), nil
+}
+
+// Log represents an individual log message. All arguments are mandatory.
+type Log struct {
+	backend     ethapi.Backend
+	transaction *Transaction
+	log         *types.Log
+}
+
+func (l *Log) Transaction(ctx context.Context) *Transaction {
+	return l.transaction
+}
+
+func (l *Log) Account(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		backend:       l.backend,
+		address:       l.log.Address,
+		blockNrOrHash: args.NumberOrLatest(),
+	}
+}
+
+func (l *Log) Index(ctx context.Context) int32 {
+	return int32(l.log.Index)
+}
+
+func (l *Log) Topics(ctx context.Context) []common.Hash {
+	return l.log.Topics
+}
+
+func (l *Log) Data(ctx context.Context) hexutil.Bytes {
+	return l.log.Data
+}
+
+// Transaction represents an Ethereum transaction.
+// The transaction, its block and index are loaded by hash on first use,
+// unless the resolver is instantiated with them.
+type Transaction struct {
+	backend ethapi.Backend
+	hash    common.Hash
+
+	mu       sync.Mutex
+	resolved bool
+	tx       *types.Transaction
+	block    *Block
+	index    uint64
+	receipt  *types.Receipt
+}
+
+// newBlockTransaction returns the resolver of a transaction of the block.
+func newBlockTransaction(backend ethapi.Backend, block *Block, index uint64) *Transaction {
+	tx := block.block.Transactions[index]
+	return &Transaction{
+		backend:  backend,
+		hash:     tx.Hash(),
+		resolved: true,
+		tx:       tx,
+		block:    block,
+		index:    index,
+	}
+}
+
+// resolve returns the internal transaction object, fetching it if needed.
+func (t *Transaction) resolve(ctx context.Context) (*types.Transaction, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.resolved {
+		return t.tx, nil
+	}
+
+	tx, blockNumber, index, err := t.backend.GetTransaction(ctx, t.hash)
+	if err != nil {
+		return nil, err
+	}
+	if tx != nil {
+		block, err := t.backend.BlockByNumber(ctx, rpc.BlockNumber(blockNumber))
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			t.block = &Block{backend: t.backend, block: block}
+		}
+		t.tx, t.index = tx, index
+	} else if t.tx == nil {
+		t.tx = t.backend.GetPoolTransaction(t.hash)
+	}
+	t.resolved = true
+	return t.tx, nil
+}
+
+// mustResolve is like resolve, but returns an error if the transaction is not found.
+func (t *Transaction) mustResolve(ctx context.Context) (*types.Transaction, error) {
+	tx, err := t.resolve(ctx)
+	if err == nil && tx == nil {
+		err = fmt.Errorf("transaction %s not found", t.hash.Hex())
+	}
+	return tx, err
+}
+
+// getReceipt returns the receipt of the transaction, or nil if the transaction is not mined yet.
+func (t *Transaction) getReceipt(ctx context.Context) (*types.Receipt, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.receipt != nil || t.block == nil {
+		return t.receipt, nil
+	}
+	receipts, err := t.block.getReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if t.index >= uint64(len(receipts)) {
+		return nil, nil
+	}
+	t.receipt = receipts[t.index]
+	return t.receipt, nil
+}
+
+func (t *Transaction) Hash(ctx context.Context) common.Hash {
+	return t.hash
+}
+
+func (t *Transaction) InputData(ctx context.Context) (hexutil.Bytes, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return hexutil.Bytes{}, err
+	}
+	return tx.Data(), nil
+}
+
+func (t *Transaction) Gas(ctx context.Context) (Long, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(tx.Gas()), nil
+}
+
+func (t *Transaction) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.GasPrice()), nil
+}
+
+func (t *Transaction) MaxFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil || tx.Type() != types.DynamicFeeTxType {
+		return nil, err
+	}
+	return (*hexutil.Big)(tx.GasFeeCap()), nil
+}
+
+func (t *Transaction) MaxPriorityFeePerGas(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil || tx.Type() != types.DynamicFeeTxType {
+		return nil, err
+	}
+	return (*hexutil.Big)(tx.GasTipCap()), nil
+}
+
+func (t *Transaction) EffectiveGasPrice(ctx context.Context) (*hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil || t.block == nil {
+		return nil, err
+	}
+	baseFee := t.block.block.BaseFee
+	if tx.Type() != types.DynamicFeeTxType || baseFee == nil {
+		return (*hexutil.Big)(tx.GasPrice()), nil
+	}
+	return (*hexutil.Big)(math.BigMin(new(big.Int).Add(tx.GasTipCap(), baseFee), tx.GasFeeCap())), nil
+}
+
+func (t *Transaction) Value(ctx context.Context) (hexutil.Big, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return hexutil.Big{}, err
+	}
+	return hexutil.Big(*tx.Value()), nil
+}
+
+func (t *Transaction) Nonce(ctx context.Context) (Long, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return Long(tx.Nonce()), nil
+}
+
+func (t *Transaction) To(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil || tx.To() == nil {
+		return nil, err
+	}
+	return &Account{
+		backend:       t.backend,
+		address:       *tx.To(),
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (t *Transaction) From(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return nil, err
+	}
+	return &Account{
+		backend:       t.backend,
+		address:       from,
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (t *Transaction) Block(ctx context.Context) (*Block, error) {
+	if _, err := t.resolve(ctx); err != nil {
+		return nil, err
+	}
+	return t.block, nil
+}
+
+func (t *Transaction) Index(ctx context.Context) (*int32, error) {
+	if _, err := t.resolve(ctx); err != nil || t.block == nil {
+		return nil, err
+	}
+	index := int32(t.index)
+	return &index, nil
+}
+
+func (t *Transaction) Status(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	status := Long(receipt.Status)
+	return &status, nil
+}
+
+func (t *Transaction) GasUsed(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := Long(receipt.GasUsed)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) CumulativeGasUsed(ctx context.Context) (*Long, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	gasUsed := Long(receipt.CumulativeGasUsed)
+	return &gasUsed, nil
+}
+
+func (t *Transaction) CreatedContract(ctx context.Context, args BlockNumberArgs) (*Account, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil || receipt.ContractAddress == (common.Address{}) {
+		return nil, err
+	}
+	return &Account{
+		backend:       t.backend,
+		address:       receipt.ContractAddress,
+		blockNrOrHash: args.NumberOrLatest(),
+	}, nil
+}
+
+func (t *Transaction) Logs(ctx context.Context) (*[]*Log, error) {
+	receipt, err := t.getReceipt(ctx)
+	if err != nil || receipt == nil {
+		return nil, err
+	}
+	ret := make([]*Log, 0, len(receipt.Logs))
+	for _, l := range receipt.Logs {
+		ret = append(ret, &Log{
+			backend:     t.backend,
+			transaction: t,
+			log:         l,
+		})
+	}
+	return &ret, nil
+}
+
+func (t *Transaction) Type(ctx context.Context) (*int32, error) {
+	tx, err := t.mustResolve(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txType := int32(tx.Type())
+	return &txType, nil
+}
+
+// Block represents an Opera block.
+type Block struct {
+	backend ethapi.Backend
+	block   *evmcore.EvmBlock
+
+	mu       sync.Mutex
+	receipts types.Receipts
+}
+
+// newBlock returns the resolver of the block by number or hash, or nil if the block is not found.
+func newBlock(ctx context.Context, backend ethapi.Backend, number *rpc.BlockNumber, blockHash *common.Hash) (*Block, error) {
+	var (
+		block *evmcore.EvmBlock
+		err   error
+	)
+	switch {
+	case blockHash != nil:
+		block, err = backend.BlockByHash(ctx, *blockHash)
+	case number != nil:
+		block, err = backend.BlockByNumber(ctx, *number)
+	default:
+		return nil, errBlockInvariant
+	}
+	if block == nil || err != nil {
+		return nil, err
+	}
+	return &Block{backend: backend, block: block}, nil
+}
+
+func (b *Block) number() rpc.BlockNumber {
+	return rpc.BlockNumber(b.block.Number.Uint64())
+}
+
+// getReceipts returns the receipts of the block transactions.
+func (b *Block) getReceipts(ctx context.Context) (types.Receipts, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.receipts != nil {
+		return b.receipts, nil
+	}
+	receipts, err := b.backend.GetReceiptsByNumber(ctx, b.number())
+	if err != nil {
+		return nil, err
+	}
+	b.receipts = receipts
+	return receipts, nil
+}
+
+func (b *Block) Number(ctx context.Context) Long {
+	return Long(b.block.Number.Uint64())
+}
+
+func (b *Block) Hash(ctx context.Context) common.Hash {
+	return b.block.Hash
+}
+
+func (b *Block) Parent(ctx context.Context) (*Block, error) {
+	if b.number() == 0 {
+		return nil, nil
+	}
+	parent := b.number() - 1
+	return newBlock(ctx, b.backend, &parent, nil)
+}
+
+func (b *Block) StateRoot(ctx context.Context) common.Hash {
+	return b.block.Root
+}
+
+func (b *Block) TransactionsRoot(ctx context.Context) common.Hash {
+	return b.block.TxHash
+}
+
+func (b *Block) Miner(ctx context.Context, args BlockNumberArgs) *Account {
+	return &Account{
+		backend:       b.backend,
+		address:       b.block.Coinbase,
+		blockNrOrHash: args.NumberOrLatest(),
+	}
+}
+
+func (b *Block) Timestamp(ctx context.Context) Long {
+	return Long(b.block.Time.Unix())
+}
+
+func (b *Block) TimestampNano(ctx context.Context) Long {
+	return Long(b.block.Time)
+}
+
+func (b *Block) GasLimit(ctx context.Context) Long {
+	return Long(0xffffffffffff) // don't use too much bits here to avoid parsing issues, as the RPC API does
+}
+
+func (b *Block) GasUsed(ctx context.Context) Long {
+	return Long(b.block.GasUsed)
+}
+
+func (b *Block) BaseFeePerGas(ctx context.Context) *hexutil.Big {
+	return (*hexutil.Big)(b.block.BaseFee)
+}
+
+func (b *Block) TransactionCount(ctx context.Context) int32 {
+	return int32(len(b.block.Transactions))
+}
+
+func (b *Block) Transactions(ctx context.Context) []*Transaction {
+	ret := make([]*Transaction, 0, len(b.block.Transactions))
+	for i := range b.block.Transactions {
+		ret = append(ret, newBlockTransaction(b.backend, b, uint64(i)))
+	}
+	return ret
+}
+
+func (b *Block) TransactionAt(ctx context.Context, args struct{ Index int32 }) *Transaction {
+	if args.Index < 0 || int(args.Index) >= len(b.block.Transactions) {
+		return nil
+	}
+	return newBlockTransaction(b.backend, b, uint64(args.Index))
+}
+
+// BlockFilterCriteria encapsulates criteria passed to a `logs` accessor inside a block.
+type BlockFilterCriteria struct {
+	Addresses *[]common.Address // restricts matches to events created by specific contracts
+
+	// The Topic list restricts matches to particular event topics. Each event has a list
+	// of topics. Topics matches a prefix of that list. An empty element slice matches any
+	// topic. Non-empty elements represent an alternative that matches any of the
+	// contained topics.
+	//
+	// Examples:
+	// {} or nil          matches any topic list
+	// {{A}}              matches topic A in first position
+	// {{}, {B}}          matches any topic in first position, B in second position
+	// {{A}, {B}}         matches topic A in first position, B in second position
+	// {{A, B}}, {C, D}}  matches topic (A OR B) in first position, (C OR D) in second position
+	Topics *[][]common.Hash
+}
+
+// criteria returns the addresses and topics of the criteria.
+func (c BlockFilterCriteria) criteria() (addresses []common.Address, topics [][]common.Hash) {
+	if c.Addresses != nil {
+		addresses = *c.Addresses
+	}
+	if c.Topics != nil {
+		topics = *c.Topics
+	}
+	return addresses, topics
+}
+
+func (b *Block) Logs(ctx context.Context, args struct{ Filter BlockFilterCriteria }) ([]*Log, error) {
+	receipts, err := b.getReceipts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	addresses, topics := args.Filter.criteria()
+
+	ret := []*Log{}
+	for i, receipt := range receipts {
+		if i >= len(b.block.Transactions) {
+			break
+		}
+		var tx *Transaction
+		for _, l := range receipt.Logs {
+			if !logMatches(l, addresses, topics) {
+				continue
+			}
+			if tx == nil {
+				tx = newBlockTransaction(b.backend, b, uint64(i))
+			}
+			ret = append(ret, &Log{
+				backend:     b.backend,
+				transaction: tx,
+				log:         l,
+			})
+		}
+	}
+	return ret, nil
+}
+
+// logMatches checks the log against the addresses and topics criteria, as eth_getLogs does.
+func logMatches(l *types.Log, addresses []common.Address, topics [][]common.Hash) bool {
+	if len(addresses) > 0 {
+		found := false
+		for _, addr := range addresses {
+			if addr == l.Address {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(topics) > len(l.Topics) {
+		return false
+	}
+	for i, sub := range topics {
+		match := len(sub) == 0 // empty rule set == wildcard
+		for _, topic := range sub {
+			if l.Topics[i] == topic {
+				match = true
+				break
+			}
+		}
+		if !match {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *Block) Account(ctx context.Context, args struct{ Address common.Address }) *Account {
+	return &Account{
+		backend:       b.backend,
+		address:       args.Address,
+		blockNrOrHash: rpc.BlockNumberOrHashWithNumber(b.number()),
+	}
+}
+
+func (b *Block) Epoch(ctx context.Context) (*Epoch, error) {
+	return newEpoch(ctx, b.backend, rpc.BlockNumber(hash.Event(b.block.Hash).Epoch()))
+}
+
+func (b *Block) Atropos(ctx context.Context) (*Event, error) {
+	if b.number() == 0 {
+		// genesis block isn't decided by an event
+		return nil, nil
+	}
+	return newEvent(ctx, b.backend, hash.Event(b.block.Hash).Hex())
+}
+
+func (b *Block) Events(ctx context.Context) ([]*Event, error) {
+	ids, err := b.backend.GetBlockEvents(ctx, b.number())
+	if err != nil {
+		return nil, err
+	}
+	return loadEvents(ctx, b.backend, ids)
+}
+
+// GasPowerLeft represents the gas power of a validator left after an event.
+type GasPowerLeft struct {
+	gas inter.GasPowerLeft
+}
+
+func (g *GasPowerLeft) ShortTerm(ctx context.Context) Long {
+	return Long(g.gas.Gas[inter.ShortTermGas])
+}
+
+func (g *GasPowerLeft) LongTerm(ctx context.Context) Long {
+	return Long(g.gas.Gas[inter.LongTermGas])
+}
+
+// Event represents a Lachesis DAG event.
+type Event struct {
+	backend ethapi.Backend
+	event   *inter.Event
+
+	mu      sync.Mutex
+	payload *inter.EventPayload
+}
+
+// newEvent returns the resolver of the event by full or short ID, or nil if the event is not found.
+func newEvent(ctx context.Context, backend ethapi.Backend, id string) (*Event, error) {
+	event, err := backend.GetEvent(ctx, id)
+	if event == nil || err != nil {
+		return nil, err
+	}
+	return &Event{backend: backend, event: event}, nil
+}
+
+// loadEvents returns the resolvers of the events. Missing events are skipped.
+func loadEvents(ctx context.Context, backend ethapi.Backend, ids hash.Events) ([]*Event, error) {
+	ret := make([]*Event, 0, len(ids))
+	for _, id := range ids {
+		e, err := newEvent(ctx, backend, id.Hex())
+		if err != nil {
+			return nil, err
+		}
+		if e != nil {
+			ret = append(ret, e)
+		}
+	}
+	return ret, nil
+}
+
+// getPayload returns the event with its transactions, fetching it if needed.
+func (e *Event) getPayload(ctx context.Context) (*inter.EventPayload, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.payload != nil {
+		return e.payload, nil
+	}
+	payload, err := e.backend.GetEventPayload(ctx, e.event.ID().Hex())
+	if err != nil {
+		return nil, err
+	}
+	if payload == nil {
+		return nil, fmt.Errorf("event %s not found", e.event.ID().String())
+	}
+	e.payload = payload
+	return payload, nil
+}
+
+func (e *Event) ID(ctx context.Context) common.Hash {
+	return common.Hash(e.event.ID())
+}
+
+func (e *Event) Epoch(ctx context.Context) (*Epoch, error) {
+	return newEpoch(ctx, e.backend, rpc.BlockNumber(e.event.Epoch()))
+}
+
+func (e *Event) Seq(ctx context.Context) Long {
+	return Long(e.event.Seq())
+}
+
+func (e *Event) Frame(ctx context.Context) Long {
+	return Long(e.event.Frame())
+}
+
+func (e *Event) Creator(ctx context.Context) (*Validator, error) {
+	validator := &Validator{
+		backend: e.backend,
+		epoch:   e.event.Epoch(),
+		id:      e.event.Creator(),
+	}
+	_, es, err := e.backend.GetEpochBlockState(ctx, rpc.BlockNumber(e.event.Epoch()))
+	if err != nil {
+		return nil, err
+	}
+	if es != nil {
+		validator.profile = es.ValidatorProfiles[validator.id]
+	}
+	return validator, nil
+}
+
+func (e *Event) Lamport(ctx context.Context) Long {
+	return Long(e.event.Lamport())
+}
+
+func (e *Event) CreationTime(ctx context.Context) Long {
+	return Long(e.event.CreationTime())
+}
+
+func (e *Event) MedianTime(ctx context.Context) Long {
+	return Long(e.event.MedianTime())
+}
+
+func (e *Event) Parents(ctx context.Context) ([]*Event, error) {
+	return loadEvents(ctx, e.backend, e.event.Parents())
+}
+
+func (e *Event) GasPowerLeft(ctx context.Context) *GasPowerLeft {
+	return &GasPowerLeft{e.event.GasPowerLeft()}
+}
+
+func (e *Event) GasPowerUsed(ctx context.Context) Long {
+	return Long(e.event.GasPowerUsed())
+}
+
+func (e *Event) PayloadHash(ctx context.Context) common.Hash {
+	return common.Hash(e.event.PayloadHash())
+}
+
+func (e *Event) ExtraData(ctx context.Context) hexutil.Bytes {
+	return e.event.Extra()
+}
+
+func (e *Event) Transactions(ctx context.Context) ([]*Transaction, error) {
+	payload, err := e.getPayload(ctx)
+	if err != nil {
+		return nil, err
+	}
+	txs := payload.Txs()
+	ret := make([]*Transaction, 0, len(txs))
+	for _, tx := range txs {
+		ret = append(ret, &Transaction{
+			backend: e.backend,
+			hash:    tx.Hash(),
+			tx:      tx,
+		})
+	}
+	return ret, nil
+}
+
+// Epoch represents a Lachesis epoch.
+type Epoch struct {
+	backend ethapi.Backend
+	es      *iblockproc.EpochState
+}
+
+// newEpoch returns the resolver of the epoch, or nil if the epoch is not found.
+func newEpoch(ctx context.Context, backend ethapi.Backend, epoch rpc.BlockNumber) (*Epoch, error) {
+	_, es, err := backend.GetEpochBlockState(ctx, epoch)
+	if es == nil || err != nil {
+		return nil, err
+	}
+	return &Epoch{backend: backend, es: es}, nil
+}
+
+func (e *Epoch) Number(ctx context.Context) Long {
+	return Long(e.es.Epoch)
+}
+
+func (e *Epoch) Start(ctx context.Context) Long {
+	return Long(e.es.EpochStart)
+}
+
+func (e *Epoch) SealedAt(ctx context.Context) (*Long, error) {
+	// the epoch is sealed at the start of the next one
+	_, next, err := e.backend.GetEpochBlockState(ctx, rpc.BlockNumber(e.es.Epoch+1))
+	if next == nil || err != nil {
+		return nil, err
+	}
+	sealedAt := Long(next.EpochStart)
+	return &sealedAt, nil
+}
+
+func (e *Epoch) TotalWeight(ctx context.Context) hexutil.Big {
+	total := new(big.Int)
+	for _, profile := range e.es.ValidatorProfiles {
+		if profile.Weight != nil {
+			total.Add(total, profile.Weight)
+		}
+	}
+	return hexutil.Big(*total)
+}
+
+func (e *Epoch) Validators(ctx context.Context) []*Validator {
+	ids := e.es.Validators.IDs()
+	ret := make([]*Validator, 0, len(ids))
+	for _, id := range ids {
+		ret = append(ret, &Validator{
+			backend: e.backend,
+			epoch:   e.es.Epoch,
+			id:      id,
+			profile: e.es.ValidatorProfiles[id],
+		})
+	}
+	return ret
+}
+
+func (e *Epoch) Rules(ctx context.Context) string {
+	return e.es.Rules.String()
+}
+
+// Downtime represents the offline period of a validator.
+type Downtime struct {
+	blocks idx.Block
+	time   inter.Timestamp
+}
+
+func (d *Downtime) Blocks(ctx context.Context) Long {
+	return Long(d.blocks)
+}
+
+func (d *Downtime) Time(ctx context.Context) Long {
+	return Long(d.time)
+}
+
+// Validator represents a validator of a Lachesis epoch.
+// Uptime, downtime and originated fee are provided by the aBFT API for the current epoch only,
+// so they are null for validators of other epochs.
+type Validator struct {
+	backend ethapi.Backend
+	epoch   idx.Epoch
+	id      idx.ValidatorID
+	profile drivertype.Validator
+}
+
+// current checks if the validator is of the current epoch.
+func (v *Validator) current(ctx context.Context) bool {
+	return v.epoch == v.backend.CurrentEpoch(ctx)
+}
+
+func (v *Validator) ID(ctx context.Context) Long {
+	return Long(v.id)
+}
+
+func (v *Validator) Weight(ctx context.Context) hexutil.Big {
+	if v.profile.Weight == nil {
+		return hexutil.Big{}
+	}
+	return hexutil.Big(*v.profile.Weight)
+}
+
+func (v *Validator) Pubkey(ctx context.Context) string {
+	return v.profile.PubKey.String()
+}
+
+func (v *Validator) Uptime(ctx context.Context) (*Long, error) {
+	if !v.current(ctx) {
+		return nil, nil
+	}
+	uptime, err := v.backend.GetUptime(ctx, v.id)
+	if uptime == nil || err != nil {
+		return nil, err
+	}
+	res := Long(uptime.Uint64())
+	return &res, nil
+}
+
+func (v *Validator) Downtime(ctx context.Context) (*Downtime, error) {
+	if !v.current(ctx) {
+		return nil, nil
+	}
+	blocks, period, err := v.backend.GetDowntime(ctx, v.id)
+	if err != nil {
+		return nil, err
+	}
+	return &Downtime{blocks: blocks, time: period}, nil
+}
+
+func (v *Validator) OriginatedFee(ctx context.Context) (*hexutil.Big, error) {
+	if !v.current(ctx) {
+		return nil, nil
+	}
+	fee, err := v.backend.GetOriginatedFee(ctx, v.id)
+	if fee == nil || err != nil {
+		return nil, err
+	}
+	return (*hexutil.Big)(fee), nil
+}
+
+// BlockNumberArgs encapsulates arguments to accessors that specify a block number.
+type BlockNumberArgs struct {
+	Block *Long
+}
+
+// NumberOrLatest returns the block number or hash of the arguments,
+// or the latest block if not specified.
+func (a BlockNumberArgs) NumberOrLatest() rpc.BlockNumberOrHash {
+	if a.Block != nil {
+		return rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(*a.Block))
+	}
+	return rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber)
+}
+
+// Resolver is the top-level object in the GraphQL hierarchy.
+type Resolver struct {
+	backend      Backend
+	filterConfig filters.Config
+}
+
+func (r *Resolver) Block(ctx context.Context, args struct {
+	Number *Long
+	Hash   *common.Hash
+}) (*Block, error) {
+	number := rpc.LatestBlockNumber
+	if args.Number != nil {
+		number = rpc.BlockNumber(*args.Number)
+	}
+	return newBlock(ctx, r.backend, &number, args.Hash)
+}
+
+// resolveRange returns the [from, to] blocks range, where the missing bounds default to the latest block.
+// The range is limited as the unindexed logs search is, as the blocks are iterated one by one.
+func (r *Resolver) resolveRange(ctx context.Context, from, to *Long) (idx.Block, idx.Block, error) {
+	resolve := func(n *Long) (idx.Block, error) {
+		number := rpc.LatestBlockNumber
+		if n != nil {
+			number = rpc.BlockNumber(*n)
+		}
+		return r.backend.ResolveRpcBlockNumberOrHash(ctx, rpc.BlockNumberOrHashWithNumber(number))
+	}
+	begin, err := resolve(from)
+	if err != nil {
+		return 0, 0, err
+	}
+	end, err := resolve(to)
+	if err != nil {
+		return 0, 0, err
+	}
+	if begin > end {
+		return 0, 0, errors.New("from block is greater than to block")
+	}
+	if limit := r.filterConfig.UnindexedLogsBlockRangeLimit; end-begin > limit {
+		return 0, 0, fmt.Errorf("too wide blocks range, the limit is %d", limit)
+	}
+	return begin, end, nil
+}
+
+func (r *Resolver) Blocks(ctx context.Context, args struct {
+	From Long
+	To   *Long
+}) ([]*Block, error) {
+	begin, end, err := r.resolveRange(ctx, &args.From, args.To)
+	if err != nil {
+		return nil, err
+	}
+	ret := make([]*Block, 0, end-begin+1)
+	for n := begin; n <= end; n++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		number := rpc.BlockNumber(n)
+		block, err := newBlock(ctx, r.backend, &number, nil)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			break
+		}
+		ret = append(ret, block)
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Transaction(ctx context.Context, args struct{ Hash common.Hash }) (*Transaction, error) {
+	tx := &Transaction{
+		backend: r.backend,
+		hash:    args.Hash,
+	}
+	// Resolve the transaction; if it doesn't exist, return nil.
+	t, err := tx.resolve(ctx)
+	if t == nil || err != nil {
+		return nil, err
+	}
+	return tx, nil
+}
+
+// FilterCriteria encapsulates the arguments to `logs` on the root resolver object.
+type FilterCriteria struct {
+	FromBlock *Long // beginning of the queried range, nil means latest block
+	ToBlock   *Long // end of the range, nil means latest block
+	BlockFilterCriteria
+}
+
+func (r *Resolver) Logs(ctx context.Context, args struct{ Filter FilterCriteria }) ([]*Log, error) {
+	// Convert the RPC block numbers into internal representations
+	begin := rpc.LatestBlockNumber.Int64()
+	if args.Filter.FromBlock != nil {
+		begin = int64(*args.Filter.FromBlock)
+	}
+	end := rpc.LatestBlockNumber.Int64()
+	if args.Filter.ToBlock != nil {
+		end = int64(*args.Filter.ToBlock)
+	}
+	addresses, topics := args.Filter.criteria()
+	// Construct the range filter, which uses the logs index if the criteria is specified
+	filter := filters.NewRangeFilter(r.backend, r.filterConfig, begin, end, addresses, topics)
+	logs, err := filter.Logs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ret := make([]*Log, 0, len(logs))
+	txs := make(map[common.Hash]*Transaction)
+	for _, l := range logs {
+		tx, ok := txs[l.TxHash]
+		if !ok {
+			tx = &Transaction{
+				backend: r.backend,
+				hash:    l.TxHash,
+			}
+			txs[l.TxHash] = tx
+		}
+		ret = append(ret, &Log{
+			backend:     r.backend,
+			transaction: tx,
+			log:         l,
+		})
+	}
+	return ret, nil
+}
+
+func (r *Resolver) Event(ctx context.Context, args struct{ ID string }) (*Event, error) {
+	return newEvent(ctx, r.backend, args.ID)
+}
+
+func (r *Resolver) Epoch(ctx context.Context, args struct{ Number *Long }) (*Epoch, error) {
+	epoch := rpc.LatestBlockNumber
+	if args.Number != nil {
+		epoch = rpc.BlockNumber(*args.Number)
+	}
+	return newEpoch(ctx, r.backend, epoch)
+}
+
+func (r *Resolver) GasPrice(ctx context.Context) (hexutil.Big, error) {
+	tipcap := r.backend.SuggestGasTipCap(ctx, gasprice.AsDefaultCertainty)
+	tipcap.Add(tipcap, r.backend.MinGasPrice())
+	return hexutil.Big(*tipcap), nil
+}
+
+func (r *Resolver) ChainID(ctx context.Context) (hexutil.Big, error) {
+	return hexutil.Big(*r.backend.ChainConfig().ChainID), nil
+}
@@ -0,0 +1,273 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/Fantom-foundation/lachesis-base/hash"
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/Fantom-foundation/lachesis-base/inter/pos"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/graph-gophers/graphql-go"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Fantom-foundation/go-opera/evmcore"
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
+	"github.com/Fantom-foundation/go-opera/inter"
+	"github.com/Fantom-foundation/go-opera/inter/drivertype"
+	"github.com/Fantom-foundation/go-opera/inter/iblockproc"
+	"github.com/Fantom-foundation/go-opera/inter/validatorpk"
+	"github.com/Fantom-foundation/go-opera/opera"
+)
+
+// testBackend serves the blocks, events and epochs of the test,
+// the rest of the Backend methods aren't implemented.
+type testBackend struct {
+	Backend
+
+	epoch  idx.Epoch
+	blocks map[rpc.BlockNumber]*evmcore.EvmBlock
+	events map[rpc.BlockNumber]hash.Events
+	all    map[string]*inter.Event
+	epochs map[idx.Epoch]*iblockproc.EpochState
+}
+
+func (b *testBackend) BlockByNumber(ctx context.Context, number rpc.BlockNumber) (*evmcore.EvmBlock, error) {
+	return b.blocks[number], nil
+}
+
+func (b *testBackend) ResolveRpcBlockNumberOrHash(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) (idx.Block, error) {
+	number, _ := blockNrOrHash.Number()
+	return idx.Block(number), nil
+}
+
+func (b *testBackend) GetBlockEvents(ctx context.Context, number rpc.BlockNumber) (hash.Events, error) {
+	return b.events[number], nil
+}
+
+func (b *testBackend) GetEvent(ctx context.Context, shortEventID string) (*inter.Event, error) {
+	return b.all[shortEventID], nil
+}
+
+func (b *testBackend) CurrentEpoch(ctx context.Context) idx.Epoch {
+	return b.epoch
+}
+
+func (b *testBackend) GetEpochBlockState(ctx context.Context, epoch rpc.BlockNumber) (*iblockproc.BlockState, *iblockproc.EpochState, error) {
+	if epoch == rpc.LatestBlockNumber {
+		epoch = rpc.BlockNumber(b.epoch)
+	}
+	es, ok := b.epochs[idx.Epoch(epoch)]
+	if !ok {
+		return nil, nil, nil
+	}
+	return &iblockproc.BlockState{}, es, nil
+}
+
+func (b *testBackend) GetUptime(ctx context.Context, vid idx.ValidatorID) (*big.Int, error) {
+	return big.NewInt(int64(vid) * 100), nil
+}
+
+func (b *testBackend) GetDowntime(ctx context.Context, vid idx.ValidatorID) (idx.Block, inter.Timestamp, error) {
+	return idx.Block(vid), inter.Timestamp(vid) * 10, nil
+}
+
+func (b *testBackend) GetOriginatedFee(ctx context.Context, vid idx.ValidatorID) (*big.Int, error) {
+	return big.NewInt(int64(vid) * 1000), nil
+}
+
+func newTestBackend() *testBackend {
+	b := &testBackend{
+		epoch:  3,
+		blocks: make(map[rpc.BlockNumber]*evmcore.EvmBlock),
+		events: make(map[rpc.BlockNumber]hash.Events),
+		all:    make(map[string]*inter.Event),
+		epochs: make(map[idx.Epoch]*iblockproc.EpochState),
+	}
+	for epoch := idx.Epoch(2); epoch <= b.epoch; epoch++ {
+		builder := pos.NewBuilder()
+		profiles := make(iblockproc.ValidatorProfiles)
+		for vid := idx.ValidatorID(1); vid <= 2; vid++ {
+			builder.Set(vid, pos.Weight(vid)*10)
+			profiles[vid] = drivertype.Validator{
+				Weight: big.NewInt(int64(vid) * 10),
+				PubKey: validatorpk.PubKey{Type: validatorpk.Types.Secp256k1, Raw: []byte{byte(vid)}},
+			}
+		}
+		b.epochs[epoch] = &iblockproc.EpochState{
+			Epoch:             epoch,
+			EpochStart:        inter.Timestamp(epoch) * 1000,
+			Validators:        builder.Build(),
+			ValidatorProfiles: profiles,
+			Rules:             opera.FakeNetRules(),
+		}
+	}
+
+	// the block 1 is decided by the second event of the epoch 2
+	var events hash.Events
+	for vid := idx.ValidatorID(1); vid <= 2; vid++ {
+		me := &inter.MutableEventPayload{}
+		me.SetEpoch(2)
+		me.SetCreator(vid)
+		me.SetSeq(1)
+		me.SetLamport(idx.Lamport(vid))
+		e := me.Build()
+		b.all[e.ID().Hex()] = &e.Event
+		events = append(events, e.ID())
+	}
+	b.events[1] = events
+	b.blocks[1] = evmcore.NewEvmBlock(&evmcore.EvmHeader{
+		Number: big.NewInt(1),
+		Hash:   common.Hash(events[1]),
+	}, nil)
+	return b
+}
+
+func execQuery(t *testing.T, s *graphql.Schema, query string, result interface{}) {
+	res := s.Exec(context.Background(), query, "", nil)
+	require.Empty(t, res.Errors)
+	require.NoError(t, json.Unmarshal(res.Data, result))
+}
+
+func TestSchema(t *testing.T) {
+	// the resolvers match the schema
+	_, err := graphql.ParseSchema(schema, &Resolver{})
+	require.NoError(t, err)
+}
+
+func TestResolverBlockEvents(t *testing.T) {
+	require := require.New(t)
+
+	backend := newTestBackend()
+	s, err := graphql.ParseSchema(schema, &Resolver{backend: backend})
+	require.NoError(err)
+
+	var res struct {
+		Block struct {
+			Number  uint64
+			Hash    common.Hash
+			Atropos struct {
+				ID common.Hash
+			}
+			Events []struct {
+				ID      common.Hash
+				Lamport uint64
+				Creator struct {
+					ID     uint64
+					Weight string
+					Pubkey string
+				}
+			}
+		}
+	}
+	execQuery(t, s, `{ block(number: 1) { number hash atropos { id } events { id lamport creator { id weight pubkey } } } }`, &res)
+
+	events := backend.events[1]
+	require.Equal(uint64(1), res.Block.Number)
+	require.Equal(common.Hash(events[1]), res.Block.Hash)
+	require.Equal(common.Hash(events[1]), res.Block.Atropos.ID)
+	require.Len(res.Block.Events, len(events))
+	for i, e := range res.Block.Events {
+		vid := idx.ValidatorID(i + 1)
+		require.Equal(common.Hash(events[i]), e.ID)
+		require.Equal(uint64(vid), e.Lamport)
+		require.Equal(uint64(vid), e.Creator.ID)
+		require.Equal(backend.epochs[2].ValidatorProfiles[vid].PubKey.String(), e.Creator.Pubkey)
+		require.Equal(hexutil.EncodeBig(backend.epochs[2].ValidatorProfiles[vid].Weight), e.Creator.Weight)
+	}
+
+	// missing block
+	var missing struct {
+		Block *struct{ Number uint64 }
+	}
+	execQuery(t, s, `{ block(number: 2) { number } }`, &missing)
+	require.Nil(missing.Block)
+}
+
+func TestResolverEpochValidators(t *testing.T) {
+	require := require.New(t)
+
+	backend := newTestBackend()
+	s, err := graphql.ParseSchema(schema, &Resolver{backend: backend})
+	require.NoError(err)
+
+	type validator struct {
+		ID       uint64
+		Uptime   *uint64
+		Downtime *struct {
+			Blocks uint64
+			Time   uint64
+		}
+		OriginatedFee *string
+	}
+	var res struct {
+		Epoch struct {
+			Number     uint64
+			Start      uint64
+			SealedAt   *uint64
+			Validators []validator
+		}
+	}
+	const fields = `number start sealedAt validators { id uptime downtime { blocks time } originatedFee }`
+
+	// the current epoch
+	execQuery(t, s, `{ epoch { `+fields+` } }`, &res)
+	require.Equal(uint64(3), res.Epoch.Number)
+	require.Equal(uint64(3000), res.Epoch.Start)
+	require.Nil(res.Epoch.SealedAt)
+	require.Len(res.Epoch.Validators, 2)
+	ids := []uint64{}
+	for _, v := range res.Epoch.Validators {
+		vid := v.ID
+		ids = append(ids, vid)
+		require.NotNil(v.Uptime)
+		require.Equal(vid*100, *v.Uptime)
+		require.NotNil(v.Downtime)
+		require.Equal(vid, v.Downtime.Blocks)
+		require.Equal(vid*10, v.Downtime.Time)
+		require.NotNil(v.OriginatedFee)
+		require.Equal(hexutil.EncodeBig(big.NewInt(int64(vid)*1000)), *v.OriginatedFee)
+	}
+	require.ElementsMatch([]uint64{1, 2}, ids)
+
+	// a sealed epoch has no uptime and downtime
+	res.Epoch.Validators = nil
+	execQuery(t, s, `{ epoch(number: 2) { `+fields+` } }`, &res)
+	require.Equal(uint64(2), res.Epoch.Number)
+	require.NotNil(res.Epoch.SealedAt)
+	require.Equal(uint64(3000), *res.Epoch.SealedAt)
+	require.Len(res.Epoch.Validators, 2)
+	for _, v := range res.Epoch.Validators {
+		require.Nil(v.Uptime)
+		require.Nil(v.Downtime)
+		require.Nil(v.OriginatedFee)
+	}
+}
+
+func TestResolverBlocksRange(t *testing.T) {
+	require := require.New(t)
+
+	backend := newTestBackend()
+	s, err := graphql.ParseSchema(schema, &Resolver{
+		backend: backend,
+		filterConfig: filters.Config{
+			UnindexedLogsBlockRangeLimit: 2,
+		},
+	})
+	require.NoError(err)
+
+	var res struct {
+		Blocks []struct{ Number uint64 }
+	}
+	execQuery(t, s, `{ blocks(from: 1, to: 3) { number } }`, &res)
+	require.Len(res.Blocks, 1)
+	require.Equal(uint64(1), res.Blocks[0].Number)
+
+	out := s.Exec(context.Background(), `{ blocks(from: 1, to: 4) { number } }`, "", nil)
+	require.Len(out.Errors, 1)
+	require.Contains(out.Errors[0].Message, "too wide blocks range, the limit is 2")
+}
@@ -0,0 +1,290 @@
+package graphql
+
+const schema string = `
+    # Bytes32 is a 32 byte binary string, represented as 0x-prefixed hexadecimal.
+    scalar Bytes32
+    # Address is a 20 byte Ethereum address, represented as 0x-prefixed hexadecimal.
+    scalar Address
+    # Bytes is an arbitrary length binary string, represented as 0x-prefixed hexadecimal.
+    # An empty byte string is represented as '0x'. Byte strings must have an even number of hexadecimal nybbles.
+    scalar Bytes
+    # BigInt is a large integer. Input is accepted as either a JSON number or as a string.
+    # Strings may be either decimal or 0x-prefixed hexadecimal. Output values are all
+    # 0x-prefixed hexadecimal.
+    scalar BigInt
+    # Long is a 64 bit unsigned integer. Input is accepted as either a JSON number or as
+    # a 0x-prefixed hexadecimal string.
+    scalar Long
+
+    schema {
+        query: Query
+    }
+
+    # Account is an Ethereum account at a particular block.
+    type Account {
+        # Address is the address owning the account.
+        address: Address!
+        # Balance is the balance of the account, in wei.
+        balance: BigInt!
+        # TransactionCount is the number of transactions sent from this account,
+        # or in the case of a contract, the number of contracts created. Otherwise
+        # known as the nonce.
+        transactionCount: Long!
+        # Code contains the smart contract code for this account, if the account
+        # is a (non-self-destructed) contract.
+        code: Bytes!
+        # Storage provides access to the storage of a contract account, indexed
+        # by its 32 byte slot identifier.
+        storage(slot: Bytes32!): Bytes32!
+    }
+
+    # Log is an Ethereum event log.
+    type Log {
+        # Index is the index of this log in the block.
+        index: Int!
+        # Account is the account which generated this log - this will always
+        # be a contract account.
+        account(block: Long): Account!
+        # Topics is a list of 0-4 indexed topics for the log.
+        topics: [Bytes32!]!
+        # Data is unindexed data for this log.
+        data: Bytes!
+        # Transaction is the transaction that generated this log entry.
+        transaction: Transaction!
+    }
+
+    # Transaction is an Ethereum transaction.
+    type Transaction {
+        # Hash is the hash of this transaction.
+        hash: Bytes32!
+        # Nonce is the nonce of the account this transaction was generated with.
+        nonce: Long!
+        # Index is the index of this transaction in the parent block. This will
+        # be null if the transaction has not yet been mined.
+        index: Int
+        # From is the account that sent this transaction - this will always be
+        # an externally owned account.
+        from(block: Long): Account!
+        # To is the account the transaction was sent to. This is null for
+        # contract-creating transactions.
+        to(block: Long): Account
+        # Value is the value, in wei, sent along with this transaction.
+        value: BigInt!
+        # GasPrice is the price offered to miners for gas, in wei per unit.
+        gasPrice: BigInt!
+        # MaxFeePerGas is the maximum fee per gas offered to include a transaction, in wei.
+        maxFeePerGas: BigInt
+        # MaxPriorityFeePerGas is the maximum miner tip per gas offered to include a transaction, in wei.
+        maxPriorityFeePerGas: BigInt
+        # EffectiveGasPrice is the actual price per gas paid by the transaction, in wei.
+        # This will be null if the transaction has not yet been mined.
+        effectiveGasPrice: BigInt
+        # Gas is the maximum amount of gas this transaction can consume.
+        gas: Long!
+        # InputData is the data supplied to the target of the transaction.
+        inputData: Bytes!
+        # Block is the block this transaction was mined in. This will be null if
+        # the transaction has not yet been mined.
+        block: Block
+        # Status is the return status of the transaction. This will be 1 if the
+        # transaction succeeded, or 0 if it failed (due to a revert, or due to
+        # running out of gas). If the transaction has not yet been mined, this
+        # field will be null.
+        status: Long
+        # GasUsed is the amount of gas that was used processing this transaction.
+        # If the transaction has not yet been mined, this field will be null.
+        gasUsed: Long
+        # CumulativeGasUsed is the total gas used in the block up to and including
+        # this transaction. If the transaction has not yet been mined, this field
+        # will be null.
+        cumulativeGasUsed: Long
+        # CreatedContract is the account that was created by a contract creation
+        # transaction. If the transaction was not a contract creation transaction,
+        # or it has not yet been mined, this field will be null.
+        createdContract(block: Long): Account
+        # Logs is a list of log entries emitted by this transaction. If the
+        # transaction has not yet been mined, this field will be null.
+        logs: [Log!]
+        # Type is the EIP-2718 transaction type.
+        type: Int
+    }
+
+    # BlockFilterCriteria encapsulates log filter criteria for a filter applied
+    # to a single block.
+    input BlockFilterCriteria {
+        # Addresses is a list of addresses that are of interest. If this list is
+        # empty, results will not be filtered by address.
+        addresses: [Address!]
+        # Topics list restricts matches to particular event topics. Each event has a list
+        # of topics. Topics matches a prefix of that list. An empty element array matches any
+        # topic. Non-empty elements represent an alternative that matches any of the
+        # contained topics.
+        topics: [[Bytes32!]!]
+    }
+
+    # Block is an Opera block.
+    type Block {
+        # Number is the number of this block, starting at 0 for the genesis block.
+        number: Long!
+        # Hash is the block hash of this block, which is the ID of its Atropos event.
+        hash: Bytes32!
+        # Parent is the parent block of this block.
+        parent: Block
+        # StateRoot is the hash of the root of the state trie after this block
+        # was processed.
+        stateRoot: Bytes32!
+        # TransactionsRoot is the hash of the root of the trie of transactions in this block.
+        transactionsRoot: Bytes32!
+        # Miner is the account that receives the block fees.
+        miner(block: Long): Account!
+        # Timestamp is the unix timestamp at which this block was sealed, in seconds.
+        timestamp: Long!
+        # TimestampNano is the unix timestamp at which this block was sealed, in nanoseconds.
+        timestampNano: Long!
+        # GasLimit is the maximum amount of gas that was available to transactions in this block.
+        gasLimit: Long!
+        # GasUsed is the amount of gas that was used executing transactions in this block.
+        gasUsed: Long!
+        # BaseFeePerGas is the fee per unit of gas burned by the protocol in this block.
+        baseFeePerGas: BigInt
+        # TransactionCount is the number of transactions in this block.
+        transactionCount: Int!
+        # Transactions is a list of transactions associated with this block.
+        transactions: [Transaction!]!
+        # TransactionAt returns the transaction at the specified index. If the
+        # transaction is out of bounds, null is returned.
+        transactionAt(index: Int!): Transaction
+        # Logs returns a filtered set of logs from this block.
+        logs(filter: BlockFilterCriteria!): [Log!]!
+        # Account fetches an Ethereum account at the current block's state.
+        account(address: Address!): Account!
+        # Epoch is the Lachesis epoch this block belongs to.
+        epoch: Epoch
+        # Atropos is the Lachesis event which decided this block.
+        atropos: Event
+        # Events is the list of Lachesis events confirmed by this block.
+        events: [Event!]!
+    }
+
+    # FilterCriteria encapsulates log filter criteria for searching log entries.
+    input FilterCriteria {
+        # FromBlock is the block at which to start searching, inclusive. Defaults
+        # to the latest block if not supplied.
+        fromBlock: Long
+        # ToBlock is the block at which to stop searching, inclusive. Defaults
+        # to the latest block if not supplied.
+        toBlock: Long
+        # Addresses is a list of addresses that are of interest. If this list is
+        # empty, results will not be filtered by address.
+        addresses: [Address!]
+        # Topics list restricts matches to particular event topics. Each event has a list
+        # of topics. Topics matches a prefix of that list. An empty element array matches any
+        # topic. Non-empty elements represent an alternative that matches any of the
+        # contained topics.
+        topics: [[Bytes32!]!]
+    }
+
+    # GasPowerLeft is the gas power of a validator left after an event.
+    type GasPowerLeft {
+        shortTerm: Long!
+        longTerm: Long!
+    }
+
+    # Event is a Lachesis DAG event.
+    type Event {
+        # Id is the hash of the event.
+        id: Bytes32!
+        # Epoch is the epoch of the event.
+        epoch: Epoch
+        # Seq is the sequence number of the event among the events of its creator in the epoch.
+        seq: Long!
+        # Frame is the Lachesis frame of the event.
+        frame: Long!
+        # Creator is the validator which created the event.
+        creator: Validator!
+        # Lamport is the Lamport timestamp of the event.
+        lamport: Long!
+        # CreationTime is the unix timestamp claimed by the creator, in nanoseconds.
+        creationTime: Long!
+        # MedianTime is the weighted median of the creation times of the observed events, in nanoseconds.
+        medianTime: Long!
+        # Parents is the list of the parent events.
+        parents: [Event!]!
+        # GasPowerLeft is the gas power of the creator left after the event.
+        gasPowerLeft: GasPowerLeft!
+        # GasPowerUsed is the gas power consumed by the event.
+        gasPowerUsed: Long!
+        # PayloadHash is the hash of the event payload.
+        payloadHash: Bytes32!
+        # ExtraData is the arbitrary data of the event.
+        extraData: Bytes!
+        # Transactions is the list of transactions originated in the event.
+        transactions: [Transaction!]!
+    }
+
+    # Epoch is a Lachesis epoch.
+    type Epoch {
+        # Number is the number of the epoch.
+        number: Long!
+        # Start is the unix timestamp at which the epoch was started, in nanoseconds.
+        start: Long!
+        # SealedAt is the unix timestamp at which the epoch was sealed, in nanoseconds.
+        # This will be null if the epoch is not sealed yet.
+        sealedAt: Long
+        # TotalWeight is the total weight of the epoch validators.
+        totalWeight: BigInt!
+        # Validators is the list of the epoch validators.
+        validators: [Validator!]!
+        # Rules is the JSON encoded network rules of the epoch.
+        rules: String!
+    }
+
+    # Downtime is the offline period of a validator.
+    type Downtime {
+        # Blocks is the number of blocks the validator is offline for.
+        blocks: Long!
+        # Time is the duration the validator is offline for, in nanoseconds.
+        time: Long!
+    }
+
+    # Validator is a validator of a Lachesis epoch.
+    type Validator {
+        # Id is the ID of the validator.
+        id: Long!
+        # Weight is the weight of the validator in the epoch.
+        weight: BigInt!
+        # Pubkey is the public key of the validator in the epoch.
+        pubkey: String!
+        # Uptime is the uptime of the validator in the current epoch, in nanoseconds.
+        # This will be null if the epoch isn't current.
+        uptime: Long
+        # Downtime is the current downtime of the validator.
+        # This will be null if the epoch isn't current.
+        downtime: Downtime
+        # OriginatedFee is the fee originated by the validator in the current epoch, in wei.
+        # This will be null if the epoch isn't current.
+        originatedFee: BigInt
+    }
+
+    type Query {
+        # Block fetches a block by number or by hash. If neither is
+        # supplied, the most recent known block is returned.
+        block(number: Long, hash: Bytes32): Block
+        # Blocks returns all the blocks between two numbers, inclusive. If
+        # to is not supplied, it defaults to the most recent known block.
+        blocks(from: Long!, to: Long): [Block!]!
+        # Transaction returns a transaction specified by its hash.
+        transaction(hash: Bytes32!): Transaction
+        # Logs returns log entries matching the provided filter.
+        logs(filter: FilterCriteria!): [Log!]!
+        # Event returns a Lachesis event specified by its full ID or short ID.
+        event(id: String!): Event
+        # Epoch fetches an epoch by number. If number is not supplied,
+        # the current epoch is returned.
+        epoch(number: Long): Epoch
+        # GasPrice returns a suggestion for a gas price for legacy transactions.
+        gasPrice: BigInt!
+        # ChainID returns the current chain ID for transaction replay protection.
+        chainID: BigInt!
+    }
+`
@@ -0,0 +1,68 @@
+package graphql
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/ethereum/go-ethereum/node"
+	"github.com/graph-gophers/graphql-go"
+
+	"github.com/Fantom-foundation/go-opera/ethapi"
+	"github.com/Fantom-foundation/go-opera/gossip/filters"
+)
+
+// Backend provides the node data to the GraphQL service.
+type Backend interface {
+	ethapi.Backend
+	filters.Backend
+}
+
+type handler struct {
+	Schema *graphql.Schema
+}
+
+func (h handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var params struct {
+		Query         string                 `json:"query"`
+		OperationName string                 `json:"operationName"`
+		Variables     map[string]interface{} `json:"variables"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&params); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := h.Schema.Exec(r.Context(), params.Query, params.OperationName, params.Variables)
+	responseJSON, err := json.Marshal(response)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if len(response.Errors) > 0 {
+		w.WriteHeader(http.StatusBadRequest)
+	}
+	_, _ = w.Write(responseJSON)
+}
+
+// New constructs a new GraphQL service instance and registers it on the node HTTP server.
+// Logs are searched as eth_getLogs does, within the block range limits of the filter config.
+func New(stack *node.Node, backend Backend, filterConfig filters.Config, cors, vhosts []string) error {
+	if backend == nil {
+		panic("missing backend")
+	}
+	q := Resolver{
+		backend:      backend,
+		filterConfig: filterConfig,
+	}
+
+	s, err := graphql.ParseSchema(schema, &q)
+	if err != nil {
+		return err
+	}
+	h := node.NewHTTPHandlerStack(handler{Schema: s}, cors, vhosts)
+
+	stack.RegisterHandler("GraphQL", "/graphql", h)
+	stack.RegisterHandler("GraphQL", "/graphql/", h)
+	return nil
+}
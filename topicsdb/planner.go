@@ -0,0 +1,65 @@
+package topicsdb
+
+import (
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+type countKey [hashSize + uint8Size]byte
+
+func newCountKey(topic common.Hash, pos uint8) (key countKey) {
+	copy(key[:], topic.Bytes())
+	key[hashSize] = pos
+	return
+}
+
+// getCount returns the count of log records indexed with the topic at the position.
+func (tt *Index) getCount(topic common.Hash, pos uint8) (uint64, error) {
+	key := newCountKey(topic, pos)
+	buf, err := tt.table.Count.Get(key[:])
+	if err != nil || buf == nil {
+		return 0, err
+	}
+	return bytesToUint(buf), nil
+}
+
+// writeCounts writes the counters incremented by the deltas.
+func (tt *Index) writeCounts(w kvdb.Writer, deltas map[countKey]uint64) error {
+	for key, delta := range deltas {
+		count, err := tt.getCount(common.BytesToHash(key[:hashSize]), key[hashSize])
+		if err != nil {
+			return err
+		}
+		if err := w.Put(key[:], uintToBytes(count+delta)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// plan returns the most selective non-empty position of the pattern, i.e. the one with
+// the least estimated count of log records. Ties are resolved to the lower position,
+// so the search is driven by the first non-empty position if there are no counters
+// (e.g. for records indexed before the counters were introduced).
+// The counters are estimations only, so the choice affects the search speed but not its result.
+func (tt *Index) plan(pattern [][]common.Hash) (best uint8, ok bool, err error) {
+	var bestCount uint64
+	for pos, variants := range pattern {
+		if len(variants) < 1 {
+			continue
+		}
+		var count uint64
+		for _, variant := range variants {
+			var c uint64
+			c, err = tt.getCount(variant, uint8(pos))
+			if err != nil {
+				return
+			}
+			count += c
+		}
+		if !ok || count < bestCount {
+			best, bestCount, ok = uint8(pos), count, true
+		}
+	}
+	return
+}
@@ -1,8 +1,12 @@
 package topicsdb
 
 import (
+	"bytes"
+	"container/heap"
 	"context"
+	"sort"
 
+	"github.com/Fantom-foundation/lachesis-base/kvdb"
 	"github.com/ethereum/go-ethereum/common"
 )
 
@@ -12,127 +16,158 @@ func (tt *Index) searchLazy(ctx context.Context, pattern [][]common.Hash, blockS
 	if ctx == nil {
 		ctx = context.Background()
 	}
-	_, err = tt.walkFirst(ctx, blockStart, blockEnd, pattern, 0, onMatched)
-	return
+	pos, ok, err := tt.plan(pattern)
+	if err != nil || !ok {
+		return
+	}
+	return tt.walkMerged(ctx, blockStart, blockEnd, pattern, pos, onMatched)
 }
 
-// walkFirst for topics recursive.
-func (tt *Index) walkFirst(
+// walkMerged drives the search by the variants of the pos pattern position.
+// Variants are merged in the order of log records IDs, and each record is checked against the rest positions.
+// Matched records are passed to onMatched in block/log-index order.
+func (tt *Index) walkMerged(
 	ctx context.Context, blockStart []byte, blockEnd uint64, pattern [][]common.Hash, pos uint8, onMatched logHandler,
 ) (
-	gonext bool, err error,
+	err error,
 ) {
 	patternLen := uint8(len(pattern))
-	gonext = true
-	for {
-		if pos >= patternLen {
-			return
+
+	its := make(variantsHeap, 0, len(pattern[pos]))
+	defer func() {
+		for _, it := range its {
+			it.Release()
 		}
-		if len(pattern[pos]) < 1 {
-			pos++
+	}()
+	for _, variant := range pattern[pos] {
+		prefix := make([]byte, 0, hashSize+uint8Size)
+		prefix = append(prefix, variant.Bytes()...)
+		prefix = append(prefix, posToBytes(pos)...)
+		it := tt.table.Topic.NewIterator(prefix, blockStart)
+		if it.Next() {
+			its = append(its, it)
 			continue
 		}
-		break
+		err = it.Error()
+		it.Release()
+		if err != nil {
+			return
+		}
 	}
+	heap.Init(&its)
 
 	var (
-		prefix  [topicKeySize]byte
-		prefLen int = hashSize
+		block  []*logrec
+		blockN uint64
 	)
-	copy(prefix[prefLen:], posToBytes(pos))
-	prefLen += uint8Size
-
-	for _, variant := range pattern[pos] {
-		copy(prefix[0:], variant.Bytes())
-		it := tt.table.Topic.NewIterator(prefix[:prefLen], blockStart)
-		for it.Next() {
-			err = ctx.Err()
-			if err != nil {
-				it.Release()
+	flush := func() (gonext bool, err error) {
+		sort.Slice(block, func(i, j int) bool {
+			return block[i].ID.Index() < block[j].ID.Index()
+		})
+		for _, rec := range block {
+			gonext, err = onMatched(rec)
+			if err != nil || !gonext {
 				return
 			}
+		}
+		block = block[:0]
+		return true, nil
+	}
 
-			topicCount := bytesToPos(it.Value())
-			if topicCount < (patternLen - 1) {
-				continue
-			}
-			id := extractLogrecID(it.Key())
-			rec := newLogrec(id, topicCount)
+	for len(its) > 0 {
+		err = ctx.Err()
+		if err != nil {
+			return
+		}
 
-			if blockStart != nil && rec.ID.BlockNumber() > blockEnd {
-				break
-			}
+		it := its[0]
+		id := extractLogrecID(it.Key())
+		if blockStart != nil && id.BlockNumber() > blockEnd {
+			// the rest records of the variant are out of range too
+			heap.Pop(&its)
+			it.Release()
+			continue
+		}
 
-			gonext, err = tt.walkNexts(ctx, rec, pattern, pos+1, onMatched)
-			if err != nil || !gonext {
-				it.Release()
+		topicCount := bytesToPos(it.Value())
+		if topicCount >= (patternLen - 1) {
+			var matched bool
+			matched, err = tt.matchRest(id, pattern, pos)
+			if err != nil {
 				return
 			}
+			if matched {
+				if len(block) > 0 && blockN != id.BlockNumber() {
+					var gonext bool
+					gonext, err = flush()
+					if err != nil || !gonext {
+						return
+					}
+				}
+				blockN = id.BlockNumber()
+				block = append(block, newLogrec(id, topicCount))
+			}
 		}
 
+		if it.Next() {
+			heap.Fix(&its, 0)
+			continue
+		}
+		heap.Pop(&its)
 		err = it.Error()
 		it.Release()
 		if err != nil {
 			return
 		}
-
 	}
+
+	_, err = flush()
 	return
 }
 
-// walkNexts for topics recursive.
-func (tt *Index) walkNexts(
-	ctx context.Context, rec *logrec, pattern [][]common.Hash, pos uint8, onMatched logHandler,
-) (
-	gonext bool, err error,
-) {
-	patternLen := uint8(len(pattern))
-	gonext = true
-	for {
-		// Max recursion depth is equal to len(topics) and limited by MaxCount.
-		if pos >= patternLen {
-			gonext, err = onMatched(rec)
-			return
-		}
-		if len(pattern[pos]) < 1 {
-			pos++
+// matchRest checks the log record against all the non-empty pattern positions except the skip one.
+func (tt *Index) matchRest(id ID, pattern [][]common.Hash, skip uint8) (bool, error) {
+	for pos, variants := range pattern {
+		if uint8(pos) == skip || len(variants) < 1 {
 			continue
 		}
-		break
-	}
-
-	var (
-		prefix  [topicKeySize]byte
-		prefLen int = hashSize
-	)
-	copy(prefix[prefLen:], posToBytes(pos))
-	prefLen += uint8Size
-	copy(prefix[prefLen:], rec.ID.Bytes())
-	prefLen += logrecKeySize
-
-	for _, variant := range pattern[pos] {
-		copy(prefix[0:], variant.Bytes())
-		it := tt.table.Topic.NewIterator(prefix[:prefLen], nil)
-		for it.Next() {
-			err = ctx.Err()
+		matched := false
+		for _, variant := range variants {
+			has, err := tt.table.Topic.Has(topicKey(variant, uint8(pos), id))
 			if err != nil {
-				it.Release()
-				return
+				return false, err
 			}
-
-			gonext, err = tt.walkNexts(ctx, rec, pattern, pos+1, onMatched)
-			if err != nil || !gonext {
-				it.Release()
-				return
+			if has {
+				matched = true
+				break
 			}
 		}
-
-		err = it.Error()
-		it.Release()
-		if err != nil {
-			return
+		if !matched {
+			return false, nil
 		}
-
 	}
-	return
+	return true, nil
+}
+
+// variantsHeap is a min-heap of the pattern position variants iterators, ordered by the current log record ID.
+type variantsHeap []kvdb.Iterator
+
+func (h variantsHeap) Len() int { return len(h) }
+
+func (h variantsHeap) Less(i, j int) bool {
+	return bytes.Compare(h[i].Key()[hashSize+uint8Size:], h[j].Key()[hashSize+uint8Size:]) < 0
+}
+
+func (h variantsHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *variantsHeap) Push(x interface{}) {
+	*h = append(*h, x.(kvdb.Iterator))
+}
+
+func (h *variantsHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	it := old[n-1]
+	*h = old[:n-1]
+	return it
 }
@@ -3,6 +3,7 @@ package topicsdb
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/Fantom-foundation/lachesis-base/inter/idx"
 	"github.com/Fantom-foundation/lachesis-base/kvdb"
@@ -27,9 +28,21 @@ type Index struct {
 		Topic kvdb.Store `table:"t"`
 		// (blockN+TxHash+logIndex) -> ordered topic_count topics, blockHash, address, data
 		Logrec kvdb.Store `table:"r"`
+		// topic+topicN -> count of log records (estimation for the search planning)
+		Count kvdb.Store `table:"c"`
 	}
+
+	// pushMu serializes the counters updates
+	pushMu sync.Mutex
 }
 
+// prefixes of the tables (must match the table tags), for writing all of them in a single batch
+var (
+	topicPrefix  = []byte("t")
+	logrecPrefix = []byte("r")
+	countPrefix  = []byte("c")
+)
+
 // New Index instance.
 func New(db kvdb.Store) *Index {
 	tt := &Index{
@@ -42,6 +55,7 @@ func New(db kvdb.Store) *Index {
 }
 
 // FindInBlocks returns all log records of block range by pattern. 1st pattern element is an address.
+// Records are returned in block/log-index order.
 // The same as FindInBlocksAsync but fetches log's body sync.
 func (tt *Index) FindInBlocks(ctx context.Context, from, to idx.Block, pattern [][]common.Hash) (logs []*types.Log, err error) {
 	err = tt.ForEachInBlocks(
@@ -57,6 +71,7 @@ func (tt *Index) FindInBlocks(ctx context.Context, from, to idx.Block, pattern [
 }
 
 // ForEach matches log records by pattern. 1st pattern element is an address.
+// Records are passed to onLog in block/log-index order.
 func (tt *Index) ForEach(ctx context.Context, pattern [][]common.Hash, onLog func(*types.Log) (gonext bool)) error {
 	pattern, err := limitPattern(pattern)
 	if err != nil {
@@ -77,6 +92,7 @@ func (tt *Index) ForEach(ctx context.Context, pattern [][]common.Hash, onLog fun
 }
 
 // ForEachInBlocks matches log records of block range by pattern. 1st pattern element is an address.
+// Records are passed to onLog in block/log-index order.
 func (tt *Index) ForEachInBlocks(ctx context.Context, from, to idx.Block, pattern [][]common.Hash, onLog func(*types.Log) (gonext bool)) error {
 	if from > to {
 		return nil
@@ -144,14 +160,38 @@ func (tt *Index) MustPush(recs ...*types.Log) {
 	}
 }
 
-// Write log record to database.
+// Push writes the log records (e.g. of a block) and updates the counters in a single batch.
+// Already indexed records are skipped, so the same records may be pushed more than once.
 func (tt *Index) Push(recs ...*types.Log) error {
 	for _, rec := range recs {
 		if len(rec.Topics) > MaxTopicsCount {
 			return ErrTooBigTopics
 		}
+	}
 
+	tt.pushMu.Lock()
+	defer tt.pushMu.Unlock()
+
+	batch := tt.db.NewBatch()
+	var (
+		logrecs = prefixedWriter{batch, logrecPrefix}
+		topics  = prefixedWriter{batch, topicPrefix}
+		counts  = make(map[countKey]uint64)
+		pushed  = make(map[ID]struct{}, len(recs))
+	)
+	for _, rec := range recs {
 		id := NewID(rec.BlockNumber, rec.TxHash, rec.Index)
+		if _, ok := pushed[id]; ok {
+			continue
+		}
+		pushed[id] = struct{}{}
+		indexed, err := tt.table.Logrec.Has(id.Bytes())
+		if err != nil {
+			return err
+		}
+		if indexed {
+			continue
+		}
 
 		// write data
 		buf := make([]byte, 0, common.HashLength*len(rec.Topics)+common.HashLength+common.AddressLength+len(rec.Data))
@@ -161,7 +201,7 @@ func (tt *Index) Push(recs ...*types.Log) error {
 		buf = append(buf, rec.BlockHash.Bytes()...)
 		buf = append(buf, rec.Address.Bytes()...)
 		buf = append(buf, rec.Data...)
-		if err := tt.table.Logrec.Put(id.Bytes(), buf); err != nil {
+		if err := logrecs.Put(id.Bytes(), buf); err != nil {
 			return err
 		}
 
@@ -172,9 +212,10 @@ func (tt *Index) Push(recs ...*types.Log) error {
 		)
 		pushIndex := func(topic common.Hash) error {
 			key := topicKey(topic, pos, id)
-			if err := tt.table.Topic.Put(key, count); err != nil {
+			if err := topics.Put(key, count); err != nil {
 				return err
 			}
+			counts[newCountKey(topic, pos)]++
 			pos++
 			return nil
 		}
@@ -191,5 +232,18 @@ func (tt *Index) Push(recs ...*types.Log) error {
 
 	}
 
-	return nil
+	if err := tt.writeCounts(prefixedWriter{batch, countPrefix}, counts); err != nil {
+		return err
+	}
+	return batch.Write()
+}
+
+// prefixedWriter writes the records of a table into a batch of the underlying DB.
+type prefixedWriter struct {
+	kvdb.Writer
+	prefix []byte
+}
+
+func (w prefixedWriter) Put(key []byte, value []byte) error {
+	return w.Writer.Put(append(append(make([]byte, 0, len(w.prefix)+len(key)), w.prefix...), key...), value)
 }
@@ -310,6 +310,125 @@ func TestIndexSearchSimple(t *testing.T) {
 
 }
 
+func TestIndexSearchPlanning(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	var (
+		transfer = common.BytesToHash([]byte("Transfer"))
+		approval = common.BytesToHash([]byte("Approval"))
+		token    = randAddress()
+		holder   = randAddress()
+	)
+
+	index := New(memorydb.New())
+	var recs []*types.Log
+	for i := 0; i < 100; i++ {
+		to := randAddress()
+		if i%10 == 0 {
+			to = holder
+		}
+		event := transfer
+		if i%3 == 0 {
+			event = approval
+		}
+		recs = append(recs, &types.Log{
+			BlockNumber: uint64(i / 4),
+			TxHash:      hash.FakeHash(int64(i)),
+			Index:       uint(3 - i%4),
+			Address:     token,
+			Topics:      []common.Hash{event, token.Hash(), to.Hash()},
+		})
+	}
+	require.NoError(index.Push(recs...))
+
+	pattern := [][]common.Hash{{token.Hash()}, {transfer}, {}, {holder.Hash()}}
+	pos, ok, err := index.plan(pattern)
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(uint8(3), pos)
+
+	count, err := index.getCount(token.Hash(), 0)
+	require.NoError(err)
+	require.Equal(uint64(100), count)
+
+	// no counters: the first non-empty position
+	pos, ok, err = index.plan([][]common.Hash{{}, {hash.FakeHash(1)}, {hash.FakeHash(2)}})
+	require.NoError(err)
+	require.True(ok)
+	require.Equal(uint8(1), pos)
+
+	isOrdered := func(got []*types.Log) {
+		for i := 1; i < len(got); i++ {
+			prev, cur := got[i-1], got[i]
+			require.True(prev.BlockNumber < cur.BlockNumber ||
+				prev.BlockNumber == cur.BlockNumber && prev.Index < cur.Index, i)
+		}
+	}
+
+	got, err := index.FindInBlocks(nil, 0, 1000, pattern)
+	require.NoError(err)
+	var expect []*types.Log
+	for i, rec := range recs {
+		if i%10 == 0 && i%3 != 0 {
+			expect = append(expect, rec)
+		}
+	}
+	require.Len(got, len(expect))
+	isOrdered(got)
+
+	// multiple variants are merged in block/log-index order
+	got, err = index.FindInBlocks(nil, 5, 20, [][]common.Hash{{token.Hash()}, {approval, transfer}})
+	require.NoError(err)
+	require.Len(got, 64)
+	isOrdered(got)
+	require.Equal(uint64(5), got[0].BlockNumber)
+	require.Equal(uint(0), got[0].Index)
+
+	// stop in the middle of a block
+	var stopped []*types.Log
+	err = index.ForEachInBlocks(nil, 0, 1000, [][]common.Hash{{}, {approval, transfer}}, func(l *types.Log) bool {
+		stopped = append(stopped, l)
+		return len(stopped) < 6
+	})
+	require.NoError(err)
+	require.Len(stopped, 6)
+	isOrdered(stopped)
+}
+
+func TestIndexPushTwice(t *testing.T) {
+	logger.SetTestMode(t)
+	require := require.New(t)
+
+	index := New(memorydb.New())
+	topic := hash.FakeHash(1)
+	recs := make([]*types.Log, 10)
+	for i := range recs {
+		recs[i] = &types.Log{
+			BlockNumber: uint64(i / 2),
+			TxHash:      hash.FakeHash(int64(i)),
+			Address:     randAddress(),
+			Topics:      []common.Hash{topic},
+		}
+	}
+	// duplicates within a push are counted once
+	require.NoError(index.Push(append(recs[:5:5], recs[:5]...)...))
+	count, err := index.getCount(topic, 1)
+	require.NoError(err)
+	require.Equal(uint64(5), count)
+
+	// already indexed records are skipped
+	require.NoError(index.Push(recs...))
+	require.NoError(index.Push(recs...))
+	count, err = index.getCount(topic, 1)
+	require.NoError(err)
+	require.Equal(uint64(len(recs)), count)
+
+	got, err := index.FindInBlocks(nil, 0, 1000, [][]common.Hash{{}, {topic}})
+	require.NoError(err)
+	require.Len(got, len(recs))
+}
+
 func TestMaxTopicsCount(t *testing.T) {
 	logger.SetTestMode(t)
 
@@ -0,0 +1,60 @@
+package txtrace
+
+import (
+	"math/big"
+
+	"github.com/Fantom-foundation/lachesis-base/inter/idx"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// Action types of flat traces.
+const (
+	CallAction    = "call"
+	CreateAction  = "create"
+	SuicideAction = "suicide"
+)
+
+// ActionTrace is a flat trace of a single call frame of a transaction, in the Parity (OpenEthereum) manner.
+// Traces of a transaction are ordered as the call frames are entered.
+//
+// The meaning of From, To, Value, Input and Output depends on the action type. For calls, these are
+// the caller, callee, transferred value, call data and returned data. For creations, these are
+// the creator, created contract, endowment, init code and deployed code. For self-destructs, these
+// are the destructed contract, refund address and refunded balance.
+type ActionTrace struct {
+	Type         string
+	CallType     string // call, callcode, delegatecall or staticcall for call actions
+	From         common.Address
+	To           common.Address
+	Value        *big.Int
+	Gas          uint64
+	GasUsed      uint64
+	Input        []byte
+	Output       []byte
+	Error        string // empty if the call frame succeeded
+	TraceAddress []uint32
+	Subtraces    uint32
+}
+
+// Position is a position of a not skipped transaction within a block.
+type Position struct {
+	Block       idx.Block
+	BlockOffset uint32
+}
+
+// Addresses returns the addresses touched by the traces, as senders and as recipients.
+func Addresses(traces []ActionTrace) (from, to []common.Address) {
+	fromSet := make(map[common.Address]bool, len(traces))
+	toSet := make(map[common.Address]bool, len(traces))
+	for _, t := range traces {
+		if !fromSet[t.From] {
+			fromSet[t.From] = true
+			from = append(from, t.From)
+		}
+		if !toSet[t.To] {
+			toSet[t.To] = true
+			to = append(to, t.To)
+		}
+	}
+	return
+}
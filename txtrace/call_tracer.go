@@ -0,0 +1,117 @@
+package txtrace
+
+import (
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+)
+
+// CallTracer is a vm.Tracer which collects flat traces of call frames.
+// It may be used for consecutive transactions, traces of every finished transaction are
+// accumulated until they are taken.
+type CallTracer struct {
+	txs    [][]ActionTrace
+	traces []ActionTrace
+	frames []int // indexes of the open call frames in traces
+}
+
+// NewCallTracer creates a tracer which collects flat call traces.
+func NewCallTracer() *CallTracer {
+	return &CallTracer{}
+}
+
+// Take returns the traces of the finished transactions (one item per transaction) and resets them.
+func (t *CallTracer) Take() [][]ActionTrace {
+	txs := t.txs
+	t.txs = nil
+	return txs
+}
+
+func (t *CallTracer) enter(typ, callType string, from, to common.Address, input []byte, gas uint64, value *big.Int) {
+	trace := ActionTrace{
+		Type:     typ,
+		CallType: callType,
+		From:     from,
+		To:       to,
+		Value:    new(big.Int),
+		Gas:      gas,
+		Input:    common.CopyBytes(input),
+	}
+	if value != nil {
+		trace.Value.Set(value)
+	}
+	if len(t.frames) != 0 {
+		parent := &t.traces[t.frames[len(t.frames)-1]]
+		trace.TraceAddress = make([]uint32, len(parent.TraceAddress), len(parent.TraceAddress)+1)
+		copy(trace.TraceAddress, parent.TraceAddress)
+		trace.TraceAddress = append(trace.TraceAddress, parent.Subtraces)
+		parent.Subtraces++
+	}
+	t.frames = append(t.frames, len(t.traces))
+	t.traces = append(t.traces, trace)
+}
+
+func (t *CallTracer) exit(output []byte, gasUsed uint64, err error) {
+	if len(t.frames) == 0 {
+		return
+	}
+	trace := &t.traces[t.frames[len(t.frames)-1]]
+	t.frames = t.frames[:len(t.frames)-1]
+
+	trace.GasUsed = gasUsed
+	if err != nil {
+		trace.Error = err.Error()
+		if err != vm.ErrExecutionReverted {
+			// all the gas is consumed, nothing is returned
+			trace.GasUsed = trace.Gas
+			return
+		}
+	}
+	trace.Output = common.CopyBytes(output)
+}
+
+// CaptureStart implements vm.Tracer, it's called when the transaction execution is started.
+func (t *CallTracer) CaptureStart(env *vm.EVM, from common.Address, to common.Address, create bool, input []byte, gas uint64, value *big.Int) {
+	t.traces = nil
+	t.frames = t.frames[:0]
+	if create {
+		t.enter(CreateAction, "", from, to, input, gas, value)
+	} else {
+		t.enter(CallAction, CallAction, from, to, input, gas, value)
+	}
+}
+
+// CaptureState implements vm.Tracer.
+func (t *CallTracer) CaptureState(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, rData []byte, depth int, err error) {
+}
+
+// CaptureEnter implements vm.Tracer, it's called when a nested call frame is entered.
+func (t *CallTracer) CaptureEnter(typ vm.OpCode, from common.Address, to common.Address, input []byte, gas uint64, value *big.Int) {
+	switch typ {
+	case vm.CREATE, vm.CREATE2:
+		t.enter(CreateAction, "", from, to, input, gas, value)
+	case vm.SELFDESTRUCT:
+		t.enter(SuicideAction, "", from, to, input, gas, value)
+	default:
+		t.enter(CallAction, strings.ToLower(typ.String()), from, to, input, gas, value)
+	}
+}
+
+// CaptureExit implements vm.Tracer, it's called when a nested call frame is exited.
+func (t *CallTracer) CaptureExit(output []byte, gasUsed uint64, err error) {
+	t.exit(output, gasUsed, err)
+}
+
+// CaptureFault implements vm.Tracer.
+func (t *CallTracer) CaptureFault(env *vm.EVM, pc uint64, op vm.OpCode, gas, cost uint64, scope *vm.ScopeContext, depth int, err error) {
+}
+
+// CaptureEnd implements vm.Tracer, it's called when the transaction execution is finished.
+func (t *CallTracer) CaptureEnd(output []byte, gasUsed uint64, _ time.Duration, err error) {
+	t.exit(output, gasUsed, err)
+	t.txs = append(t.txs, t.traces)
+	t.traces = nil
+}
@@ -0,0 +1,84 @@
+package txtrace
+
+import (
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCallTracer(t *testing.T) {
+	require := require.New(t)
+
+	var (
+		sender   = common.Address{1}
+		contract = common.Address{2}
+		created  = common.Address{3}
+		refund   = common.Address{4}
+	)
+	tracer := NewCallTracer()
+
+	// tx with nested frames
+	tracer.CaptureStart(nil, sender, contract, false, []byte{0xaa}, 1000, big.NewInt(5))
+	tracer.CaptureEnter(vm.DELEGATECALL, contract, common.Address{5}, nil, 500, nil)
+	tracer.CaptureExit([]byte{0xbb}, 100, nil)
+	tracer.CaptureEnter(vm.CREATE, contract, created, []byte{0xcc}, 400, big.NewInt(1))
+	tracer.CaptureEnter(vm.SELFDESTRUCT, created, refund, nil, 0, big.NewInt(1))
+	tracer.CaptureExit(nil, 0, nil)
+	tracer.CaptureExit([]byte{0xdd}, 200, nil)
+	tracer.CaptureEnd([]byte{0xee}, 800, 0, nil)
+
+	// failed tx
+	tracer.CaptureStart(nil, sender, contract, false, nil, 1000, nil)
+	tracer.CaptureEnter(vm.CALL, contract, refund, nil, 500, big.NewInt(2))
+	tracer.CaptureExit(nil, 10, errors.New("out of gas"))
+	tracer.CaptureEnd([]byte{0xff}, 600, 0, vm.ErrExecutionReverted)
+
+	txs := tracer.Take()
+	require.Len(txs, 2)
+	require.Empty(tracer.Take())
+
+	traces := txs[0]
+	require.Len(traces, 4)
+
+	require.Equal(CallAction, traces[0].Type)
+	require.Equal("call", traces[0].CallType)
+	require.Equal(sender, traces[0].From)
+	require.Equal(contract, traces[0].To)
+	require.Equal(big.NewInt(5), traces[0].Value)
+	require.Equal(uint64(800), traces[0].GasUsed)
+	require.Equal([]byte{0xee}, traces[0].Output)
+	require.Empty(traces[0].TraceAddress)
+	require.Equal(uint32(2), traces[0].Subtraces)
+
+	require.Equal("delegatecall", traces[1].CallType)
+	require.Equal(0, traces[1].Value.Sign())
+	require.Equal([]uint32{0}, traces[1].TraceAddress)
+
+	require.Equal(CreateAction, traces[2].Type)
+	require.Equal(created, traces[2].To)
+	require.Equal([]byte{0xcc}, traces[2].Input)
+	require.Equal([]byte{0xdd}, traces[2].Output)
+	require.Equal([]uint32{1}, traces[2].TraceAddress)
+	require.Equal(uint32(1), traces[2].Subtraces)
+
+	require.Equal(SuicideAction, traces[3].Type)
+	require.Equal(created, traces[3].From)
+	require.Equal(refund, traces[3].To)
+	require.Equal([]uint32{1, 0}, traces[3].TraceAddress)
+
+	traces = txs[1]
+	require.Len(traces, 2)
+	require.Equal(vm.ErrExecutionReverted.Error(), traces[0].Error)
+	require.Equal([]byte{0xff}, traces[0].Output)
+	require.Equal("out of gas", traces[1].Error)
+	require.Equal(uint64(500), traces[1].GasUsed)
+	require.Nil(traces[1].Output)
+
+	from, to := Addresses(txs[0])
+	require.Equal([]common.Address{sender, contract, created}, from)
+	require.Equal([]common.Address{contract, {5}, created, refund}, to)
+}